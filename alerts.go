@@ -0,0 +1,262 @@
+package erlcgo
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// EventTypeAlert is the type of events emitted by Alerts. It is not a
+// subscription type; it only appears on events sent to an Alerts sink.
+const EventTypeAlert EventType = "alert"
+
+// AlertState is the tracked server state alert conditions are evaluated on.
+type AlertState struct {
+	Players int
+	Staff   int
+	Queue   int
+	// OpenModCalls counts mod calls in the log that no moderator has answered.
+	OpenModCalls int
+}
+
+// Condition is a predicate over AlertState.
+type Condition func(AlertState) bool
+
+// And returns a condition that holds when c and every other condition hold.
+func (c Condition) And(others ...Condition) Condition {
+	return func(s AlertState) bool {
+		if !c(s) {
+			return false
+		}
+		for _, o := range others {
+			if !o(s) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or returns a condition that holds when c or any other condition holds.
+func (c Condition) Or(others ...Condition) Condition {
+	return func(s AlertState) bool {
+		if c(s) {
+			return true
+		}
+		for _, o := range others {
+			if o(s) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// PlayersAtLeast holds when at least n players are in the server.
+func PlayersAtLeast(n int) Condition {
+	return func(s AlertState) bool { return s.Players >= n }
+}
+
+// StaffAtMost holds when at most n staff are in the server.
+func StaffAtMost(n int) Condition {
+	return func(s AlertState) bool { return s.Staff <= n }
+}
+
+// QueueAtLeast holds when at least n users are waiting in the join queue.
+func QueueAtLeast(n int) Condition {
+	return func(s AlertState) bool { return s.Queue >= n }
+}
+
+// OpenModCallsAtLeast holds when at least n mod calls are unanswered.
+func OpenModCallsAtLeast(n int) Condition {
+	return func(s AlertState) bool { return s.OpenModCalls >= n }
+}
+
+// Alert is a named condition that fires once it has held for For and
+// resolves once it has not held for ResolveAfter. The two durations give
+// hysteresis, so a value hovering around a threshold does not flap.
+type Alert struct {
+	Name         string
+	Condition    Condition
+	For          time.Duration
+	ResolveAfter time.Duration
+}
+
+// AlertStatus is the state reported in an AlertEvent.
+type AlertStatus string
+
+const (
+	AlertFiring   AlertStatus = "firing"
+	AlertResolved AlertStatus = "resolved"
+)
+
+// AlertEvent is the Data of an EventTypeAlert event.
+type AlertEvent struct {
+	Name   string
+	Status AlertStatus
+	// Since is when the condition started holding (firing) or stopped
+	// holding (resolved).
+	Since time.Time
+	State AlertState
+}
+
+// AlertsConfig configures Alerts.
+type AlertsConfig struct {
+	// PollInterval is the time between polls. Defaults to 30 seconds.
+	PollInterval time.Duration
+
+	// Sink receives an EventTypeAlert event whenever an alert fires or resolves.
+	Sink Sink
+
+	// ErrorHandler is called with poll and sink errors.
+	ErrorHandler func(error)
+}
+
+// Alerts evaluates composite conditions over server state and notifies a
+// sink when they fire and resolve.
+//
+// Example:
+//
+//	alerts := erlcgo.NewAlerts(client, erlcgo.AlertsConfig{Sink: discordSink})
+//	alerts.Register(erlcgo.Alert{
+//	    Name:         "understaffed",
+//	    Condition:    erlcgo.PlayersAtLeast(30).And(erlcgo.StaffAtMost(0)),
+//	    For:          10 * time.Minute,
+//	    ResolveAfter: 2 * time.Minute,
+//	})
+//	go alerts.Run(ctx)
+type Alerts struct {
+	client *Client
+	config AlertsConfig
+
+	mu     sync.Mutex
+	alerts []*alertTracker
+}
+
+type alertTracker struct {
+	Alert
+	firing bool
+	// changedAt is when the condition last flipped relative to firing; zero
+	// when it agrees with the current status.
+	changedAt time.Time
+}
+
+// NewAlerts creates an alert evaluator. Register alerts, then call Run.
+func NewAlerts(client *Client, config AlertsConfig) *Alerts {
+	if config.PollInterval <= 0 {
+		config.PollInterval = 30 * time.Second
+	}
+	return &Alerts{client: client, config: config}
+}
+
+// Register adds an alert.
+func (a *Alerts) Register(alert Alert) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.alerts = append(a.alerts, &alertTracker{Alert: alert})
+}
+
+// Firing returns the names of alerts that are currently firing.
+func (a *Alerts) Firing() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	names := make([]string, 0)
+	for _, t := range a.alerts {
+		if t.firing {
+			names = append(names, t.Name)
+		}
+	}
+	return names
+}
+
+// Run polls the server and evaluates alerts until ctx is done.
+func (a *Alerts) Run(ctx context.Context) error {
+	ctx = pollContext(ctx)
+	ticker := time.NewTicker(a.config.PollInterval)
+	defer ticker.Stop()
+
+	opts := ServerQueryOptions{Players: true, Queue: true, ModCalls: true}
+	for {
+		resp, err := a.client.GetServer(ctx, opts)
+		if err != nil {
+			a.report(err)
+		} else {
+			a.Evaluate(ctx, AlertStateOf(resp), time.Now())
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// AlertStateOf derives AlertState from a server snapshot.
+func AlertStateOf(resp *ERLCServerResponse) AlertState {
+	s := AlertState{
+		Players: len(resp.Players),
+		Staff:   len(staffSet(resp.Players)),
+		Queue:   len(resp.Queue),
+	}
+	for _, m := range resp.ModCalls {
+		if m.Moderator == "" {
+			s.OpenModCalls++
+		}
+	}
+	return s
+}
+
+// Evaluate checks every alert against state observed at now. Run calls it on
+// each poll; call it directly to drive alerts from state you already track.
+func (a *Alerts) Evaluate(ctx context.Context, state AlertState, now time.Time) {
+	var events []AlertEvent
+
+	a.mu.Lock()
+	for _, t := range a.alerts {
+		if t.Condition(state) == t.firing {
+			t.changedAt = time.Time{}
+			continue
+		}
+		if t.changedAt.IsZero() {
+			t.changedAt = now
+		}
+		hold := t.For
+		if t.firing {
+			hold = t.ResolveAfter
+		}
+		if now.Sub(t.changedAt) < hold {
+			continue
+		}
+		t.firing = !t.firing
+		status := AlertResolved
+		if t.firing {
+			status = AlertFiring
+		}
+		events = append(events, AlertEvent{Name: t.Name, Status: status, Since: t.changedAt, State: state})
+		t.changedAt = time.Time{}
+	}
+	a.mu.Unlock()
+
+	if a.config.Sink == nil {
+		return
+	}
+	for _, e := range events {
+		event := Event{
+			ID:      hashFields("alert", e.Name, string(e.Status), strconv.FormatInt(e.Since.Unix(), 10)),
+			Type:    EventTypeAlert,
+			Data:    e,
+			Version: SchemaVersion,
+		}
+		if err := a.config.Sink.Send(ctx, event); err != nil {
+			a.report(err)
+		}
+	}
+}
+
+func (a *Alerts) report(err error) {
+	if a.config.ErrorHandler != nil {
+		a.config.ErrorHandler(err)
+	}
+}
@@ -0,0 +1,143 @@
+package erlcgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// AnnounceStyle selects the command Announce uses.
+type AnnounceStyle string
+
+const (
+	// AnnounceMessage shows the text as a server message (:m).
+	AnnounceMessage AnnounceStyle = "m"
+	// AnnounceHint shows the text as a hint (:h).
+	AnnounceHint AnnounceStyle = "h"
+)
+
+// AnnounceOptions configures Announce.
+type AnnounceOptions struct {
+	// Style is the command used for each part. Defaults to AnnounceMessage.
+	Style AnnounceStyle
+	// Interval is the pause between parts, so players can read each one.
+	// Defaults to three seconds.
+	Interval time.Duration
+}
+
+// AnnounceError is returned by Announce when PRC refused some parts as
+// prohibited content (code 4003). The other parts were still sent.
+type AnnounceError struct {
+	// Rejected maps the index of each refused part to its error.
+	Rejected map[int]error
+	// Parts is every part of the announcement, in order.
+	Parts []string
+}
+
+func (e *AnnounceError) Error() string {
+	return fmt.Sprintf("%d of %d announcement parts were rejected as prohibited content", len(e.Rejected), len(e.Parts))
+}
+
+// Announce shows text to the whole server, splitting text longer than one
+// message allows into parts at word boundaries and sending them in order,
+// paced by Interval. Parts go through the request queue like any command. If
+// PRC rejects a part as prohibited content, the remaining parts are still
+// sent and an AnnounceError lists the rejected ones; any other error stops the
+// announcement.
+//
+// Example:
+//
+//	err := client.Announce(ctx, longRulesText, erlcgo.AnnounceOptions{Style: erlcgo.AnnounceHint})
+//	var annErr *erlcgo.AnnounceError
+//	if errors.As(err, &annErr) {
+//	    log.Printf("some parts were filtered: %v", annErr.Rejected)
+//	}
+func (c *Client) Announce(ctx context.Context, text string, opts ...AnnounceOptions) error {
+	var opt AnnounceOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Style == "" {
+		opt.Style = AnnounceMessage
+	}
+	if opt.Interval <= 0 {
+		opt.Interval = 3 * time.Second
+	}
+
+	parts := splitMessage(escapeCommandText(text), maxMessageLength)
+	if len(parts) == 0 {
+		return errors.New("announcement is empty")
+	}
+
+	var rejected map[int]error
+	for i, part := range parts {
+		if i > 0 {
+			timer := time.NewTimer(opt.Interval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		err := c.ExecuteCommand(ctx, ":"+string(opt.Style)+" "+part)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.Code == ErrorCodeProhibitedMsg {
+			if rejected == nil {
+				rejected = make(map[int]error)
+			}
+			rejected[i] = err
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to send announcement part %d of %d: %w", i+1, len(parts), err)
+		}
+	}
+	if rejected != nil {
+		return &AnnounceError{Rejected: rejected, Parts: parts}
+	}
+	return nil
+}
+
+// splitMessage splits text into parts of at most limit characters, breaking
+// at spaces where possible and inside words longer than limit otherwise.
+func splitMessage(text string, limit int) []string {
+	var parts []string
+	var current strings.Builder
+	currentLen := 0
+	flush := func() {
+		if currentLen > 0 {
+			parts = append(parts, current.String())
+			current.Reset()
+			currentLen = 0
+		}
+	}
+
+	for _, word := range strings.Fields(text) {
+		for utf8.RuneCountInString(word) > limit {
+			flush()
+			runes := []rune(word)
+			parts = append(parts, string(runes[:limit]))
+			word = string(runes[limit:])
+		}
+		if word == "" {
+			continue
+		}
+		n := utf8.RuneCountInString(word)
+		if currentLen > 0 && currentLen+1+n > limit {
+			flush()
+		}
+		if currentLen > 0 {
+			current.WriteByte(' ')
+			currentLen++
+		}
+		current.WriteString(word)
+		currentLen += n
+	}
+	flush()
+	return parts
+}
@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 )
@@ -23,53 +26,69 @@ import (
 //	}
 //	fmt.Printf("Server Name: %s\n", resp.Name)
 func (c *Client) GetServer(ctx context.Context, opts ...ServerQueryOptions) (*ERLCServerResponse, error) {
-	query := ""
+	var opt ServerQueryOptions
 	if len(opts) > 0 {
-		opt := opts[0]
-		params := []string{}
-		if opt.Players {
-			params = append(params, "Players=true")
-		}
-		if opt.Staff {
-			params = append(params, "Staff=true")
-		}
-		if opt.JoinLogs {
-			params = append(params, "JoinLogs=true")
-		}
-		if opt.Queue {
-			params = append(params, "Queue=true")
-		}
-		if opt.KillLogs {
-			params = append(params, "KillLogs=true")
-		}
-		if opt.CommandLogs {
-			params = append(params, "CommandLogs=true")
-		}
-		if opt.ModCalls {
-			params = append(params, "ModCalls=true")
-		}
-		if opt.EmergencyCalls {
-			params = append(params, "EmergencyCalls=true")
-		}
-		if opt.Vehicles {
-			params = append(params, "Vehicles=true")
-		}
-		if len(params) > 0 {
-			query = "?"
-			for i, p := range params {
-				if i > 0 {
-					query += "&"
-				}
-				query += p
-			}
+		opt = opts[0]
+	}
+
+	// One GetMulti finds a cached response fetched with these options or
+	// with more, instead of a Get for these options only.
+	if c.cache != nil && c.cache.Enabled && !isFreshRead(ctx) && !isCacheRead(ctx) {
+		if resp, ok := c.warmServer(ctx, opt); ok {
+			return resp, nil
 		}
+		ctx = withCacheRead(ctx)
 	}
 
 	var resp ERLCServerResponse
-	err := c.get(ctx, "/v2/server"+query, &resp)
+	err := c.get(ctx, serverPath(opt), &resp)
 	return &resp, err
 }
 
+// serverPath returns the /v2/server path and query for opt.
+func serverPath(opt ServerQueryOptions) string {
+	params := []string{}
+	if opt.Players {
+		params = append(params, "Players=true")
+	}
+	if opt.Staff {
+		params = append(params, "Staff=true")
+	}
+	if opt.JoinLogs {
+		params = append(params, "JoinLogs=true")
+	}
+	if opt.Queue {
+		params = append(params, "Queue=true")
+	}
+	if opt.KillLogs {
+		params = append(params, "KillLogs=true")
+	}
+	if opt.CommandLogs {
+		params = append(params, "CommandLogs=true")
+	}
+	if opt.ModCalls {
+		params = append(params, "ModCalls=true")
+	}
+	if opt.EmergencyCalls {
+		params = append(params, "EmergencyCalls=true")
+	}
+	if opt.Vehicles {
+		params = append(params, "Vehicles=true")
+	}
+
+	query := ""
+	if len(params) > 0 {
+		query = "?"
+		for i, p := range params {
+			if i > 0 {
+				query += "&"
+			}
+			query += p
+		}
+	}
+	return "/v2/server" + query
+}
+
 // ExecuteCommand executes a server command via the v2 API.
 // The command should include the leading colon (e.g., ":h Hello").
 //
@@ -77,24 +96,81 @@ func (c *Client) GetServer(ctx context.Context, opts ...ServerQueryOptions) (*ER
 //
 //	err := client.ExecuteCommand(ctx, ":h Server maintenance in 5 minutes")
 //	if err != nil {
-//	    if apiErr, ok := err.(*APIError); ok {
+//	    var apiErr *APIError
+//	    if errors.As(err, &apiErr) {
 //	        fmt.Println(GetFriendlyErrorMessage(apiErr))
 //	    }
 //	}
 func (c *Client) ExecuteCommand(ctx context.Context, command string) error {
-	data := map[string]string{"command": command}
-	jsonData, err := json.Marshal(data)
-	if err != nil {
-		return err
+	err := c.executeCommand(ctx, command, nil)
+	if c.offline != nil && isServerOffline(err) {
+		return c.offline.hold(ctx, command)
 	}
+	return err
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v2/server/command", bytes.NewBuffer(jsonData))
-	if err != nil {
+func (c *Client) executeCommand(ctx context.Context, command string, v interface{}) error {
+	if !hasPriority(ctx) {
+		ctx = WithPriority(ctx, c.commandPriority)
+	}
+	return c.commandChain(func(ctx context.Context, command string) error {
+		return c.dispatchCommand(ctx, command, v)
+	})(ctx, command)
+}
+
+// dispatchCommand checks, journals and sends a command that has passed the
+// middleware.
+func (c *Client) dispatchCommand(ctx context.Context, command string, v interface{}) error {
+	if err := c.checkCommand(command); err != nil {
+		return err
+	}
+	if err := c.checkRestrictedCommand(command); err != nil {
+		return err
+	}
+	if err := c.checkTarget(ctx, command); err != nil {
 		return err
 	}
+	if c.recordDryRun(ctx, command) {
+		return nil
+	}
+	sentAt := time.Now()
+	var err error
+	if replay, ok := ctx.Value(journalReplayKey{}).(*journalReplay); ok && c.journal != nil {
+		replay.sent = true
+		entry := replay.entry
+		entry.Command = command
+		err = c.executeJournaled(ctx, entry, v)
+	} else if c.journal != nil {
+		entry := JournalEntry{ID: newRandomID(), Command: command, CreatedAt: sentAt, Tenant: c.tenantOf(ctx), Tags: CommandTagsFrom(ctx)}
+		if err := c.journal.Append(entry); err != nil {
+			return fmt.Errorf("failed to journal command: %w", err)
+		}
+		err = c.executeJournaled(ctx, entry, v)
+	} else {
+		err = c.sendCommand(ctx, command, v)
+	}
+	c.learnRestricted(command, err)
+	if c.confirmation != nil && (err == nil || errors.Is(err, ErrEmptyBody)) {
+		if _, cerr := c.ConfirmCommand(ctx, command, sentAt); cerr != nil {
+			return cerr
+		}
+	}
+	return err
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	return c.doRequest(req, nil)
+// sendCommand posts a command to the v2 command endpoint, decoding the
+// response into v if it is not nil.
+func (c *Client) sendCommand(ctx context.Context, command string, v interface{}) error {
+	data := map[string]string{"command": command}
+	err := c.post(ctx, "/v2/server/command", data, v)
+	if c.cache != nil && c.cache.Enabled {
+		var apiErr *APIError
+		if err == nil || !errors.As(err, &apiErr) {
+			// The command may have been applied even if the response was lost.
+			c.taint.taint(c.cache.ReadYourWritesWindow)
+		}
+	}
+	return err
 }
 
 // get is an internal helper that executes GET requests and parses responses.
@@ -106,6 +182,100 @@ func (c *Client) get(ctx context.Context, path string, v interface{}) error {
 	return c.doRequest(req, v)
 }
 
+// post is an internal helper that executes JSON POST requests and parses responses.
+func (c *Client) post(ctx context.Context, path string, body interface{}, v interface{}) error {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	return c.doRequest(req, v)
+}
+
+// GetJSON performs a GET request against an arbitrary API path and decodes the
+// JSON response into out. It goes through the same authentication, queueing,
+// rate limiting, caching, hooks and error handling as the typed methods, so it
+// can be used to call new PRC endpoints before erlcgo has a wrapper for them.
+//
+// Example:
+//
+//	var bans map[string]string
+//	err := client.GetJSON(ctx, "/v2/server/bans", &bans)
+func (c *Client) GetJSON(ctx context.Context, path string, out interface{}) error {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return c.get(ctx, path, out)
+}
+
+// PostJSON encodes body as JSON, POSTs it to an arbitrary API path and decodes
+// the JSON response into out, which may be nil. Like GetJSON it uses the full
+// request pipeline.
+func (c *Client) PostJSON(ctx context.Context, path string, body interface{}, out interface{}) error {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return c.post(ctx, path, body, out)
+}
+
+// GetRaw performs a GET request against an arbitrary API path and returns the
+// undecoded JSON body. Like GetJSON it uses the full request pipeline.
+//
+// Example:
+//
+//	raw, err := client.GetRaw(ctx, "/v2/server/new-endpoint")
+func (c *Client) GetRaw(ctx context.Context, path string) (json.RawMessage, error) {
+	var raw json.RawMessage
+	if err := c.GetJSON(ctx, path, &raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// DoRequest sends a caller-built request through the client's request
+// pipeline (authentication, queue, cache, rate limiter, hooks and error
+// handling) and decodes the JSON response into v, which may be nil. A
+// request whose URL has no host is resolved against the client's base URL; a
+// request for any other scheme or host is refused with ErrForeignHost, so
+// the server key is never sent elsewhere. Use it for endpoints or methods GetJSON and PostJSON do not cover; request
+// bodies should be created with http.NewRequest so they can be replayed on
+// retries.
+//
+// Example:
+//
+//	req, _ := http.NewRequestWithContext(ctx, http.MethodDelete, "/v2/server/something", nil)
+//	err := client.DoRequest(req, nil)
+func (c *Client) DoRequest(req *http.Request, v interface{}) error {
+	if req != nil && req.URL != nil && req.URL.Host == "" {
+		path := req.URL.RequestURI()
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		u, err := url.Parse(c.baseURL + path)
+		if err != nil {
+			return fmt.Errorf("invalid request URL: %w", err)
+		}
+		req = req.Clone(req.Context())
+		req.URL = u
+		req.Host = ""
+	} else if req != nil && req.URL != nil {
+		base, err := url.Parse(c.baseURL)
+		if err != nil {
+			return fmt.Errorf("invalid base URL: %w", err)
+		}
+		if !strings.EqualFold(req.URL.Scheme, base.Scheme) || !strings.EqualFold(req.URL.Host, base.Host) {
+			return fmt.Errorf("%w: %s://%s", ErrForeignHost, req.URL.Scheme, req.URL.Host)
+		}
+	}
+	return c.doRequest(req, v)
+}
+
 // doRequest executes HTTP requests, handling authorization, rate limiting, and errors.
 func (c *Client) doRequest(req *http.Request, v interface{}) error {
 	if req == nil {
@@ -116,7 +286,35 @@ func (c *Client) doRequest(req *http.Request, v interface{}) error {
 		return fmt.Errorf("http client is nil - was NewClient() used to create the client?")
 	}
 
+	req, cancelDefault := c.applyDefaultDeadline(req)
+	defer cancelDefault()
+
+	if c.lifetime != nil {
+		if c.lifetime.Err() != nil {
+			return ErrClientClosed
+		}
+		// Release queued and in-flight requests when the client is closed.
+		ctx, cancel := context.WithCancel(req.Context())
+		defer cancel()
+		stop := context.AfterFunc(c.lifetime, cancel)
+		defer stop()
+		req = req.WithContext(ctx)
+	}
+
+	// Carry the effective tenant on the context so the queue and transport see it.
+	tenant := c.tenantOf(req.Context())
+	if tenant != "" && TenantFrom(req.Context()) != tenant {
+		req = req.WithContext(WithTenant(req.Context(), tenant))
+	}
+	publish := func(e LifecycleEvent) {
+		e.Tenant = tenant
+		c.bus.publish(e)
+	}
+
 	req.Header.Set("Server-Key", c.apiKey)
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", userAgent())
+	}
 
 	if c.apiKey == "" {
 		return fmt.Errorf("API key is empty")
@@ -128,43 +326,64 @@ func (c *Client) doRequest(req *http.Request, v interface{}) error {
 	}
 
 	if c.cache != nil && c.cache.Enabled {
-		if c.cache.Cache == nil {
-			c.cache.Cache = NewMemoryCache()
+		if c.cache.backend() == nil {
+			c.cache.Cache = newConfiguredMemoryCache(c.cache)
 		}
 
-		if req.Method == http.MethodGet && c.cache.Cache != nil {
-			cacheKey := c.cache.Prefix + req.URL.String()
-			if cached, ok := c.cache.Cache.Get(cacheKey); ok {
-				c.metricsMu.Lock()
-				c.metrics.CacheHits++
-				c.metricsMu.Unlock()
+		if req.Method == http.MethodGet {
+			cacheKey := c.cacheKey(req.URL.String())
+			// Skip the cached value after a recent command so the read reflects it.
+			bypass := c.taint.bypass(cacheKey) || isFreshRead(req.Context())
+			var cached interface{}
+			var ok bool
+			if !isCacheRead(req.Context()) {
+				var cacheErr error
+				cached, ok, cacheErr = c.cache.backend().Get(req.Context(), cacheKey)
+				if cacheErr != nil {
+					publish(LifecycleEvent{Type: LifecycleCacheError, Route: req.Method + " " + req.URL.Path, Err: cacheErr})
+				}
+			}
+			if ok && !bypass {
+				c.countCacheHit(req.Method + " " + req.URL.Path)
 				if v != nil {
-					data, err := json.Marshal(cached)
-					if err != nil {
-						return fmt.Errorf("failed to marshal cached data: %w", err)
-					}
-					return json.Unmarshal(data, v)
+					return withStage(StageDecode, c.decodeCacheValue(cached, v))
 				}
 				return nil
 			}
 			c.metricsMu.Lock()
 			c.metrics.CacheMisses++
 			c.metricsMu.Unlock()
+			publish(LifecycleEvent{Type: LifecycleCacheMiss, Route: req.Method + " " + req.URL.Path})
 		}
 	}
 
+	// streamed is set when execute decoded the response directly into v.
+	streamed := false
+
+	routeBucket := "global"
+	if req.URL.Path == "/v2/server/command" {
+		routeBucket = "command"
+	}
+	bucket := partitionKey(c.globalAPIKey, c.apiKey, routeBucket)
+	ceiling := ceilingKey(c.globalAPIKey, routeBucket)
+
 	execute := func() ([]byte, error) {
-		bucket := "global"
-		if req.URL.Path == "/v2/server/command" {
-			bucket = "command"
-		}
-		if c.apiKey != "" {
-			bucket = c.apiKey + ":" + bucket
+		// Rewind the body so the request can be sent again when the queue retries it.
+		if req.GetBody != nil {
+			if rc, err := req.GetBody(); err == nil {
+				req.Body = rc
+			}
 		}
 
 		if c.rateLimiter != nil {
-			if wait, shouldWait := c.rateLimiter.ShouldWait(bucket); shouldWait {
-				time.Sleep(wait)
+			if wait, shouldWait := c.rateLimiter.ShouldWaitAny(bucket, ceiling); shouldWait {
+				timer := time.NewTimer(wait)
+				select {
+				case <-timer.C:
+				case <-req.Context().Done():
+					timer.Stop()
+					return nil, withStage(StageRateLimit, classifyTransportErr(req.Context(), req.Context().Err()))
+				}
 			}
 		}
 
@@ -172,10 +391,23 @@ func (c *Client) doRequest(req *http.Request, v interface{}) error {
 			return nil, fmt.Errorf("http client not initialized")
 		}
 
+		routeName := req.Method + " " + req.URL.Path
+		publish(LifecycleEvent{Type: LifecycleRequestStarted, Route: routeName})
+
 		start := time.Now()
-		resp, err := c.httpClient.Do(req)
+		resp, err := c.doHTTP(req, routeName)
 		duration := time.Since(start)
 
+		if c.rateLimiter != nil && resp != nil {
+			c.rateLimiter.recordTenant(tenant, routeBucket == "command", resp.StatusCode == http.StatusTooManyRequests)
+		}
+
+		finished := LifecycleEvent{Type: LifecycleRequestFinished, Route: routeName, Duration: duration, Err: err}
+		if resp != nil {
+			finished.StatusCode = resp.StatusCode
+		}
+		publish(finished)
+
 		c.metricsMu.Lock()
 		c.metrics.TotalRequests++
 		if c.metrics.AvgResponseTime == 0 {
@@ -186,18 +418,62 @@ func (c *Client) doRequest(req *http.Request, v interface{}) error {
 		c.metricsMu.Unlock()
 
 		if err != nil {
-			return nil, fmt.Errorf("request failed: %w", err)
+			return nil, withStage(StageTransport, fmt.Errorf("request failed: %w", classifyTransportErr(req.Context(), err)))
 		}
 		if resp == nil {
-			return nil, fmt.Errorf("received nil response")
+			return nil, withStage(StageTransport, fmt.Errorf("received nil response"))
 		}
 		defer resp.Body.Close()
 
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read response: %w", err)
+		if c.maxResponseBytes > 0 && resp.ContentLength > c.maxResponseBytes {
+			return nil, withStage(StageTransport, fmt.Errorf("%w: %d bytes", ErrResponseTooLarge, resp.ContentLength))
+		}
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			if err := checkContentType(resp); err != nil {
+				return nil, withStage(StageDecode, err)
+			}
+		}
+
+		var body []byte
+		src := c.limitBody(resp.Body)
+		stream := c.canStream(req, resp, v)
+		if stream && resp.ContentLength < 0 {
+			// The length is unknown, as for gzip or chunked responses. Buffer
+			// up to the threshold and stream only if the body is larger, so
+			// small bodies are still checked for error envelopes.
+			head, err := io.ReadAll(io.LimitReader(src, streamThreshold+1))
+			if err != nil {
+				return nil, withStage(StageTransport, fmt.Errorf("failed to read response: %w", err))
+			}
+			if len(head) <= streamThreshold {
+				body, stream = head, false
+			} else {
+				src = io.MultiReader(bytes.NewReader(head), src)
+			}
+		} else if !stream {
+			body, err = io.ReadAll(src)
+			if err != nil {
+				return nil, withStage(StageTransport, fmt.Errorf("failed to read response: %w", err))
+			}
+		}
+		if stream {
+			// Decode large payloads straight from the connection instead of
+			// holding the raw body and the decoded value in memory at once.
+			counted := &countingReader{r: src}
+			if err := json.NewDecoder(counted).Decode(v); err != nil {
+				if c.maxResponseBytes > 0 && counted.n > c.maxResponseBytes {
+					return nil, withStage(StageTransport, fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, c.maxResponseBytes))
+				}
+				return nil, withStage(StageDecode, err)
+			}
+			streamed = true
+		} else if c.maxResponseBytes > 0 && int64(len(body)) > c.maxResponseBytes {
+			return nil, withStage(StageTransport, fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, c.maxResponseBytes))
 		}
 
+		c.checkDeprecation(routeName, resp.Header)
+
 		rl := parseRateLimitHeaders(resp.Header)
 		ra := (*time.Duration)(nil)
 		if resp.StatusCode == http.StatusTooManyRequests {
@@ -218,25 +494,46 @@ func (c *Client) doRequest(req *http.Request, v interface{}) error {
 
 		if c.rateLimiter != nil {
 			if rl != nil {
-				targetBucket := rl.Bucket
-				if targetBucket == "" {
-					targetBucket = bucket
-				} else if c.apiKey != "" {
-					targetBucket = c.apiKey + ":" + targetBucket
+				targetBucket, targetCeiling := bucket, ceiling
+				if rl.Bucket != "" {
+					targetBucket = partitionKey(c.globalAPIKey, c.apiKey, rl.Bucket)
+					targetCeiling = ceilingKey(c.globalAPIKey, rl.Bucket)
+				}
+				c.updateRateLimit(targetBucket, rl.Limit, rl.Remaining, rl.ResetAt)
+				// Requests made with a global key count against its
+				// account-wide budget, which the headers then describe, so
+				// every server sharing the key sees it before it runs out.
+				if targetCeiling != "" {
+					c.updateRateLimit(targetCeiling, rl.Limit, rl.Remaining, rl.ResetAt)
 				}
-				c.rateLimiter.UpdateFromHeaders(targetBucket, rl.Limit, rl.Remaining, rl.ResetAt)
 			} else if resp.StatusCode == http.StatusTooManyRequests {
 				if ra != nil {
-					c.rateLimiter.UpdateFromHeaders(bucket, 0, 0, time.Now().Add(*ra))
+					c.updateRateLimit(bucket, 0, 0, time.Now().Add(*ra))
 				} else {
-					c.rateLimiter.UpdateFromHeaders(bucket, 0, 0, time.Now().Add(time.Second*5))
+					c.updateRateLimit(bucket, 0, 0, time.Now().Add(time.Second*5))
+				}
+			}
+
+			// A global 429 means the whole global key is exhausted, so every
+			// server sharing it has to back off, not just this one.
+			if resp.StatusCode == http.StatusTooManyRequests && ceiling != "" && parseRateLimitGlobal(body) {
+				reset := time.Now().Add(time.Second * 5)
+				if ra != nil {
+					reset = time.Now().Add(*ra)
 				}
+				c.updateRateLimit(ceiling, 0, 0, reset)
 			}
 		}
 
-		routeName := req.Method + " " + req.URL.Path
+		if resp.StatusCode == http.StatusTooManyRequests {
+			rateLimited := LifecycleEvent{Type: LifecycleRateLimited, Route: routeName, StatusCode: resp.StatusCode}
+			if ra != nil {
+				rateLimited.Duration = *ra
+			}
+			publish(rateLimited)
+		}
 
-		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 || isErrorEnvelope(body) {
 			apiErr := &APIError{
 				StatusCode: resp.StatusCode,
 				Body:       body,
@@ -244,12 +541,9 @@ func (c *Client) doRequest(req *http.Request, v interface{}) error {
 				RateLimit:  rl,
 				RetryAfter: ra,
 			}
-			if len(body) > 0 {
-				if err := json.Unmarshal(body, apiErr); err != nil {
-					apiErr.Message = string(body)
-				}
-			} else {
-				apiErr.Message = fmt.Sprintf("unknown error (status %d)", resp.StatusCode)
+			parseErrorBody(apiErr, body, resp.Header.Get("Content-Type"))
+			if apiErr.RetryAfter == nil {
+				apiErr.RetryAfter = edgeRetryHint(apiErr)
 			}
 
 			c.metricsMu.Lock()
@@ -271,7 +565,7 @@ func (c *Client) doRequest(req *http.Request, v interface{}) error {
 				})
 			}
 
-			if c.cache != nil && c.cache.StaleIfError && c.cache.Cache != nil {
+			if c.cache != nil && c.cache.StaleIfError && c.cache.backend() != nil {
 				// Stale data fallback is handled locally by the caller upon receiving this error
 			}
 			return nil, apiErr
@@ -279,10 +573,16 @@ func (c *Client) doRequest(req *http.Request, v interface{}) error {
 
 		if resp.StatusCode == http.StatusOK {
 			// Populate cache
-			var rawData interface{}
-			if err := json.Unmarshal(body, &rawData); err == nil {
-				if c.cache != nil && c.cache.Enabled && c.cache.Cache != nil && req.Method == http.MethodGet {
-					c.cache.Cache.Set(c.cache.Prefix+req.URL.String(), rawData, c.cache.TTL)
+			if c.cache != nil && c.cache.Enabled && c.cache.backend() != nil && req.Method == http.MethodGet {
+				if value, err := c.encodeCacheValue(body, v); err == nil {
+					key := c.cacheKey(req.URL.String())
+					if batch := cacheBatchFrom(req.Context()); batch != nil {
+						batch.add(c, key, value, start)
+					} else if err := c.cache.backend().Set(req.Context(), key, value, c.cache.TTL); err != nil {
+						publish(LifecycleEvent{Type: LifecycleCacheError, Route: routeName, Err: err})
+					} else {
+						c.taint.markRefreshed(key, start)
+					}
 				}
 			}
 		}
@@ -306,31 +606,74 @@ func (c *Client) doRequest(req *http.Request, v interface{}) error {
 	var err error
 
 	runWithQueue := func() ([]byte, error) {
+		// Take the tenant's quota once per request, so queue retries after a
+		// 429 do not use up more of it.
+		if c.rateLimiter != nil {
+			if err := c.rateLimiter.reserveTenant(tenant, time.Now()); err != nil {
+				return nil, withStage(StageRateLimit, err)
+			}
+		}
 		if c.queue != nil {
 			var b []byte
 			var e error
-			qErr := c.queue.Enqueue(req.Context(), func() error {
+			attempts := 0
+			routeName := req.Method + " " + req.URL.Path
+			publish(LifecycleEvent{Type: LifecycleQueueEnqueued, Route: routeName})
+			qCtx := withQueuePartitions(req.Context(), bucket, ceiling)
+			qErr := c.queue.Enqueue(qCtx, func() error {
+				attempts++
+				if attempts == 1 {
+					publish(LifecycleEvent{Type: LifecycleQueueDequeued, Route: routeName})
+				} else {
+					publish(LifecycleEvent{Type: LifecycleRetry, Route: routeName, Attempt: attempts})
+				}
 				b, e = execute()
+				if apiErr, ok := e.(*APIError); ok && apiErr.StatusCode == http.StatusTooManyRequests {
+					e = &ErrQueuedRateLimited{RetryAfter: retryAfterOf(apiErr), Err: apiErr}
+				}
 				return e
 			})
 			if qErr != nil {
-				return nil, qErr
+				if attempts == 0 {
+					return nil, withStage(StageQueue, classifyQueueErr(qErr))
+				}
+				// Only requests the queue retried have failed repeatedly.
+				if c.deadLetterHandler != nil && attempts > 1 && retryableFailure(qErr) {
+					c.deadLetterHandler(newDeadLetter(req, attempts, qErr))
+				}
+				return nil, &QueueError{Route: routeName, Attempts: attempts, Err: qErr}
 			}
 			return b, e
 		}
 		return execute()
 	}
 
-	// Request Coalescing for GET requests
+	// Request Coalescing for GET requests. Tenant quotas are reserved by the
+	// leader, so only requests of the same tenant share a result.
 	if req.Method == http.MethodGet {
-		key := req.URL.String()
+		key := tenant + "\x00" + req.URL.String()
+		leader := false
 		res, doErr := c.requestGroup.Do(key, func() (interface{}, error) {
-			return runWithQueue()
+			leader = true
+			b, e := runWithQueue()
+			if streamed {
+				return coalescedResult{decoded: v}, e
+			}
+			return coalescedResult{body: b}, e
 		})
 		if doErr != nil {
 			err = doErr
-		} else if res != nil {
-			body = res.([]byte)
+		} else if res, ok := res.(coalescedResult); ok {
+			body = res.body
+			if res.decoded != nil && !leader && v != nil {
+				// Copy rather than share the leader's value so callers can't
+				// observe each other's mutations.
+				data, err := json.Marshal(res.decoded)
+				if err != nil {
+					return withStage(StageDecode, err)
+				}
+				return withStage(StageDecode, json.Unmarshal(data, v))
+			}
 		}
 	} else {
 		body, err = runWithQueue()
@@ -338,11 +681,13 @@ func (c *Client) doRequest(req *http.Request, v interface{}) error {
 
 	if err != nil {
 		// Try stale cache if enabled
-		if c.cache != nil && c.cache.StaleIfError && c.cache.Cache != nil {
-			if cached, ok := c.cache.Cache.Get(c.cache.Prefix + req.URL.String()); ok {
+		if c.cache != nil && c.cache.StaleIfError && c.cache.backend() != nil {
+			// Use a background context: the request context may be the reason we failed.
+			if cached, ok, _ := c.cache.backend().Get(context.Background(), c.cacheKey(req.URL.String())); ok {
 				if v != nil {
-					data, _ := json.Marshal(cached)
-					return json.Unmarshal(data, v)
+					if decodeErr := c.decodeCacheValue(cached, v); decodeErr != nil {
+						return &CacheFallbackError{Err: err, FallbackErr: withStage(StageDecode, decodeErr)}
+					}
 				}
 				return nil
 			}
@@ -351,7 +696,10 @@ func (c *Client) doRequest(req *http.Request, v interface{}) error {
 	}
 
 	if v != nil && body != nil {
-		return json.Unmarshal(body, v)
+		if isEmptyBody(body) {
+			return withStage(StageDecode, c.decodeEmptyBody(v))
+		}
+		return withStage(StageDecode, c.decode(req.Context(), body, v))
 	}
 
 	return nil
@@ -392,3 +740,35 @@ func (g *group) Do(key string, fn func() (interface{}, error)) (interface{}, err
 
 	return c.val, c.err
 }
+
+// updateRateLimit records bucket state in the limiter and, once the bucket is
+// exhausted, pauses that bucket's requests in the queue until it resets so
+// they keep their order instead of each worker sleeping on its own. Other
+// servers and buckets sharing the queue carry on.
+func (c *Client) updateRateLimit(bucket string, limit, remaining int, reset time.Time) {
+	c.rateLimiter.UpdateFromHeaders(bucket, limit, remaining, reset)
+	if c.queue != nil && remaining <= 0 && time.Until(reset) > 0 {
+		c.queue.pausePartition(bucket, reset)
+	}
+}
+
+// retryAfterOf returns how long to wait before retrying a rate limited request,
+// preferring the explicit Retry-After value over the bucket reset time.
+func retryAfterOf(apiErr *APIError) time.Duration {
+	if apiErr.RetryAfter != nil {
+		return *apiErr.RetryAfter
+	}
+	if apiErr.RateLimit != nil && !apiErr.RateLimit.ResetAt.IsZero() {
+		if d := time.Until(apiErr.RateLimit.ResetAt); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// coalescedResult is shared between callers of a coalesced GET. Exactly one
+// of body and decoded is set, depending on whether the response was streamed.
+type coalescedResult struct {
+	body    []byte
+	decoded interface{}
+}
@@ -0,0 +1,128 @@
+package erlcgo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// exhaustedServer answers every request with an exhausted rate limit budget
+// that resets in ten seconds, so the next request has to wait.
+func exhaustedServer(t *testing.T) (*httptest.Server, *int32) {
+	t.Helper()
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("X-RateLimit-Bucket", "global")
+		w.Header().Set("X-RateLimit-Limit", "35")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(10*time.Second).Unix(), 10))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"Name":"Test"}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func TestRateLimitWaitReleasesCanceledCaller(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		opts   []ClientOption
+		reason error
+		stage  Stage
+		ctx    func() (context.Context, context.CancelFunc)
+	}{
+		{
+			name:   "canceled",
+			reason: ErrCallerCanceled,
+			stage:  StageRateLimit,
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(50*time.Millisecond, cancel)
+				return ctx, cancel
+			},
+		},
+		{
+			name:   "deadline",
+			reason: ErrCallerDeadline,
+			stage:  StageRateLimit,
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 50*time.Millisecond)
+			},
+		},
+		{
+			// The queue holds requests for an exhausted bucket itself.
+			name:   "queued",
+			opts:   []ClientOption{WithRequestQueue(1, time.Millisecond)},
+			reason: ErrCallerCanceled,
+			stage:  StageQueue,
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(50*time.Millisecond, cancel)
+				return ctx, cancel
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv, hits := exhaustedServer(t)
+			c := NewClient("key", append([]ClientOption{WithBaseURL(srv.URL)}, tc.opts...)...)
+			defer c.Close()
+
+			// The first request spends the budget.
+			if _, err := c.GetServer(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := tc.ctx()
+			defer cancel()
+			start := time.Now()
+			_, err := c.GetServer(ctx)
+			elapsed := time.Since(start)
+
+			if elapsed > time.Second {
+				t.Errorf("canceled caller released after %v, want well under the 10s reset", elapsed)
+			}
+			if !errors.Is(err, tc.reason) {
+				t.Errorf("got %v, want %v", err, tc.reason)
+			}
+			if stage := StageOf(err); stage != tc.stage {
+				t.Errorf("stage %q, want %q", stage, tc.stage)
+			}
+			if n := atomic.LoadInt32(hits); n != 1 {
+				t.Errorf("server got %d requests, want 1; the waiting request must not be sent", n)
+			}
+		})
+	}
+}
+
+func TestGlobalKeyCeilingFromHeaders(t *testing.T) {
+	srv, hits := exhaustedServer(t)
+	limiter := NewRateLimiter()
+	first := NewClient("server-a", WithBaseURL(srv.URL), WithGlobalAPIKey("global"), WithRateLimiter(limiter))
+	defer first.Close()
+	second := NewClient("server-b", WithBaseURL(srv.URL), WithGlobalAPIKey("global"), WithRateLimiter(limiter))
+	defer second.Close()
+
+	// A successful response spends the global key's budget without a 429.
+	if _, err := first.GetServer(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if rl, ok := limiter.Snapshot(ceilingKey("global", "global")); !ok || rl.Remaining != 0 || rl.Limit != 35 {
+		t.Fatalf("ceiling = %+v, %v; want it recorded from the headers", rl, ok)
+	}
+
+	// Another server on the same key waits for the shared ceiling.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := second.GetServer(ctx); !errors.Is(err, ErrCallerDeadline) {
+		t.Errorf("got %v, want %v", err, ErrCallerDeadline)
+	}
+	if n := atomic.LoadInt32(hits); n != 1 {
+		t.Errorf("server got %d requests, want 1", n)
+	}
+}
@@ -0,0 +1,67 @@
+package erlcgo
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// defaultBanPollInterval is how often subscriptions poll the ban list.
+// Bans change rarely and the endpoint is not part of /v2/server, so it is
+// polled much less often than the server itself.
+const defaultBanPollInterval = time.Minute
+
+// ERLCBans maps banned Roblox user IDs to usernames.
+type ERLCBans map[string]string
+
+// BanEvent is a change in the server's ban list.
+type BanEvent struct {
+	UserID string
+	// Name is the banned username. It may be empty for "unbanned" events
+	// restored from a checkpoint, which only stores IDs.
+	Name string
+	Type string // "banned" or "unbanned"
+	// ObservedAt is when the poll that saw the change was made.
+	ObservedAt time.Time
+}
+
+// ID returns a content hash of the ban change and the poll that saw it, so a
+// user banned again after an unban gets a new ID.
+func (e BanEvent) ID() string {
+	return hashFields("ban", e.Type, e.UserID, observedField(e.ObservedAt))
+}
+
+// GetBans returns the server's ban list.
+//
+// Example:
+//
+//	bans, err := client.GetBans(ctx)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for id, name := range bans {
+//	    fmt.Printf("%s (%s) is banned\n", name, id)
+//	}
+func (c *Client) GetBans(ctx context.Context) (ERLCBans, error) {
+	var bans ERLCBans
+	err := c.get(ctx, "/v1/server/bans", &bans)
+	return bans, err
+}
+
+// diffBans compares two ban lists, returning changes ordered by user ID and
+// stamped with the poll time at.
+func diffBans(old, current ERLCBans, at time.Time) []BanEvent {
+	changes := make([]BanEvent, 0)
+	for id, name := range current {
+		if _, ok := old[id]; !ok {
+			changes = append(changes, BanEvent{UserID: id, Name: name, Type: "banned", ObservedAt: at})
+		}
+	}
+	for id, name := range old {
+		if _, ok := current[id]; !ok {
+			changes = append(changes, BanEvent{UserID: id, Name: name, Type: "unbanned", ObservedAt: at})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].UserID < changes[j].UserID })
+	return changes
+}
@@ -0,0 +1,183 @@
+package erlcgo
+
+import (
+	"context"
+	"time"
+)
+
+// Operation is a single unit of work run by a Batcher, such as one command or read.
+type Operation func(ctx context.Context) error
+
+// BatchChunk is a run of operations executed back to back after waiting Delay.
+type BatchChunk struct {
+	Start int           // Index of the first operation in the chunk
+	End   int           // Index after the last operation in the chunk
+	Delay time.Duration // Wait before starting the chunk, relative to the previous chunk
+}
+
+// BatchPlan is how a Batcher intends to spread operations over time.
+type BatchPlan struct {
+	Chunks []BatchChunk
+	// Estimated is the estimated time to run every chunk.
+	Estimated time.Duration
+	// FitsDeadline is false if the plan is not expected to finish before the deadline.
+	FitsDeadline bool
+}
+
+// BatchResult reports the outcome of Batcher.Run.
+type BatchResult struct {
+	// Errors holds the error for each operation, by index. Operations that were
+	// not run because the batch was cut short have a nil entry and are counted
+	// by Done.
+	Errors []error
+	// Done is the number of operations that were run.
+	Done int
+}
+
+// Batcher paces a large number of operations against the client's live rate
+// limit state. It spends the remaining budget of the current window first and
+// then runs one window's worth of operations each time the bucket resets.
+//
+// Example:
+//
+//	b := &erlcgo.Batcher{Client: client, Commands: true, Deadline: time.Now().Add(time.Minute)}
+//	ops := make([]erlcgo.Operation, 0, len(players))
+//	for _, p := range players {
+//	    cmd := ":pm " + p + " Server restarting soon"
+//	    ops = append(ops, func(ctx context.Context) error { return client.ExecuteCommand(ctx, cmd) })
+//	}
+//	result, err := b.Run(ctx, ops)
+type Batcher struct {
+	Client *Client
+
+	// Commands selects the command rate limit bucket instead of the global one.
+	Commands bool
+
+	// Deadline, when non-zero, stops the batch once it passes.
+	Deadline time.Time
+
+	// Window is the assumed length of a rate limit window once the current
+	// one resets. Defaults to one second.
+	Window time.Duration
+
+	// MinInterval paces operations when no rate limit information is known yet.
+	// Defaults to one second.
+	MinInterval time.Duration
+
+	// OnProgress, if set, is called after each operation.
+	OnProgress ProgressFunc
+
+	// fleet paces against the global key ceiling alone, for operations that
+	// each target a different server sharing Client's global key.
+	fleet bool
+}
+
+func (b *Batcher) window() time.Duration {
+	if b.Window > 0 {
+		return b.Window
+	}
+	return time.Second
+}
+
+func (b *Batcher) minInterval() time.Duration {
+	if b.MinInterval > 0 {
+		return b.MinInterval
+	}
+	return time.Second
+}
+
+// rateLimitState returns the tighter of the server partition and the global
+// key ceiling for the batcher's bucket.
+func (b *Batcher) rateLimitState() (RateLimit, bool) {
+	if b.Client == nil || b.Client.rateLimiter == nil {
+		return RateLimit{}, false
+	}
+	routeBucket := "global"
+	if b.Commands {
+		routeBucket = "command"
+	}
+	if b.fleet {
+		if ceiling := ceilingKey(b.Client.globalAPIKey, routeBucket); ceiling != "" {
+			return b.Client.rateLimiter.Snapshot(ceiling)
+		}
+	}
+	return b.Client.rateLimitBudget(routeBucket)
+}
+
+// Plan computes how n operations would be spread over time given the current
+// rate limit state. It does not run anything.
+func (b *Batcher) Plan(n int) BatchPlan {
+	var plan BatchPlan
+	if n <= 0 {
+		plan.FitsDeadline = true
+		return plan
+	}
+
+	state, known := b.rateLimitState()
+	if !known || state.Limit <= 0 {
+		for i := 0; i < n; i++ {
+			delay := time.Duration(0)
+			if i > 0 {
+				delay = b.minInterval()
+			}
+			plan.Chunks = append(plan.Chunks, BatchChunk{Start: i, End: i + 1, Delay: delay})
+			plan.Estimated += delay
+		}
+	} else {
+		start := 0
+		if state.Remaining > 0 {
+			end := min(n, state.Remaining)
+			plan.Chunks = append(plan.Chunks, BatchChunk{Start: 0, End: end})
+			start = end
+		}
+		first := true
+		for start < n {
+			delay := b.window()
+			if first {
+				delay = max(time.Until(state.Reset), 0)
+				first = false
+			}
+			end := min(n, start+state.Limit)
+			plan.Chunks = append(plan.Chunks, BatchChunk{Start: start, End: end, Delay: delay})
+			plan.Estimated += delay
+			start = end
+		}
+	}
+
+	plan.FitsDeadline = b.Deadline.IsZero() || time.Now().Add(plan.Estimated).Before(b.Deadline)
+	return plan
+}
+
+// Run executes ops according to Plan. It stops early when ctx is done or the
+// deadline passes, returning the partial result together with the reason.
+func (b *Batcher) Run(ctx context.Context, ops []Operation) (BatchResult, error) {
+	result := BatchResult{Errors: make([]error, len(ops))}
+	if !b.Deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, b.Deadline)
+		defer cancel()
+	}
+
+	for _, chunk := range b.Plan(len(ops)).Chunks {
+		if chunk.Delay > 0 {
+			timer := time.NewTimer(chunk.Delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return result, ctx.Err()
+			case <-timer.C:
+			}
+		}
+		for i := chunk.Start; i < chunk.End; i++ {
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+			result.Errors[i] = ops[i](ctx)
+			result.Done++
+			if b.OnProgress != nil {
+				b.OnProgress(result.Done, len(ops), result.Errors[i])
+			}
+		}
+	}
+	return result, nil
+}
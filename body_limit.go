@@ -0,0 +1,60 @@
+package erlcgo
+
+import (
+	"errors"
+	"io"
+	"net/http"
+)
+
+// streamThreshold is the Content-Length above which successful GET responses
+// are decoded as a stream rather than buffered.
+const streamThreshold = 256 << 10
+
+// ErrResponseTooLarge is returned when a response exceeds WithMaxResponseBytes.
+var ErrResponseTooLarge = errors.New("erlc: response body too large")
+
+// WithMaxResponseBytes limits how large a response body may be. Larger
+// responses fail with ErrResponseTooLarge instead of being read into memory,
+// protecting small hosts from unexpectedly huge log payloads. Zero, the
+// default, means no limit.
+func WithMaxResponseBytes(n int64) ClientOption {
+	return func(c *Client) {
+		c.maxResponseBytes = n
+	}
+}
+
+// limitBody caps how much of a response body can be read. One extra byte is
+// allowed so that exceeding the limit can be detected.
+func (c *Client) limitBody(r io.Reader) io.Reader {
+	if c.maxResponseBytes <= 0 {
+		return r
+	}
+	return io.LimitReader(r, c.maxResponseBytes+1)
+}
+
+// canStream reports whether a response can be decoded directly into v.
+// Streaming is only used for large successful GETs when nothing else needs the
+// raw body: caching stores it, and error detection inspects it, but error
+// bodies are small, so large responses are never error envelopes. Responses
+// of unknown length, such as gzip or chunked ones, qualify too; the caller
+// buffers them up to streamThreshold before deciding.
+func (c *Client) canStream(req *http.Request, resp *http.Response, v interface{}) bool {
+	return v != nil &&
+		req.Method == http.MethodGet &&
+		resp.StatusCode == http.StatusOK &&
+		(resp.ContentLength > streamThreshold || resp.ContentLength < 0) &&
+		(c.cache == nil || !c.cache.Enabled)
+}
+
+// countingReader counts the bytes read through it, so a streamed decode that
+// failed can tell a truncated oversized body from malformed JSON.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
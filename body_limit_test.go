@@ -0,0 +1,95 @@
+package erlcgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// chunkedServer answers with body, flushing first so it is sent chunked,
+// without a Content-Length.
+func chunkedServer(t *testing.T, body []byte) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.(http.Flusher).Flush()
+		w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func largeServerBody(t *testing.T) []byte {
+	t.Helper()
+	resp := ERLCServerResponse{Name: "Large"}
+	for i := 0; i < 5000; i++ {
+		resp.CommandLogs = append(resp.CommandLogs, ERLCCommandLog{Player: "Admin:1", Timestamp: int64(i), Command: ":h message " + strconv.Itoa(i)})
+	}
+	body, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(body) <= streamThreshold {
+		t.Fatalf("body of %d bytes is below the stream threshold", len(body))
+	}
+	return body
+}
+
+func TestCanStreamUnknownLength(t *testing.T) {
+	c := NewClient("key")
+	defer c.Close()
+	req := httptest.NewRequest(http.MethodGet, "/v2/server", nil)
+	var v ERLCServerResponse
+	for _, tc := range []struct {
+		length int64
+		want   bool
+	}{
+		{-1, true},
+		{streamThreshold + 1, true},
+		{1024, false},
+	} {
+		resp := &http.Response{StatusCode: http.StatusOK, ContentLength: tc.length}
+		if got := c.canStream(req, resp, &v); got != tc.want {
+			t.Errorf("Content-Length %d: canStream %v, want %v", tc.length, got, tc.want)
+		}
+	}
+}
+
+func TestChunkedResponses(t *testing.T) {
+	t.Run("large", func(t *testing.T) {
+		srv := chunkedServer(t, largeServerBody(t))
+		c := NewClient("key", WithBaseURL(srv.URL))
+		defer c.Close()
+		resp, err := c.GetServer(context.Background(), ServerQueryOptions{CommandLogs: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.CommandLogs) != 5000 {
+			t.Errorf("got %d command logs, want 5000", len(resp.CommandLogs))
+		}
+	})
+
+	t.Run("over the limit", func(t *testing.T) {
+		body := largeServerBody(t)
+		srv := chunkedServer(t, body)
+		c := NewClient("key", WithBaseURL(srv.URL), WithMaxResponseBytes(int64(len(body)-100)))
+		defer c.Close()
+		if _, err := c.GetServer(context.Background(), ServerQueryOptions{CommandLogs: true}); !errors.Is(err, ErrResponseTooLarge) {
+			t.Errorf("got %v, want ErrResponseTooLarge", err)
+		}
+	})
+
+	t.Run("error envelope", func(t *testing.T) {
+		srv := chunkedServer(t, []byte(`{"code":2002,"message":"invalid server key"}`))
+		c := NewClient("key", WithBaseURL(srv.URL))
+		defer c.Close()
+		var apiErr *APIError
+		if _, err := c.GetServer(context.Background()); !errors.As(err, &apiErr) {
+			t.Errorf("got %v, want an APIError for the small error envelope", err)
+		}
+	})
+}
@@ -0,0 +1,229 @@
+package erlcgo
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrBrokerClosed is returned by Consumer.Next once the broker's source has
+// closed and the consumer has read every remaining event.
+var ErrBrokerClosed = errors.New("erlc: broker closed")
+
+// BrokerConfig configures a Broker.
+type BrokerConfig struct {
+	// MaxBacklog is the most events kept for consumers that have not acked
+	// them. When exceeded, the oldest events are dropped and counted in
+	// Consumer.Dropped. Defaults to 10000.
+	MaxBacklog int
+}
+
+// Broker fans one subscription out to several independent consumers. Each
+// consumer reads at its own pace and acks what it has processed, so a slow
+// consumer such as a Discord relay never blocks a fast one such as metrics.
+// Events are kept until every consumer has acked them, up to MaxBacklog.
+//
+// Example:
+//
+//	sub, _ := client.Subscribe(ctx, erlcgo.EventTypeKills, erlcgo.EventTypeModCalls)
+//	broker := erlcgo.NewBroker(sub, erlcgo.BrokerConfig{})
+//
+//	discord := broker.Consumer("discord")
+//	go func() {
+//	    for {
+//	        event, offset, err := discord.Next(ctx)
+//	        if err != nil {
+//	            return
+//	        }
+//	        if postToDiscord(event) == nil {
+//	            discord.Ack(offset)
+//	        } else {
+//	            discord.Rewind()
+//	        }
+//	    }
+//	}()
+type Broker struct {
+	config BrokerConfig
+
+	mu        sync.Mutex
+	log       []Event
+	base      int64 // offset of log[0]
+	consumers map[string]*Consumer
+	notify    chan struct{}
+	closed    bool
+}
+
+// Consumer reads events from a Broker. Offsets increase by one per event and
+// are shared by all consumers of the broker.
+type Consumer struct {
+	broker  *Broker
+	name    string
+	next    int64
+	acked   int64
+	dropped int64
+}
+
+// NewBroker starts reading events from sub.
+func NewBroker(sub *Subscription, config BrokerConfig) *Broker {
+	if config.MaxBacklog <= 0 {
+		config.MaxBacklog = 10000
+	}
+	b := &Broker{
+		config:    config,
+		consumers: make(map[string]*Consumer),
+		notify:    make(chan struct{}),
+	}
+	spawn(b, "broker pump", func() { b.pump(sub.Events) })
+	return b
+}
+
+func (b *Broker) pump(events <-chan Event) {
+	for event := range events {
+		b.mu.Lock()
+		b.log = append(b.log, event)
+		b.trim()
+		b.wakeLocked()
+		b.mu.Unlock()
+	}
+	b.mu.Lock()
+	b.closed = true
+	b.wakeLocked()
+	b.mu.Unlock()
+}
+
+// wakeLocked wakes every consumer waiting in Next.
+func (b *Broker) wakeLocked() {
+	close(b.notify)
+	b.notify = make(chan struct{})
+}
+
+// Consumer returns the consumer with the given name, creating it if needed.
+// New consumers start with the next event to arrive.
+func (b *Broker) Consumer(name string) *Consumer {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if c, ok := b.consumers[name]; ok {
+		return c
+	}
+	tail := b.base + int64(len(b.log))
+	c := &Consumer{broker: b, name: name, next: tail, acked: tail}
+	b.consumers[name] = c
+	return c
+}
+
+// Remove detaches a consumer so it no longer holds back event retention.
+func (b *Broker) Remove(name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.consumers, name)
+	b.trim()
+}
+
+// trim drops events every consumer has acked, and the oldest events beyond
+// MaxBacklog.
+func (b *Broker) trim() {
+	low := b.base + int64(len(b.log))
+	for _, c := range b.consumers {
+		if c.acked < low {
+			low = c.acked
+		}
+	}
+	if over := int64(len(b.log) - b.config.MaxBacklog); over > 0 && b.base+over > low {
+		low = b.base + over
+	}
+	if n := low - b.base; n > 0 {
+		b.log = append(b.log[:0:0], b.log[n:]...)
+		b.base = low
+	}
+}
+
+// Name returns the consumer's name.
+func (c *Consumer) Name() string {
+	return c.name
+}
+
+// Next returns the consumer's next event and its offset, waiting for one to
+// arrive if needed.
+func (c *Consumer) Next(ctx context.Context) (Event, int64, error) {
+	b := c.broker
+	for {
+		b.mu.Lock()
+		if c.next < b.base {
+			c.dropped += b.base - c.next
+			c.next = b.base
+		}
+		if c.acked < b.base {
+			c.acked = b.base
+		}
+		if i := c.next - b.base; i < int64(len(b.log)) {
+			event, offset := b.log[i], c.next
+			c.next++
+			b.mu.Unlock()
+			return event, offset, nil
+		}
+		if b.closed {
+			b.mu.Unlock()
+			return Event{}, 0, ErrBrokerClosed
+		}
+		wait := b.notify
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return Event{}, 0, ctx.Err()
+		case <-wait:
+		}
+	}
+}
+
+// Ack marks every event up to and including offset as processed.
+func (c *Consumer) Ack(offset int64) {
+	b := c.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if offset+1 > c.acked {
+		c.acked = offset + 1
+		if c.next < c.acked {
+			c.next = c.acked
+		}
+		b.trim()
+	}
+}
+
+// Rewind makes Next redeliver events that were read but not yet acked.
+func (c *Consumer) Rewind() {
+	b := c.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c.next = c.acked
+}
+
+// Offset returns the offset of the next event the consumer will read.
+func (c *Consumer) Offset() int64 {
+	b := c.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return c.next
+}
+
+// Lag returns how many retained events the consumer has not yet acked.
+func (c *Consumer) Lag() int64 {
+	b := c.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	acked := c.acked
+	if acked < b.base {
+		acked = b.base
+	}
+	return b.base + int64(len(b.log)) - acked
+}
+
+// Dropped returns how many events the consumer missed because it fell more
+// than MaxBacklog events behind.
+func (c *Consumer) Dropped() int64 {
+	b := c.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return c.dropped
+}
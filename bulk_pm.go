@@ -0,0 +1,73 @@
+package erlcgo
+
+import (
+	"context"
+	"time"
+
+	"github.com/bmrgcorp/erlcgo/commands"
+)
+
+// PMPlayers sends message privately to each player, one after another,
+// pacing the commands so they spread over the command rate limit window
+// instead of exhausting it at once. It returns the error for each player
+// whose message failed; a nil map means every message was sent. If ctx ends,
+// players not yet messaged get ctx's error.
+//
+// Example:
+//
+//	failed := client.PMPlayers(ctx, staff, "Shift starts in 10 minutes.")
+//	for player, err := range failed {
+//	    log.Printf("could not message %s: %v", player, err)
+//	}
+func (c *Client) PMPlayers(ctx context.Context, players []string, message string) map[string]error {
+	var failed map[string]error
+	fail := func(player string, err error) {
+		if failed == nil {
+			failed = make(map[string]error)
+		}
+		failed[player] = err
+	}
+
+	for i, player := range players {
+		if i > 0 {
+			if wait := c.commandPacing(); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+				case <-timer.C:
+				}
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			for _, rest := range players[i:] {
+				fail(rest, err)
+			}
+			return failed
+		}
+
+		if err := c.Execute(ctx, commands.PM(player, message)); err != nil {
+			fail(player, err)
+		}
+	}
+	return failed
+}
+
+// commandPacing returns how long to wait between bulk commands so the known
+// remaining command budget lasts until the window resets. It is zero while
+// the budget is unknown; the limiter still holds requests back when it runs
+// out.
+func (c *Client) commandPacing() time.Duration {
+	if c.rateLimiter == nil {
+		return 0
+	}
+	state, ok := c.rateLimitBudget("command")
+	if !ok {
+		return 0
+	}
+	untilReset := time.Until(state.Reset)
+	if untilReset <= 0 || state.Remaining <= 0 {
+		return 0
+	}
+	return untilReset / time.Duration(state.Remaining)
+}
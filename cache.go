@@ -1,7 +1,11 @@
 package erlcgo
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"io"
+	"sort"
 	"sync"
 	"time"
 )
@@ -48,7 +52,7 @@ type CacheStats struct {
 	Hits      int64         // Number of cache hits
 	Misses    int64         // Number of cache misses
 	ItemCount int           // Current number of items in cache
-	Memory    int64         // Approximate memory usage in bytes
+	Memory    int64         // Approximate memory usage in bytes, based on the JSON size of values
 	AvgTTL    time.Duration // Average TTL of cached items
 }
 
@@ -62,11 +66,14 @@ type MemoryCache struct {
 	onEvict  func(key string, value interface{}) // Optional callback invoked when items are evicted
 	stopCh   chan struct{}                   // Used to signal the cleanup goroutine to stop
 	stopOnce sync.Once                       // Ensures Close() only closes stopCh once, preventing panic
+	maxItems int                             // Maximum number of items, 0 for unlimited
+	maxBytes int64                           // Maximum approximate size of all items, 0 for unlimited
 }
 
 type cacheItem struct {
 	value      interface{}
 	expiration time.Time
+	size       int64
 }
 
 // NewMemoryCache creates a new MemoryCache instance.
@@ -84,7 +91,15 @@ func NewMemoryCache() *MemoryCache {
 		items:  make(map[string]*cacheItem),
 		stopCh: make(chan struct{}),
 	}
-	go cache.cleanupLoop() // Start background cleanup goroutine
+	spawn(cache, "cache cleanup", cache.cleanupLoop) // Start background cleanup goroutine
+	return cache
+}
+
+// newConfiguredMemoryCache creates the MemoryCache used when a CacheConfig
+// does not provide its own implementation, applying the configured limits.
+func newConfiguredMemoryCache(config *CacheConfig) *MemoryCache {
+	cache := NewMemoryCache()
+	cache.SetLimits(config.MaxItems, config.MaxBytes)
 	return cache
 }
 
@@ -104,10 +119,7 @@ func (c *MemoryCache) Get(key string) (interface{}, bool) {
 		c.mu.Lock()
 		// Double-check expiration after acquiring write lock
 		if item, exists := c.items[key]; exists && time.Now().After(item.expiration) {
-			delete(c.items, key)
-			if c.onEvict != nil {
-				c.onEvict(key, item.value)
-			}
+			c.evictLocked(key)
 		}
 		c.mu.Unlock()
 		return nil, false
@@ -119,19 +131,155 @@ func (c *MemoryCache) Get(key string) (interface{}, bool) {
 }
 
 func (c *MemoryCache) Set(key string, value interface{}, ttl time.Duration) {
+	size := approxSize(key, value)
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	// A single value larger than the whole budget would evict everything
+	// and still not fit, so it is not cached at all.
+	if c.maxBytes > 0 && size > c.maxBytes {
+		c.removeLocked(key)
+		return
+	}
+
+	c.removeLocked(key)
 	c.items[key] = &cacheItem{
 		value:      value,
 		expiration: time.Now().Add(ttl),
+		size:       size,
 	}
+	c.stats.Memory += size
+	c.stats.ItemCount = len(c.items)
+	c.enforceLimitsLocked()
 }
 
 func (c *MemoryCache) Delete(key string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	delete(c.items, key)
+	c.removeLocked(key)
+}
+
+// GetMulti retrieves several keys under a single lock. Missing and expired
+// keys are absent from the returned map.
+func (c *MemoryCache) GetMulti(keys []string) map[string]interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	values := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		if item, ok := c.items[key]; ok && !now.After(item.expiration) {
+			values[key] = item.value
+		}
+	}
+	return values
+}
+
+// SetMulti stores several items with the same TTL under a single lock.
+func (c *MemoryCache) SetMulti(items map[string]interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiration := time.Now().Add(ttl)
+	for key, value := range items {
+		size := approxSize(key, value)
+		c.removeLocked(key)
+		if c.maxBytes > 0 && size > c.maxBytes {
+			continue
+		}
+		c.items[key] = &cacheItem{value: value, expiration: expiration, size: size}
+		c.stats.Memory += size
+	}
+	c.stats.ItemCount = len(c.items)
+	c.enforceLimitsLocked()
+}
+
+// SetLimits bounds the cache by item count and approximate size in bytes.
+// When either limit is exceeded, expired items are evicted first, followed by
+// the items closest to expiring. A limit of 0 means unlimited.
+func (c *MemoryCache) SetLimits(maxItems int, maxBytes int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxItems = maxItems
+	c.maxBytes = maxBytes
+	c.enforceLimitsLocked()
+}
+
+// removeLocked deletes key and updates size accounting. c.mu must be held.
+func (c *MemoryCache) removeLocked(key string) {
+	if item, ok := c.items[key]; ok {
+		delete(c.items, key)
+		c.stats.Memory -= item.size
+		c.stats.ItemCount = len(c.items)
+	}
+}
+
+// evictLocked removes key and reports it to the eviction callback. c.mu must be held.
+func (c *MemoryCache) evictLocked(key string) {
+	item, ok := c.items[key]
+	if !ok {
+		return
+	}
+	c.removeLocked(key)
+	if c.onEvict != nil {
+		c.onEvict(key, item.value)
+	}
+}
+
+func (c *MemoryCache) overLimitLocked() bool {
+	return (c.maxItems > 0 && len(c.items) > c.maxItems) ||
+		(c.maxBytes > 0 && c.stats.Memory > c.maxBytes)
+}
+
+// enforceLimitsLocked evicts items until the cache is within its limits. c.mu must be held.
+func (c *MemoryCache) enforceLimitsLocked() {
+	if !c.overLimitLocked() {
+		return
+	}
+
+	now := time.Now()
+	for key, item := range c.items {
+		if now.After(item.expiration) {
+			c.evictLocked(key)
+		}
+	}
+	if !c.overLimitLocked() {
+		return
+	}
+
+	keys := make([]string, 0, len(c.items))
+	for key := range c.items {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return c.items[keys[i]].expiration.Before(c.items[keys[j]].expiration)
+	})
+	for _, key := range keys {
+		if !c.overLimitLocked() {
+			return
+		}
+		c.evictLocked(key)
+	}
+}
+
+// approxSize estimates the memory used by a cache entry from the length of the
+// key and the JSON encoding of the value. It is an approximation intended for
+// enforcing budgets, not an exact measurement.
+func approxSize(key string, value interface{}) int64 {
+	size := int64(len(key))
+	switch v := value.(type) {
+	case nil:
+	case []byte:
+		size += int64(len(v))
+	case string:
+		size += int64(len(v))
+	default:
+		if data, err := json.Marshal(v); err == nil {
+			size += int64(len(data))
+		}
+	}
+	return size
 }
 
 // cleanupLoop runs in a background goroutine and periodically removes expired items.
@@ -149,11 +297,8 @@ func (c *MemoryCache) cleanupLoop() {
 			now := time.Now()
 			for key, item := range c.items {
 				if now.After(item.expiration) {
-					delete(c.items, key)
-					// Call eviction callback if set
-					if c.onEvict != nil {
-						c.onEvict(key, item.value)
-					}
+					// Remove the item and call the eviction callback if set
+					c.evictLocked(key)
 				}
 			}
 			c.mu.Unlock()
@@ -201,3 +346,99 @@ func (c *MemoryCache) Close() {
 		close(c.stopCh)
 	})
 }
+
+// CacheEntry is a snapshot of a single cached item.
+type CacheEntry struct {
+	Key       string      `json:"key"`
+	Value     interface{} `json:"value"`
+	ExpiresAt time.Time   `json:"expiresAt"`
+	// Encoding is "bytes" when Value is a []byte, such as a value encoded by
+	// CacheConfig.Serializer. Dump writes those as base64 strings and Load
+	// turns them back into []byte.
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// cacheEncodingBytes marks CacheEntry values that are []byte.
+const cacheEncodingBytes = "bytes"
+
+// Entries returns a snapshot of all unexpired items, sorted by key.
+// It is intended for inspection and debugging; values are not copied.
+func (c *MemoryCache) Entries() []CacheEntry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	entries := make([]CacheEntry, 0, len(c.items))
+	for key, item := range c.items {
+		if now.After(item.expiration) {
+			continue
+		}
+		entry := CacheEntry{Key: key, Value: item.value, ExpiresAt: item.expiration}
+		if _, ok := item.value.([]byte); ok {
+			entry.Encoding = cacheEncodingBytes
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries
+}
+
+// Dump writes all unexpired items to w as a JSON array, including their
+// expiration times. The output can be restored with Load.
+func (c *MemoryCache) Dump(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(c.Entries()); err != nil {
+		return &CacheError{Op: "dump", Err: err}
+	}
+	return nil
+}
+
+// Load reads entries written by Dump and adds them to the cache, keeping
+// their original expiration times. Entries that have already expired are
+// skipped. Existing items with the same key are overwritten.
+//
+// Example:
+//
+//	f, _ := os.Open("cache.json")
+//	defer f.Close()
+//	if err := cache.Load(f); err != nil {
+//	    log.Fatal(err)
+//	}
+func (c *MemoryCache) Load(r io.Reader) error {
+	var entries []CacheEntry
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return &CacheError{Op: "load", Err: err}
+	}
+	for i, e := range entries {
+		switch e.Encoding {
+		case "":
+		case cacheEncodingBytes:
+			s, _ := e.Value.(string)
+			data, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return &CacheError{Op: "load", Key: e.Key, Err: err}
+			}
+			entries[i].Value = data
+		default:
+			return &CacheError{Op: "load", Key: e.Key, Err: fmt.Errorf("unknown encoding %q", e.Encoding)}
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for _, e := range entries {
+		if now.After(e.ExpiresAt) {
+			continue
+		}
+		size := approxSize(e.Key, e.Value)
+		c.removeLocked(e.Key)
+		c.items[e.Key] = &cacheItem{value: e.Value, expiration: e.ExpiresAt, size: size}
+		c.stats.Memory += size
+	}
+	c.stats.ItemCount = len(c.items)
+	c.enforceLimitsLocked()
+	return nil
+}
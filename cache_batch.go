@@ -0,0 +1,173 @@
+package erlcgo
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// cacheKey returns the cache key for a GET of rawURL. Keys include the
+// server, so clients for different servers can share a cache.
+func (c *Client) cacheKey(rawURL string) string {
+	return c.cache.Prefix + serverID(c.apiKey) + ":" + rawURL
+}
+
+// countCacheHit records a response served from the cache for route.
+func (c *Client) countCacheHit(route string) {
+	c.metricsMu.Lock()
+	c.metrics.CacheHits++
+	c.metricsMu.Unlock()
+	c.bus.publish(LifecycleEvent{Type: LifecycleCacheHit, Route: route})
+}
+
+type cacheReadKey struct{}
+
+// withCacheRead marks ctx as belonging to a request whose cached response was
+// already looked up, such as with GetMulti, so doRequest does not look again.
+func withCacheRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheReadKey{}, true)
+}
+
+// isCacheRead reports whether ctx was marked with withCacheRead or
+// withCacheBatch.
+func isCacheRead(ctx context.Context) bool {
+	read, _ := ctx.Value(cacheReadKey{}).(bool)
+	return read || cacheBatchFrom(ctx) != nil
+}
+
+// cacheBatch collects the responses fetched for a batched cache lookup, so
+// they are written with one SetMulti instead of a Set each.
+type cacheBatch struct {
+	mu      sync.Mutex
+	entries []cacheBatchEntry
+}
+
+type cacheBatchEntry struct {
+	client  *Client
+	key     string
+	value   interface{}
+	started time.Time
+}
+
+type cacheBatchKey struct{}
+
+// withCacheBatch marks ctx so the responses of its GET requests are added to
+// b instead of being written to the cache, and their cache lookup is skipped
+// as the batch already made it.
+func withCacheBatch(ctx context.Context, b *cacheBatch) context.Context {
+	return context.WithValue(ctx, cacheBatchKey{}, b)
+}
+
+func cacheBatchFrom(ctx context.Context) *cacheBatch {
+	b, _ := ctx.Value(cacheBatchKey{}).(*cacheBatch)
+	return b
+}
+
+func (b *cacheBatch) add(c *Client, key string, value interface{}, started time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entries = append(b.entries, cacheBatchEntry{client: c, key: key, value: value, started: started})
+}
+
+// serverBatch reads and writes the cached /v2/server responses of several
+// clients with one GetMulti and one SetMulti per cache they share, instead
+// of a round trip per server. ClientManager.Snapshot and Poll use it.
+type serverBatch struct {
+	opts    ServerQueryOptions
+	batches map[*CacheConfig]*cacheBatch
+}
+
+// newServerBatch looks up the cached responses of clients for opts and
+// returns the hits by name. Misses are fetched with fetch and written back
+// with flush.
+func newServerBatch(ctx context.Context, clients map[string]*Client, opts ServerQueryOptions) (*serverBatch, map[string]*ERLCServerResponse) {
+	b := &serverBatch{opts: opts, batches: make(map[*CacheConfig]*cacheBatch)}
+	groups := make(map[*CacheConfig]map[string]*Client)
+	for name, c := range clients {
+		if c.cache == nil || !c.cache.Enabled || c.cache.backend() == nil {
+			continue
+		}
+		if groups[c.cache] == nil {
+			groups[c.cache] = make(map[string]*Client)
+			b.batches[c.cache] = &cacheBatch{}
+		}
+		groups[c.cache][name] = c
+	}
+
+	hits := make(map[string]*ERLCServerResponse)
+	if isFreshRead(ctx) {
+		return b, hits
+	}
+	for config, group := range groups {
+		keys := make([]string, 0, len(group))
+		names := make(map[string]string, len(group))
+		for name, c := range group {
+			key := c.cacheKey(c.baseURL + serverPath(opts))
+			if c.taint.bypass(key) {
+				continue
+			}
+			keys = append(keys, key)
+			names[key] = name
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		values, err := config.backend().GetMulti(ctx, keys)
+		if err != nil {
+			for _, c := range group {
+				c.bus.publish(LifecycleEvent{Type: LifecycleCacheError, Route: "GET /v2/server", Err: err})
+			}
+			continue
+		}
+		for key, cached := range values {
+			name, ok := names[key]
+			if !ok {
+				continue
+			}
+			c := group[name]
+			var resp ERLCServerResponse
+			if err := c.decodeCacheValue(cached, &resp); err != nil {
+				continue
+			}
+			c.countCacheHit("GET /v2/server")
+			hits[name] = &resp
+		}
+	}
+	return b, hits
+}
+
+// fetch gets the server of a client whose response was not cached, adding
+// the response to the batch.
+func (b *serverBatch) fetch(ctx context.Context, c *Client) (*ERLCServerResponse, error) {
+	if batch := b.batches[c.cache]; batch != nil {
+		ctx = withCacheBatch(ctx, batch)
+	}
+	return c.GetServer(ctx, b.opts)
+}
+
+// flush writes the fetched responses with one SetMulti per cache.
+func (b *serverBatch) flush(ctx context.Context) {
+	for config, batch := range b.batches {
+		batch.mu.Lock()
+		entries := batch.entries
+		batch.entries = nil
+		batch.mu.Unlock()
+		if len(entries) == 0 {
+			continue
+		}
+
+		items := make(map[string]interface{}, len(entries))
+		for _, e := range entries {
+			items[e.key] = e.value
+		}
+		if err := config.backend().SetMulti(ctx, items, config.TTL); err != nil {
+			for _, e := range entries {
+				e.client.bus.publish(LifecycleEvent{Type: LifecycleCacheError, Route: "GET /v2/server", Err: err})
+			}
+			continue
+		}
+		for _, e := range entries {
+			e.client.taint.markRefreshed(e.key, e.started)
+		}
+	}
+}
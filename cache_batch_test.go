@@ -0,0 +1,178 @@
+package erlcgo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingCache counts the calls made to a CacheCtx.
+type countingCache struct {
+	CacheCtx
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func newCountingCache() *countingCache {
+	return &countingCache{CacheCtx: AdaptCache(NewMemoryCache()), calls: make(map[string]int)}
+}
+
+func (c *countingCache) count(op string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls[op]++
+}
+
+func (c *countingCache) took(op string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := c.calls[op]
+	c.calls[op] = 0
+	return n
+}
+
+func (c *countingCache) Get(ctx context.Context, key string) (interface{}, bool, error) {
+	c.count("Get")
+	return c.CacheCtx.Get(ctx, key)
+}
+
+func (c *countingCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	c.count("Set")
+	return c.CacheCtx.Set(ctx, key, value, ttl)
+}
+
+func (c *countingCache) GetMulti(ctx context.Context, keys []string) (map[string]interface{}, error) {
+	c.count("GetMulti")
+	return c.CacheCtx.GetMulti(ctx, keys)
+}
+
+func (c *countingCache) SetMulti(ctx context.Context, items map[string]interface{}, ttl time.Duration) error {
+	c.count("SetMulti")
+	return c.CacheCtx.SetMulti(ctx, items, ttl)
+}
+
+// serverNamedByKey answers /v2/server with the server key as the name.
+func serverNamedByKey(hits *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ERLCServerResponse{Name: r.Header.Get("Server-Key"), CurrentPlayers: 3})
+	}))
+}
+
+func TestGetServerFindsCachedOptionVariants(t *testing.T) {
+	var hits int32
+	srv := serverNamedByKey(&hits)
+	defer srv.Close()
+	cache := newCountingCache()
+	c := NewClient("key", WithBaseURL(srv.URL), WithCache(&CacheConfig{Enabled: true, TTL: time.Minute, CacheCtx: cache}))
+	defer c.Close()
+
+	if _, err := c.GetServer(context.Background(), ServerQueryOptions{Players: true, Staff: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got := cache.took("GetMulti") + cache.took("Get"); got != 1 {
+		t.Errorf("a miss took %d cache reads, want 1", got)
+	}
+
+	resp, err := c.GetServer(context.Background(), ServerQueryOptions{Players: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&hits); n != 1 || resp.Name != "key" {
+		t.Errorf("got %q after %d requests, want the cached response with more options", resp.Name, n)
+	}
+	if got := cache.took("GetMulti"); got != 1 {
+		t.Errorf("made %d GetMulti calls, want 1", got)
+	}
+	if got := cache.took("Get"); got != 0 {
+		t.Errorf("made %d Get calls, want none", got)
+	}
+}
+
+func TestManagerSnapshotBatchesCache(t *testing.T) {
+	var hits int32
+	srv := serverNamedByKey(&hits)
+	defer srv.Close()
+	cache := newCountingCache()
+	m := NewClientManager([]ClientOption{
+		WithBaseURL(srv.URL),
+		WithCache(&CacheConfig{Enabled: true, TTL: time.Minute, CacheCtx: cache}),
+	})
+	defer m.Close()
+	for _, name := range []string{"a", "b", "c"} {
+		m.Add(name, "key-"+name)
+	}
+	opts := ServerQueryOptions{Players: true}
+
+	for round := 0; round < 2; round++ {
+		snap := m.Snapshot(context.Background(), opts)
+		if len(snap.Errors) != 0 || len(snap.Servers) != 3 {
+			t.Fatalf("round %d: %+v", round, snap)
+		}
+		for name, server := range snap.Servers {
+			if server.Name != "key-"+name {
+				t.Errorf("round %d: %s got the server of %s", round, name, server.Name)
+			}
+		}
+		if got := cache.took("GetMulti"); got != 1 {
+			t.Errorf("round %d: %d GetMulti calls, want 1", round, got)
+		}
+		if got := cache.took("Get") + cache.took("Set"); got != 0 {
+			t.Errorf("round %d: %d single-key cache calls, want none", round, got)
+		}
+		wantSets := 1 - round
+		if got := cache.took("SetMulti"); got != wantSets {
+			t.Errorf("round %d: %d SetMulti calls, want %d", round, got, wantSets)
+		}
+	}
+	if n := atomic.LoadInt32(&hits); n != 3 {
+		t.Errorf("server got %d requests, want 3 with the second snapshot cached", n)
+	}
+}
+
+func TestManagerPollBatchesCache(t *testing.T) {
+	var hits int32
+	srv := serverNamedByKey(&hits)
+	defer srv.Close()
+	cache := newCountingCache()
+	m := NewClientManager([]ClientOption{
+		WithBaseURL(srv.URL),
+		WithCache(&CacheConfig{Enabled: true, TTL: time.Minute, CacheCtx: cache}),
+	})
+	defer m.Close()
+	m.Add("a", "key-a")
+	m.Add("b", "key-b")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var mu sync.Mutex
+	seen := make(map[string]int)
+	m.Poll(ctx, PollConfig{
+		Interval: time.Millisecond,
+		Options:  ServerQueryOptions{Players: true},
+		Handler: func(name string, resp *ERLCServerResponse, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil || resp.Name != "key-"+name {
+				t.Errorf("%s: %v, %+v", name, err, resp)
+			}
+			if seen[name]++; seen["a"] >= 3 && seen["b"] >= 3 {
+				cancel()
+			}
+		},
+	})
+	if n := atomic.LoadInt32(&hits); n != 2 {
+		t.Errorf("server got %d requests, want one per server with later rounds cached", n)
+	}
+	if got := cache.took("SetMulti"); got != 1 {
+		t.Errorf("%d SetMulti calls, want 1", got)
+	}
+	if got := cache.took("Get") + cache.took("Set"); got != 0 {
+		t.Errorf("%d single-key cache calls, want none", got)
+	}
+}
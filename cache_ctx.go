@@ -0,0 +1,115 @@
+package erlcgo
+
+import (
+	"context"
+	"time"
+)
+
+// CacheCtx is a context-aware cache interface with error reporting. Remote
+// caches such as Redis should implement it so lookups respect request deadlines
+// and failures are reported instead of silently treated as misses.
+// Implementations must be safe for concurrent use.
+//
+// Legacy Cache implementations are adapted automatically; see AdaptCache.
+type CacheCtx interface {
+	// Get retrieves a value from the cache. A miss is reported as
+	// (nil, false, nil); err is only set when the lookup itself failed.
+	Get(ctx context.Context, key string) (interface{}, bool, error)
+
+	// Set stores a value in the cache with the specified TTL.
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+
+	// Delete removes an item from the cache.
+	Delete(ctx context.Context, key string) error
+
+	// GetMulti retrieves several values in one round trip. Keys that are not
+	// cached are absent from the returned map.
+	GetMulti(ctx context.Context, keys []string) (map[string]interface{}, error)
+
+	// SetMulti stores several values with the same TTL in one round trip.
+	SetMulti(ctx context.Context, items map[string]interface{}, ttl time.Duration) error
+}
+
+// multiCache is implemented by legacy caches, such as MemoryCache, that can
+// read and write several keys at once. AdaptCache uses it when available and
+// falls back to one call per key otherwise.
+type multiCache interface {
+	GetMulti(keys []string) map[string]interface{}
+	SetMulti(items map[string]interface{}, ttl time.Duration)
+}
+
+// AdaptCache wraps a legacy Cache so it satisfies CacheCtx.
+// The context is ignored and operations never return errors.
+func AdaptCache(c Cache) CacheCtx {
+	if c == nil {
+		return nil
+	}
+	return cacheAdapter{c}
+}
+
+type cacheAdapter struct {
+	cache Cache
+}
+
+func (a cacheAdapter) Get(ctx context.Context, key string) (interface{}, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+	v, ok := a.cache.Get(key)
+	return v, ok, nil
+}
+
+func (a cacheAdapter) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	a.cache.Set(key, value, ttl)
+	return nil
+}
+
+func (a cacheAdapter) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	a.cache.Delete(key)
+	return nil
+}
+
+func (a cacheAdapter) GetMulti(ctx context.Context, keys []string) (map[string]interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if mc, ok := a.cache.(multiCache); ok {
+		return mc.GetMulti(keys), nil
+	}
+	values := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		if v, ok := a.cache.Get(key); ok {
+			values[key] = v
+		}
+	}
+	return values, nil
+}
+
+func (a cacheAdapter) SetMulti(ctx context.Context, items map[string]interface{}, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if mc, ok := a.cache.(multiCache); ok {
+		mc.SetMulti(items, ttl)
+		return nil
+	}
+	for key, value := range items {
+		a.cache.Set(key, value, ttl)
+	}
+	return nil
+}
+
+// backend returns the cache implementation the client should use, preferring
+// CacheCtx over the legacy Cache field. It returns nil if neither is set.
+func (config *CacheConfig) backend() CacheCtx {
+	if config.CacheCtx != nil {
+		return config.CacheCtx
+	}
+	return AdaptCache(config.Cache)
+}
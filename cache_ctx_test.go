@@ -0,0 +1,38 @@
+package erlcgo
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// mapCache is a legacy Cache without GetMulti.
+type mapCache map[string]interface{}
+
+func (m mapCache) Get(key string) (interface{}, bool)                   { v, ok := m[key]; return v, ok }
+func (m mapCache) Set(key string, value interface{}, ttl time.Duration) { m[key] = value }
+func (m mapCache) Delete(key string)                                    { delete(m, key) }
+
+func TestAdaptCacheMulti(t *testing.T) {
+	mem := NewMemoryCache()
+	for name, cache := range map[string]Cache{"memory": mem, "legacy": mapCache{}} {
+		t.Run(name, func(t *testing.T) {
+			cache.Set("a", 1, time.Minute)
+			cache.Set("b", 2, time.Minute)
+			got, err := AdaptCache(cache).GetMulti(context.Background(), []string{"a", "b", "missing"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 2 || got["a"] != 1 || got["b"] != 2 {
+				t.Errorf("got %v, want a and b only", got)
+			}
+
+			if err := AdaptCache(cache).SetMulti(context.Background(), map[string]interface{}{"c": 3, "d": 4}, time.Minute); err != nil {
+				t.Fatal(err)
+			}
+			if v, ok := cache.Get("d"); !ok || v != 4 {
+				t.Errorf("after SetMulti, d = %v, %v", v, ok)
+			}
+		})
+	}
+}
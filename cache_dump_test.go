@@ -0,0 +1,87 @@
+package erlcgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryCacheDumpLoadSerialized(t *testing.T) {
+	want := ERLCServerResponse{Name: "Server", OwnerId: 1, CurrentPlayers: 1, MaxPlayers: 40, JoinKey: "abc"}
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(want)
+	}))
+	defer srv.Close()
+
+	for name, serializer := range map[string]Serializer{
+		"gob":     GobSerializer{},
+		"msgpack": MsgpackSerializer{},
+		"json":    JSONSerializer{},
+	} {
+		t.Run(name, func(t *testing.T) {
+			atomic.StoreInt32(&hits, 0)
+			client := func(mem *MemoryCache) *Client {
+				return NewClient("key", WithBaseURL(srv.URL), WithCache(&CacheConfig{Enabled: true, TTL: time.Minute, Cache: mem, Serializer: serializer}))
+			}
+
+			mem := NewMemoryCache()
+			c := client(mem)
+			defer c.Close()
+			if _, err := c.GetServer(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			var dump bytes.Buffer
+			if err := mem.Dump(&dump); err != nil {
+				t.Fatal(err)
+			}
+
+			restored := NewMemoryCache()
+			if err := restored.Load(&dump); err != nil {
+				t.Fatal(err)
+			}
+			for _, e := range restored.Entries() {
+				if _, ok := e.Value.([]byte); !ok || e.Encoding != "bytes" {
+					t.Errorf("restored %s as %T with encoding %q, want []byte", e.Key, e.Value, e.Encoding)
+				}
+			}
+
+			c2 := client(restored)
+			defer c2.Close()
+			got, err := c2.GetServer(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*got, want) {
+				t.Errorf("got %+v, want %+v", *got, want)
+			}
+			if n := atomic.LoadInt32(&hits); n != 1 {
+				t.Errorf("server got %d requests, want the second client served from the loaded cache", n)
+			}
+		})
+	}
+}
+
+func TestMemoryCacheLoadRejectsBadEncoding(t *testing.T) {
+	exp := time.Now().Add(time.Minute).Format(time.RFC3339)
+	for _, dump := range []string{
+		`[{"key":"k","value":"not base64!","expiresAt":"` + exp + `","encoding":"bytes"}]`,
+		`[{"key":"k","value":"x","expiresAt":"` + exp + `","encoding":"hex"}]`,
+	} {
+		mem := NewMemoryCache()
+		if err := mem.Load(bytes.NewBufferString(dump)); err == nil {
+			t.Errorf("Load(%s) succeeded", dump)
+		}
+		if n := len(mem.Entries()); n != 0 {
+			t.Errorf("Load(%s) kept %d entries after failing", dump, n)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package erlcgo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Checkpoint is the last-seen state of one event type for one server.
+// Log-based event types use Timestamp; set-based types (players, vehicles,
+// emergency calls, queue, bans, staff) use Keys.
+type Checkpoint struct {
+	Version   int      `json:"version,omitempty"`
+	Timestamp int64    `json:"timestamp,omitempty"`
+	Keys      []string `json:"keys,omitempty"`
+}
+
+// CheckpointStore persists subscription checkpoints so event pipelines can
+// resume where they left off after a restart. Keys combine a server identifier
+// and an event type. Implementations must be safe for concurrent use.
+type CheckpointStore interface {
+	// Get returns the checkpoint for key, or false if none has been stored.
+	Get(ctx context.Context, key string) (Checkpoint, bool, error)
+
+	// Set stores the checkpoint for key.
+	Set(ctx context.Context, key string, cp Checkpoint) error
+}
+
+// FileCheckpointStore is a CheckpointStore backed by a single JSON file.
+// The file is rewritten atomically on every Set.
+type FileCheckpointStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileCheckpointStore returns a store that keeps checkpoints in the file at path.
+// The file is created on the first Set.
+func NewFileCheckpointStore(path string) *FileCheckpointStore {
+	return &FileCheckpointStore{path: path}
+}
+
+func (s *FileCheckpointStore) Get(ctx context.Context, key string) (Checkpoint, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all, err := s.read()
+	if err != nil {
+		return Checkpoint{}, false, err
+	}
+	cp, ok := all[key]
+	return cp, ok, nil
+}
+
+func (s *FileCheckpointStore) Set(ctx context.Context, key string, cp Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all, err := s.read()
+	if err != nil {
+		return err
+	}
+	all[key] = cp
+
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func (s *FileCheckpointStore) read() (map[string]Checkpoint, error) {
+	all := make(map[string]Checkpoint)
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return all, nil
+	}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// checkpointKey builds the store key for a server and event type. The server
+// key is hashed so secrets are never written to the store.
+func checkpointKey(serverKey string, eventType EventType) string {
+	return serverID(serverKey) + ":" + string(eventType)
+}
+
+// serverID derives a short, non-secret identifier from a server key.
+func serverID(serverKey string) string {
+	sum := sha256.Sum256([]byte(serverKey))
+	return hex.EncodeToString(sum[:8])
+}
+
+// checkpoint captures the state of one event type.
+func (s *lastState) checkpoint(eventType EventType) Checkpoint {
+	switch eventType {
+	case EventTypePlayers:
+		return Checkpoint{Keys: sortedKeys(s.players)}
+	case EventTypeVehicles:
+		return Checkpoint{Keys: sortedKeys(s.vehicleSet)}
+	case EventTypeEmergencyCalls:
+		keys := make([]string, 0, len(s.emergencyCallNumbers))
+		for n := range s.emergencyCallNumbers {
+			keys = append(keys, strconv.Itoa(n))
+		}
+		sort.Strings(keys)
+		return Checkpoint{Keys: keys}
+	case EventTypeBans:
+		return Checkpoint{Keys: sortedKeys(s.bans)}
+	case EventTypeStaff:
+		return Checkpoint{Keys: sortedKeys(s.staff)}
+	case EventTypeQueue:
+		// Queue order is meaningful, so keys are kept in queue order.
+		keys := make([]string, 0, len(s.queue))
+		for _, id := range s.queue {
+			keys = append(keys, strconv.FormatInt(id, 10))
+		}
+		return Checkpoint{Keys: keys}
+	case EventTypeCommands:
+		return Checkpoint{Timestamp: s.commandTime}
+	case EventTypeModCalls:
+		return Checkpoint{Timestamp: s.modCallTime}
+	case EventTypeKills:
+		return Checkpoint{Timestamp: s.killTime}
+	case EventTypeJoins:
+		return Checkpoint{Timestamp: s.joinTime}
+	}
+	return Checkpoint{}
+}
+
+// restore replaces the state of one event type with a stored checkpoint.
+func (s *lastState) restore(eventType EventType, cp Checkpoint) {
+	switch eventType {
+	case EventTypePlayers:
+		s.players = make(playerSet, len(cp.Keys))
+		for _, k := range cp.Keys {
+			s.players[k] = struct{}{}
+		}
+	case EventTypeVehicles:
+		s.vehicleSet = make(map[string]struct{}, len(cp.Keys))
+		for _, k := range cp.Keys {
+			s.vehicleSet[k] = struct{}{}
+		}
+	case EventTypeEmergencyCalls:
+		s.emergencyCallNumbers = make(map[int]struct{}, len(cp.Keys))
+		for _, k := range cp.Keys {
+			if n, err := strconv.Atoi(k); err == nil {
+				s.emergencyCallNumbers[n] = struct{}{}
+			}
+		}
+	case EventTypeStaff:
+		s.staff = make(playerSet, len(cp.Keys))
+		for _, k := range cp.Keys {
+			s.staff[k] = struct{}{}
+		}
+	case EventTypeBans:
+		s.bans = make(ERLCBans, len(cp.Keys))
+		for _, k := range cp.Keys {
+			s.bans[k] = ""
+		}
+	case EventTypeQueue:
+		s.queue = make([]int64, 0, len(cp.Keys))
+		for _, k := range cp.Keys {
+			if id, err := strconv.ParseInt(k, 10, 64); err == nil {
+				s.queue = append(s.queue, id)
+			}
+		}
+	case EventTypeCommands:
+		s.commandTime = NormalizeTimestamp(cp.Timestamp)
+	case EventTypeModCalls:
+		s.modCallTime = NormalizeTimestamp(cp.Timestamp)
+	case EventTypeKills:
+		s.killTime = NormalizeTimestamp(cp.Timestamp)
+	case EventTypeJoins:
+		s.joinTime = NormalizeTimestamp(cp.Timestamp)
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// checkpointer loads and saves subscription state, skipping writes when a
+// checkpoint has not changed since it was last saved.
+type checkpointer struct {
+	store     CheckpointStore
+	serverKey string
+	types     []EventType
+	saved     map[EventType]string
+	onError   func(error)
+}
+
+// load restores state from stored checkpoints. Store errors are reported and
+// skipped, but a checkpoint with an incompatible schema is returned as an
+// error: saving over it would destroy state written by another release.
+func (cp *checkpointer) load(ctx context.Context, state *lastState) error {
+	for _, t := range cp.types {
+		stored, ok, err := cp.store.Get(ctx, checkpointKey(cp.serverKey, t))
+		if err != nil {
+			cp.report(err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		stored, err = MigrateCheckpoint(stored)
+		if err != nil {
+			return err
+		}
+		state.restore(t, stored)
+	}
+	return nil
+}
+
+func (cp *checkpointer) save(ctx context.Context, state *lastState) {
+	for _, t := range cp.types {
+		current := state.checkpoint(t)
+		current.Version = SchemaVersion
+		encoded, _ := json.Marshal(current)
+		if cp.saved[t] == string(encoded) {
+			continue
+		}
+		if err := cp.store.Set(ctx, checkpointKey(cp.serverKey, t), current); err != nil {
+			cp.report(err)
+			continue
+		}
+		cp.saved[t] = string(encoded)
+	}
+}
+
+func (cp *checkpointer) report(err error) {
+	if cp.onError != nil {
+		cp.onError(err)
+	}
+}
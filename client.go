@@ -18,6 +18,7 @@
 package erlcgo
 
 import (
+	"context"
 	"net/http"
 	"sync"
 	"time"
@@ -39,6 +40,43 @@ type Client struct {
 	metrics      *ClientMetrics
 	metricsMu    sync.RWMutex
 	requestGroup group
+
+	deadLetterHandler DeadLetterHandler
+	bus               eventBus
+	journal           Journal
+	taint             cacheTaint
+	validateCommands  bool
+	eventDefaults     *EventConfig
+	deprecationHook   DeprecationHook
+	deprecations      deprecations
+	maxResponseBytes  int64
+	emptyBodyPolicy   EmptyBodyPolicy
+	transportRetries  int
+	decodePool        *DecodePool
+	commandPolicy     *CommandPolicy
+	timeLocation      *time.Location
+	routeTimeouts     map[string]time.Duration
+	tenant            string
+	offline           *offlineCommands
+	commandPriority   Priority
+	dryRun            *dryRunLog
+	confirmation      *CommandConfirmation
+	confirmed         confirmedLogs
+	checkTargets      bool
+	moderationAudit   ModerationAuditHook
+	random            *lockedRand
+	commandMiddleware []CommandMiddleware
+	checkRestricted   bool
+	restrictedLearned sync.Map
+
+	defaultRequestTimeout time.Duration
+	noDeadline            noDeadlineWarnings
+
+	// lifetime is canceled by Close, aborting queued and in-flight requests.
+	lifetime      context.Context
+	closeLifetime context.CancelFunc
+	subsMu        sync.Mutex
+	subs          map[*Subscription]struct{}
 }
 
 // ClientOption allows customizing the client's behavior.
@@ -72,16 +110,23 @@ func NewClient(apiKey string, opts ...ClientOption) *Client {
 		rateLimiter: NewRateLimiter(),
 		cache:       defaultCache,
 		metrics:     &ClientMetrics{},
+		subs:        make(map[*Subscription]struct{}),
+		dryRun:      &dryRunLog{},
+
+		transportRetries: defaultTransportRetries,
+		commandPriority:  PriorityHigh,
 	}
 
+	c.lifetime, c.closeLifetime = context.WithCancel(context.Background())
+
 	// Apply custom options
 	for _, opt := range opts {
 		opt(c)
 	}
 
 	// Initialize cache if enabled
-	if c.cache != nil && c.cache.Enabled && c.cache.Cache == nil {
-		c.cache.Cache = NewMemoryCache()
+	if c.cache != nil && c.cache.Enabled && c.cache.backend() == nil {
+		c.cache.Cache = newConfiguredMemoryCache(c.cache)
 	}
 
 	return c
@@ -188,13 +233,26 @@ func WithRequestQueue(workers int, interval time.Duration) ClientOption {
 	}
 }
 
+// WithDeadLetterHandler registers a handler for queued requests that failed
+// after the queue gave up on them. Only failures that retrying could fix,
+// such as rate limits, server errors and dropped connections, are
+// dead-lettered, and only once the queue has retried them; see
+// RequestQueue.SetRateLimitRetries and SetFailureRetries. Rejections such as
+// a 4xx or an exhausted tenant quota are only returned to the caller. The
+// handler receives enough context to replay the request or raise an alert.
+func WithDeadLetterHandler(h DeadLetterHandler) ClientOption {
+	return func(c *Client) {
+		c.deadLetterHandler = h
+	}
+}
+
 // Close stops background goroutines and releases resources associated with the client.
 // This includes closing the cache cleanup goroutine if caching is enabled, and stopping
 // the request queue if one was configured.
 //
-// It is safe to call Close() multiple times; subsequent calls are no-ops.
-// Once Close() is called, the client should not be used further, though this is
-// not enforced.
+// Close also stops the client's subscriptions and cancels its queued and
+// in-flight requests. It is safe to call Close() multiple times; subsequent
+// calls are no-ops. Requests made after Close() fail with ErrClientClosed.
 //
 // It is recommended to call Close() when the client is no longer needed, especially
 // in long-running applications, to prevent goroutine leaks.
@@ -204,6 +262,17 @@ func WithRequestQueue(workers int, interval time.Duration) ClientOption {
 //	client := NewClient("your-api-key")
 //	defer client.Close() // Clean up when done
 func (c *Client) Close() {
+	if c.closeLifetime != nil {
+		c.closeLifetime()
+	}
+	c.subsMu.Lock()
+	subs := c.subs
+	c.subs = make(map[*Subscription]struct{})
+	c.subsMu.Unlock()
+	for sub := range subs {
+		sub.Close()
+	}
+
 	if c.cache != nil && c.cache.Cache != nil {
 		// Close the cache if it's a MemoryCache instance
 		if mc, ok := c.cache.Cache.(*MemoryCache); ok {
@@ -214,6 +283,7 @@ func (c *Client) Close() {
 		// Stop the request queue only if it was created by this client
 		c.queue.Stop()
 	}
+	c.bus.close()
 }
 
 // Metrics returns a copy of the current client metrics.
@@ -223,4 +293,16 @@ func (c *Client) Metrics() ClientMetrics {
 	return *c.metrics
 }
 
+func (c *Client) trackSubscription(sub *Subscription) {
+	c.subsMu.Lock()
+	defer c.subsMu.Unlock()
+	if c.subs != nil {
+		c.subs[sub] = struct{}{}
+	}
+}
 
+func (c *Client) untrackSubscription(sub *Subscription) {
+	c.subsMu.Lock()
+	defer c.subsMu.Unlock()
+	delete(c.subs, sub)
+}
@@ -0,0 +1,63 @@
+package erlcgo
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrEdgeOutage is matched via errors.Is by APIErrors that came from
+// Cloudflare in front of PRC rather than from the API itself: 52x origin
+// errors and challenge pages. It lets callers alert on PRC edge outages
+// separately from API errors. Such errors also match ErrUpstream.
+var ErrEdgeOutage = errors.New("erlc: PRC edge unavailable")
+
+// Retry hints used when Cloudflare does not send a Retry-After.
+const (
+	edgeRetryAfter      = 5 * time.Second
+	challengeRetryAfter = 30 * time.Second
+)
+
+// isCloudflareStatus reports whether status is one of Cloudflare's own
+// origin error codes (520-526).
+func isCloudflareStatus(status int) bool {
+	return status >= 520 && status <= 526
+}
+
+// isCloudflareChallenge reports whether a response is a Cloudflare challenge
+// page, which PRC's API clients cannot solve.
+func isCloudflareChallenge(status int, h http.Header, body []byte) bool {
+	if h.Get("Cf-Mitigated") == "challenge" {
+		return true
+	}
+	if status != http.StatusForbidden && status != http.StatusServiceUnavailable {
+		return false
+	}
+	if !strings.EqualFold(h.Get("Server"), "cloudflare") {
+		return false
+	}
+	return bytes.Contains(body, []byte("challenge-platform")) || bytes.Contains(body, []byte("cf-chl")) || bytes.Contains(body, []byte("Just a moment..."))
+}
+
+// IsEdgeError reports whether the error came from Cloudflare rather than
+// from PRC.
+func (e *APIError) IsEdgeError() bool {
+	return isCloudflareStatus(e.StatusCode) || isCloudflareChallenge(e.StatusCode, e.Headers, e.Body)
+}
+
+// edgeRetryHint returns how long to wait before retrying an edge error, or
+// nil if the error is not one.
+func edgeRetryHint(e *APIError) *time.Duration {
+	var d time.Duration
+	switch {
+	case isCloudflareStatus(e.StatusCode):
+		d = edgeRetryAfter
+	case isCloudflareChallenge(e.StatusCode, e.Headers, e.Body):
+		d = challengeRetryAfter
+	default:
+		return nil
+	}
+	return &d
+}
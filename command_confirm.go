@@ -0,0 +1,142 @@
+package erlcgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnconfirmed is returned when a command the API accepted did not appear
+// in the server's command logs in time. PRC occasionally accepts commands
+// that never reach the game server; the command may also still arrive late.
+var ErrUnconfirmed = errors.New("erlc: command not confirmed in command logs")
+
+// confirmSkew allows for clock skew between this host and PRC when matching
+// log timestamps against the time a command was sent.
+const confirmSkew = 5 * time.Second
+
+// CommandConfirmation configures WithCommandConfirmation.
+type CommandConfirmation struct {
+	// Timeout is how long to wait for the command to appear in the command
+	// logs. Defaults to 15 seconds.
+	Timeout time.Duration
+
+	// PollInterval is the time between command log reads. Defaults to two
+	// seconds.
+	PollInterval time.Duration
+}
+
+// WithCommandConfirmation makes ExecuteCommand wait until each accepted
+// command shows up in the server's command logs, returning ErrUnconfirmed if
+// it does not within the timeout. Each check reads the command logs, so this
+// costs requests against the read rate limit.
+//
+// Example:
+//
+//	client := NewClient("your-server-key",
+//	    WithCommandConfirmation(CommandConfirmation{Timeout: 10 * time.Second}),
+//	)
+//	if err := client.ExecuteCommand(ctx, ":kick Player1 Exploiting"); errors.Is(err, erlcgo.ErrUnconfirmed) {
+//	    log.Println("kick was accepted but never reached the server")
+//	}
+func WithCommandConfirmation(config CommandConfirmation) ClientOption {
+	return func(c *Client) {
+		c.confirmation = &config
+	}
+}
+
+// ConfirmCommand waits for command, sent at sentAt, to appear in the
+// server's command logs and returns the matching entry. It returns an error
+// wrapping ErrUnconfirmed if the entry does not appear within the configured
+// timeout, or the defaults if WithCommandConfirmation was not used. A log
+// entry confirms at most one command, so repeated identical commands each
+// need their own entry.
+//
+// Example:
+//
+//	sent := time.Now()
+//	if err := client.ExecuteCommand(ctx, ":h Restart in 5 minutes"); err != nil {
+//	    return err
+//	}
+//	entry, err := client.ConfirmCommand(ctx, ":h Restart in 5 minutes", sent)
+func (c *Client) ConfirmCommand(ctx context.Context, command string, sentAt time.Time) (ERLCCommandLog, error) {
+	config := CommandConfirmation{}
+	if c.confirmation != nil {
+		config = *c.confirmation
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 15 * time.Second
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = 2 * time.Second
+	}
+
+	want := normalizeCommand(command)
+	since := sentAt.Add(-confirmSkew).Unix()
+	deadline := time.NewTimer(config.Timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(config.PollInterval)
+	defer ticker.Stop()
+	// A cached command log would hide the entry until the cache expires.
+	fresh := withFreshRead(ctx)
+	for {
+		resp, err := c.GetServer(fresh, ServerQueryOptions{CommandLogs: true})
+		if err == nil {
+			for _, l := range resp.CommandLogs {
+				if logTimestamp(l) >= since && normalizeCommand(l.Command) == want && c.confirmed.claim(l.ID(), config.Timeout+confirmSkew) {
+					return l, nil
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ERLCCommandLog{}, ctx.Err()
+		case <-deadline.C:
+			return ERLCCommandLog{}, fmt.Errorf("%w: %q not seen within %s", ErrUnconfirmed, command, config.Timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// normalizeCommand makes commands comparable with their command log entries,
+// which may differ in spacing and in the case of the verb.
+func normalizeCommand(command string) string {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return ""
+	}
+	fields[0] = strings.ToLower(fields[0])
+	return strings.Join(fields, " ")
+}
+
+// confirmedLogs remembers the command log entries that already confirmed a
+// command, so two identical commands are not confirmed by one entry.
+type confirmedLogs struct {
+	mu      sync.Mutex
+	claimed map[string]time.Time
+}
+
+// claim marks the entry with id as used until ttl from now, reporting false
+// if it was already claimed.
+func (cl *confirmedLogs) claim(id string, ttl time.Duration) bool {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	now := time.Now()
+	for k, until := range cl.claimed {
+		if now.After(until) {
+			delete(cl.claimed, k)
+		}
+	}
+	if _, ok := cl.claimed[id]; ok {
+		return false
+	}
+	if cl.claimed == nil {
+		cl.claimed = make(map[string]time.Time)
+	}
+	cl.claimed[id] = now.Add(ttl)
+	return true
+}
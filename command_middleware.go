@@ -0,0 +1,103 @@
+package erlcgo
+
+import (
+	"context"
+	"strings"
+)
+
+// CommandHandler sends a command. It is the next step passed to a
+// CommandMiddleware.
+type CommandHandler func(ctx context.Context, command string) error
+
+// CommandMiddleware wraps the sending of every command. It can change the
+// command before passing it to next, refuse it by returning an error without
+// calling next, tag it with WithCommandTags, or observe the result.
+type CommandMiddleware func(next CommandHandler) CommandHandler
+
+// WithCommandMiddleware adds middleware that sees every command sent by
+// ExecuteCommand and the methods built on it, before policy, validation, dry
+// run and journaling. Middleware runs in the order registered, the first
+// being outermost.
+//
+// Example:
+//
+//	client := NewClient("your-server-key",
+//	    WithCommandMiddleware(
+//	        BlockVerbs("unadmin", "shutdown"),
+//	        func(next CommandHandler) CommandHandler {
+//	            return func(ctx context.Context, cmd string) error {
+//	                err := next(WithCommandTags(ctx, "feature:autowarn"), cmd)
+//	                log.Printf("command %q: %v", cmd, err)
+//	                return err
+//	            }
+//	        },
+//	    ),
+//	)
+func WithCommandMiddleware(mw ...CommandMiddleware) ClientOption {
+	return func(c *Client) {
+		c.commandMiddleware = append(c.commandMiddleware, mw...)
+	}
+}
+
+// BlockVerbs returns middleware that refuses commands with any of the given
+// verbs, written with or without the colon, with a CommandValidationError.
+func BlockVerbs(verbs ...string) CommandMiddleware {
+	return func(next CommandHandler) CommandHandler {
+		return func(ctx context.Context, command string) error {
+			if err := blockedVerb(command, verbs); err != nil {
+				return err
+			}
+			return next(ctx, command)
+		}
+	}
+}
+
+// blockedVerb returns a CommandValidationError if command's verb is one of
+// blocked.
+func blockedVerb(command string, blocked []string) error {
+	verb := commandVerb(command)
+	if verb == "" {
+		return nil
+	}
+	for _, b := range blocked {
+		if strings.EqualFold(strings.TrimLeft(b, ":/"), verb) {
+			return &CommandValidationError{Command: command, Issues: []Issue{{
+				Severity: IssueError,
+				Code:     "blocked_verb",
+				Message:  "command :" + verb + " is blocked for this server",
+			}}}
+		}
+	}
+	return nil
+}
+
+// commandChain wraps send in the client's middleware.
+func (c *Client) commandChain(send CommandHandler) CommandHandler {
+	for i := len(c.commandMiddleware) - 1; i >= 0; i-- {
+		send = c.commandMiddleware[i](send)
+	}
+	return send
+}
+
+type commandTagsKey struct{}
+
+// WithCommandTags returns a context whose commands carry tags, such as the
+// feature that sent them. Tags add to any already on ctx and are recorded in
+// journal and dry-run entries for auditing.
+//
+// Example:
+//
+//	ctx = erlcgo.WithCommandTags(ctx, "feature:welcome")
+//	err := client.Execute(ctx, commands.PM(player, "Welcome!"))
+func WithCommandTags(ctx context.Context, tags ...string) context.Context {
+	existing := CommandTagsFrom(ctx)
+	all := make([]string, 0, len(existing)+len(tags))
+	all = append(append(all, existing...), tags...)
+	return context.WithValue(ctx, commandTagsKey{}, all)
+}
+
+// CommandTagsFrom returns the tags set on ctx with WithCommandTags.
+func CommandTagsFrom(ctx context.Context) []string {
+	tags, _ := ctx.Value(commandTagsKey{}).([]string)
+	return tags
+}
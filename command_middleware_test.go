@@ -0,0 +1,82 @@
+package erlcgo
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCommandMiddlewareChain(t *testing.T) {
+	rec := &commandRecorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	var calls []string
+	trace := func(name string) CommandMiddleware {
+		return func(next CommandHandler) CommandHandler {
+			return func(ctx context.Context, cmd string) error {
+				calls = append(calls, name+" before")
+				err := next(ctx, cmd)
+				calls = append(calls, name+" after")
+				return err
+			}
+		}
+	}
+	upper := func(next CommandHandler) CommandHandler {
+		return func(ctx context.Context, cmd string) error {
+			return next(ctx, strings.ToUpper(cmd))
+		}
+	}
+	c := NewClient("key", WithBaseURL(srv.URL),
+		WithCommandMiddleware(trace("outer"), BlockVerbs(":shutdown"), upper),
+		WithCommandMiddleware(trace("inner")))
+	defer c.Close()
+	ctx := context.Background()
+
+	if err := c.ExecuteCommand(ctx, ":h hello"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"outer before", "inner before", "inner after", "outer after"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls %v, want %v", calls, want)
+	}
+
+	calls = nil
+	err := c.ExecuteCommand(ctx, "/Shutdown now")
+	var verr *CommandValidationError
+	if !errors.As(err, &verr) || verr.Issues[0].Code != "blocked_verb" {
+		t.Fatalf("got %v, want a blocked_verb CommandValidationError", err)
+	}
+	if !reflect.DeepEqual(calls, []string{"outer before", "outer after"}) {
+		t.Errorf("calls %v, want the chain to stop at BlockVerbs", calls)
+	}
+
+	// Only the rewritten, unblocked command reached the server.
+	if got := rec.received(); !reflect.DeepEqual(got, []string{":H HELLO"}) {
+		t.Errorf("server received %q", got)
+	}
+}
+
+func TestCommandTagsReachDryRun(t *testing.T) {
+	var entries []DryRunEntry
+	tag := func(next CommandHandler) CommandHandler {
+		return func(ctx context.Context, cmd string) error {
+			return next(WithCommandTags(ctx, "middleware"), cmd)
+		}
+	}
+	c := NewClient("key", WithBaseURL("http://127.0.0.1:1"),
+		WithCommandMiddleware(tag),
+		WithDryRun(func(e DryRunEntry) { entries = append(entries, e) }))
+	defer c.Close()
+
+	ctx := WithCommandTags(context.Background(), "feature:welcome")
+	if err := c.ExecuteCommand(ctx, ":h hi"); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || !reflect.DeepEqual(entries[0].Tags, []string{"feature:welcome", "middleware"}) {
+		t.Errorf("dry run entries %+v, want the caller's and the middleware's tags", entries)
+	}
+}
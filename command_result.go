@@ -0,0 +1,52 @@
+package erlcgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// CommandResult is the response PRC returns for an executed command.
+type CommandResult struct {
+	// CommandID identifies the command, when PRC returns one.
+	CommandID string `json:"commandId,omitempty"`
+	// Message is PRC's confirmation message, if any.
+	Message string `json:"message,omitempty"`
+	// Raw is the undecoded response body, for fields not covered above.
+	Raw json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON keeps the raw body and decodes the known fields. Bodies that
+// are valid JSON but not an object are kept in Raw only.
+func (r *CommandResult) UnmarshalJSON(data []byte) error {
+	type plain CommandResult
+	var p plain
+	_ = json.Unmarshal(data, &p)
+	*r = CommandResult(p)
+	r.Raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// ExecuteCommandWithResult executes a command like ExecuteCommand and returns
+// PRC's response. An empty response yields an empty result. If the response
+// cannot be decoded the command was still delivered; the error then has
+// StageDecode.
+//
+// Example:
+//
+//	result, err := client.ExecuteCommandWithResult(ctx, ":h Restarting soon")
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Println("command id:", result.CommandID)
+func (c *Client) ExecuteCommandWithResult(ctx context.Context, command string) (*CommandResult, error) {
+	var result CommandResult
+	err := c.executeCommand(ctx, command, &result)
+	if errors.Is(err, ErrEmptyBody) {
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
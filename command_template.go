@@ -0,0 +1,157 @@
+package erlcgo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"unicode"
+
+	"github.com/bmrgcorp/erlcgo/commands"
+)
+
+// CommandTemplate is a reusable command with placeholders, written in
+// text/template syntax. Substituted values are escaped according to where
+// they appear: values in argument positions of a known command, such as the
+// player of :pm, must be single words, while values in the message are
+// stripped of newlines and invisible characters so they cannot inject a
+// second command.
+//
+// Example:
+//
+//	warn := erlcgo.CommandTemplate{":pm {{.Player}} {{.Message}}"}
+//	err := client.ExecuteTemplate(ctx, warn, map[string]string{
+//	    "Player":  "Player1",
+//	    "Message": "Please stop ramming other players.",
+//	})
+type CommandTemplate struct {
+	Text string
+}
+
+// Render fills in the template with data and validates the result, returning
+// a CommandValidationError if it has error-severity issues.
+func (t CommandTemplate) Render(data interface{}) (string, error) {
+	tmpl, err := template.New("command").Option("missingkey=error").Funcs(template.FuncMap{
+		"erlcWord": escapeCommandWord,
+		"erlcText": escapeCommandText,
+	}).Parse(t.Text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse command template: %w", err)
+	}
+	escapeTemplate(tmpl.Tree)
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render command template: %w", err)
+	}
+	command := b.String()
+
+	var issues []Issue
+	for _, issue := range ValidateCommand(command) {
+		if issue.Severity == IssueError {
+			issues = append(issues, issue)
+		}
+	}
+	if len(issues) > 0 {
+		return "", &CommandValidationError{Command: command, Issues: issues}
+	}
+	return command, nil
+}
+
+// ExecuteTemplate renders t with data and executes the result like
+// ExecuteCommand.
+func (c *Client) ExecuteTemplate(ctx context.Context, t CommandTemplate, data interface{}) error {
+	command, err := t.Render(data)
+	if err != nil {
+		return err
+	}
+	return c.ExecuteCommand(ctx, command)
+}
+
+// escapeTemplate appends an escaper to every action in tree. Top-level
+// actions in the argument positions of a known command get erlcWord; all
+// others, including those nested in if or range blocks, get erlcText.
+func escapeTemplate(tree *parse.Tree) {
+	var spec commandSpec
+	if len(tree.Root.Nodes) > 0 {
+		if text, ok := tree.Root.Nodes[0].(*parse.TextNode); ok {
+			// The verb only counts if it is followed by whitespace, not a placeholder.
+			src := strings.TrimLeftFunc(string(text.Text), unicode.IsSpace)
+			if i := strings.IndexFunc(src, unicode.IsSpace); i > 0 {
+				spec = knownCommands[strings.ToLower(strings.TrimLeft(src[:i], ":/"))]
+			}
+		}
+	}
+
+	token, inWord := -1, false
+	for _, node := range tree.Root.Nodes {
+		switch n := node.(type) {
+		case *parse.TextNode:
+			for _, r := range string(n.Text) {
+				if unicode.IsSpace(r) {
+					inWord = false
+				} else if !inWord {
+					token++
+					inWord = true
+				}
+			}
+		case *parse.ActionNode:
+			if !inWord {
+				token++
+				inWord = true
+			}
+			escaper := "erlcText"
+			if token >= 1 && token <= spec.args {
+				escaper = "erlcWord"
+			}
+			appendEscaper(tree, n.Pipe, escaper)
+		default:
+			// Blocks make token positions unknowable; treat their output as text.
+			token, inWord = spec.args+1, true
+			escapeNested(tree, node)
+		}
+	}
+}
+
+func escapeNested(tree *parse.Tree, node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ActionNode:
+		appendEscaper(tree, n.Pipe, "erlcText")
+	case *parse.ListNode:
+		if n != nil {
+			for _, child := range n.Nodes {
+				escapeNested(tree, child)
+			}
+		}
+	case *parse.IfNode:
+		escapeNested(tree, n.List)
+		escapeNested(tree, n.ElseList)
+	case *parse.RangeNode:
+		escapeNested(tree, n.List)
+		escapeNested(tree, n.ElseList)
+	case *parse.WithNode:
+		escapeNested(tree, n.List)
+		escapeNested(tree, n.ElseList)
+	}
+}
+
+func appendEscaper(tree *parse.Tree, pipe *parse.PipeNode, name string) {
+	// Actions that declare variables produce no output.
+	if pipe == nil || len(pipe.Decl) > 0 {
+		return
+	}
+	ident := parse.NewIdentifier(name).SetTree(tree).SetPos(pipe.Position())
+	pipe.Cmds = append(pipe.Cmds, &parse.CommandNode{NodeType: parse.NodeCommand, Pos: pipe.Position(), Args: []parse.Node{ident}})
+}
+
+// escapeCommandText renders args as message text with commands.SanitizeText.
+func escapeCommandText(args ...interface{}) string {
+	return commands.SanitizeText(fmt.Sprint(args...))
+}
+
+// escapeCommandWord renders args as a single command argument, such as a
+// player name, with commands.SanitizePlayerName.
+func escapeCommandWord(args ...interface{}) (string, error) {
+	return commands.SanitizePlayerName(fmt.Sprint(args...))
+}
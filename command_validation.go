@@ -0,0 +1,224 @@
+package erlcgo
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/bmrgcorp/erlcgo/commands"
+)
+
+// maxCommandLength is the longest command string accepted by ValidateCommand.
+const maxCommandLength = 256
+
+// maxMessageLength is the longest message text, in characters, accepted by
+// ValidateCommand for commands such as :h, :m and :pm.
+const maxMessageLength = 200
+
+// commandSpec describes the arguments an in-game command takes.
+type commandSpec struct {
+	// args is how many single-word arguments, such as player names, are required.
+	args int
+	// text reports whether the command takes a trailing message, and
+	// textRequired whether it must be present.
+	text, textRequired bool
+	// usage is shown when arguments are missing or unexpected.
+	usage string
+}
+
+// knownCommands lists the in-game commands accepted by ER:LC servers.
+var knownCommands = map[string]commandSpec{
+	"h":       {text: true, textRequired: true, usage: ":h <message>"},
+	"hint":    {text: true, textRequired: true, usage: ":hint <message>"},
+	"m":       {text: true, textRequired: true, usage: ":m <message>"},
+	"message": {text: true, textRequired: true, usage: ":message <message>"},
+	"pm":      {args: 1, text: true, textRequired: true, usage: ":pm <player> <message>"},
+
+	"kick":  {args: 1, text: true, usage: ":kick <player> [reason]"},
+	"ban":   {args: 1, usage: ":ban <player>"},
+	"unban": {args: 1, usage: ":unban <player>"},
+	"pban":  {args: 1, usage: ":pban <player>"},
+
+	"tp":    {args: 2, usage: ":tp <player> <target>"},
+	"bring": {args: 1, usage: ":bring <player>"},
+	"to":    {args: 1, usage: ":to <player>"},
+
+	"kill":     {args: 1, usage: ":kill <player>"},
+	"heal":     {args: 1, usage: ":heal <player>"},
+	"respawn":  {args: 1, usage: ":respawn <player>"},
+	"refresh":  {args: 1, usage: ":refresh <player>"},
+	"load":     {args: 1, usage: ":load <player>"},
+	"wanted":   {args: 1, usage: ":wanted <player>"},
+	"unwanted": {args: 1, usage: ":unwanted <player>"},
+	"jail":     {args: 1, usage: ":jail <player>"},
+	"unjail":   {args: 1, usage: ":unjail <player>"},
+	"view":     {args: 1, usage: ":view <player>"},
+
+	"mod":      {args: 1, usage: ":mod <player>"},
+	"unmod":    {args: 1, usage: ":unmod <player>"},
+	"admin":    {args: 1, usage: ":admin <player>"},
+	"unadmin":  {args: 1, usage: ":unadmin <player>"},
+	"helper":   {args: 1, usage: ":helper <player>"},
+	"unhelper": {args: 1, usage: ":unhelper <player>"},
+
+	"weather":    {args: 1, usage: ":weather <type>"},
+	"time":       {args: 1, usage: ":time <hour>"},
+	"startfire":  {args: 1, usage: ":startfire <type>"},
+	"stopfire":   {usage: ":stopfire"},
+	"prty":       {args: 1, usage: ":prty <seconds>"},
+	"priority":   {args: 1, usage: ":priority <seconds>"},
+	"pt":         {args: 1, usage: ":pt <seconds|on|off>"},
+	"peacetimer": {args: 1, usage: ":peacetimer <seconds|on|off>"},
+
+	"log":      {text: true, usage: ":log [message]"},
+	"logs":     {usage: ":logs"},
+	"cmds":     {usage: ":cmds"},
+	"commands": {usage: ":commands"},
+	"mods":     {usage: ":mods"},
+	"admins":   {usage: ":admins"},
+	"helpers":  {usage: ":helpers"},
+	"bans":     {usage: ":bans"},
+	"shutdown": {usage: ":shutdown"},
+	"lock":     {usage: ":lock"},
+	"unlock":   {usage: ":unlock"},
+}
+
+// IssueSeverity describes how serious a command Issue is.
+type IssueSeverity string
+
+const (
+	// IssueError means the command will almost certainly be rejected or misbehave.
+	IssueError IssueSeverity = "error"
+	// IssueWarning means the command is unusual but may be intentional.
+	IssueWarning IssueSeverity = "warning"
+)
+
+// Issue is a problem found in a command string by ValidateCommand.
+type Issue struct {
+	Severity IssueSeverity
+	Code     string // Stable identifier, e.g. "missing_prefix"
+	Message  string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s", i.Severity, i.Message)
+}
+
+// ValidateCommand checks a command string before it is sent, reporting a
+// missing leading colon, unknown and restricted verbs, missing arguments,
+// overlong messages, excessive length, invisible or control characters, and
+// embedded newlines that could inject a second command.
+// It returns nil if no issues were found.
+//
+// Example:
+//
+//	for _, issue := range erlcgo.ValidateCommand(":pm NoahCxrest Hello") {
+//	    fmt.Println(issue)
+//	}
+func ValidateCommand(cmd string) []Issue {
+	var issues []Issue
+	add := func(sev IssueSeverity, code, format string, args ...interface{}) {
+		issues = append(issues, Issue{Severity: sev, Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	trimmed := strings.TrimSpace(cmd)
+	if trimmed == "" {
+		add(IssueError, "empty", "command is empty")
+		return issues
+	}
+
+	switch trimmed[0] {
+	case ':':
+	case '/':
+		add(IssueWarning, "slash_prefix", "command starts with '/', the API expects ':'")
+	default:
+		add(IssueError, "missing_prefix", "command must start with ':'")
+	}
+
+	fields := strings.Fields(trimmed)
+	verb := strings.ToLower(strings.TrimLeft(fields[0], ":/"))
+	if verb == "" {
+		add(IssueError, "missing_verb", "command has no verb")
+	} else if IsRestrictedCommand(trimmed) {
+		add(IssueWarning, "restricted", "command :%s is restricted and will be refused by the API", verb)
+	} else if spec, ok := knownCommands[verb]; !ok {
+		add(IssueWarning, "unknown_verb", "unknown command verb %q", verb)
+	} else {
+		args := fields[1:]
+		if len(args) < spec.args || (spec.textRequired && len(args) <= spec.args) {
+			add(IssueError, "missing_argument", "missing argument, usage: %s", spec.usage)
+		} else if !spec.text && len(args) > spec.args {
+			add(IssueWarning, "extra_argument", "unexpected extra arguments, usage: %s", spec.usage)
+		}
+		if spec.text && len(args) > spec.args {
+			if n := len([]rune(strings.Join(args[spec.args:], " "))); n > maxMessageLength {
+				add(IssueError, "message_too_long", "message is %d characters, the limit is %d", n, maxMessageLength)
+			}
+		}
+	}
+
+	if len(cmd) > maxCommandLength {
+		add(IssueError, "too_long", "command is %d bytes, the limit is %d", len(cmd), maxCommandLength)
+	}
+
+	if strings.ContainsAny(cmd, "\r\n") {
+		add(IssueError, "newline", "command contains a newline, which could inject another command")
+	}
+
+	for _, r := range cmd {
+		if r == '\n' || r == '\r' {
+			continue
+		}
+		if unicode.IsControl(r) || commands.IsInvisible(r) {
+			add(IssueError, "suspicious_char", "command contains suspicious character %U", r)
+			break
+		}
+	}
+
+	return issues
+}
+
+// CommandValidationError is returned by ExecuteCommand when command validation
+// is enabled and the command has error-severity issues.
+type CommandValidationError struct {
+	Command string
+	Issues  []Issue
+}
+
+func (e *CommandValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Issues))
+	for _, issue := range e.Issues {
+		msgs = append(msgs, issue.Message)
+	}
+	return fmt.Sprintf("invalid command: %s", strings.Join(msgs, "; "))
+}
+
+// WithCommandValidation makes ExecuteCommand run ValidateCommand and refuse to
+// send commands with error-severity issues. Warnings are ignored.
+func WithCommandValidation(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.validateCommands = enabled
+	}
+}
+
+// checkCommand returns an error if the CommandPolicy refuses the command, or a
+// CommandValidationError if validation is enabled and the command has
+// error-severity issues.
+func (c *Client) checkCommand(command string) error {
+	if err := c.checkPolicy(command); err != nil {
+		return err
+	}
+	if !c.validateCommands {
+		return nil
+	}
+	var errs []Issue
+	for _, issue := range ValidateCommand(command) {
+		if issue.Severity == IssueError {
+			errs = append(errs, issue)
+		}
+	}
+	if len(errs) > 0 {
+		return &CommandValidationError{Command: command, Issues: errs}
+	}
+	return nil
+}
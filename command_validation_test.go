@@ -0,0 +1,68 @@
+package erlcgo
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateCommand(t *testing.T) {
+	for _, tc := range []struct {
+		cmd   string
+		codes []string
+	}{
+		{":pm Player1 Hello there", nil},
+		{":kick Player1", nil},
+		{":KICK Player1 spamming chat", nil},
+		{":stopfire", nil},
+		{"", []string{"empty"}},
+		{"   ", []string{"empty"}},
+		{"pm Player1 Hello", []string{"missing_prefix"}},
+		{"/h Hello", []string{"slash_prefix"}},
+		{":", []string{"missing_verb"}},
+		{":fly Player1", []string{"unknown_verb"}},
+		{":unadmin Player1", []string{"restricted"}},
+		{":pm Player1", []string{"missing_argument"}},
+		{":tp Player1", []string{"missing_argument"}},
+		{":h", []string{"missing_argument"}},
+		{":ban Player1 forever", []string{"extra_argument"}},
+		{":h " + strings.Repeat("a", maxMessageLength+1), []string{"message_too_long"}},
+		{":log " + strings.Repeat("a ", maxCommandLength/2), []string{"message_too_long", "too_long"}},
+		{":h hi\n:ban Player2", []string{"newline"}},
+		{":h hi\u200bthere", []string{"suspicious_char"}},
+		{":h hi\x07", []string{"suspicious_char"}},
+	} {
+		var codes []string
+		for _, issue := range ValidateCommand(tc.cmd) {
+			codes = append(codes, issue.Code)
+		}
+		if !reflect.DeepEqual(codes, tc.codes) {
+			t.Errorf("ValidateCommand(%q) = %v, want %v", tc.cmd, codes, tc.codes)
+		}
+	}
+}
+
+func TestCommandValidationRefusesErrors(t *testing.T) {
+	rec := &commandRecorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+	c := NewClient("key", WithBaseURL(srv.URL), WithCommandValidation(true))
+	defer c.Close()
+	ctx := context.Background()
+
+	err := c.ExecuteCommand(ctx, ":pm Player1")
+	var verr *CommandValidationError
+	if !errors.As(err, &verr) || len(verr.Issues) != 1 || verr.Issues[0].Code != "missing_argument" {
+		t.Fatalf("got %v, want a missing_argument CommandValidationError", err)
+	}
+	// Warnings alone do not stop a command.
+	if err := c.ExecuteCommand(ctx, ":newverb Player1"); err != nil {
+		t.Fatalf("command with only warnings: %v", err)
+	}
+	if got := rec.received(); !reflect.DeepEqual(got, []string{":newverb Player1"}) {
+		t.Errorf("server received %q, want only the command with warnings", got)
+	}
+}
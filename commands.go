@@ -0,0 +1,46 @@
+package erlcgo
+
+import (
+	"context"
+
+	"github.com/bmrgcorp/erlcgo/commands"
+)
+
+// Execute validates a command built with the commands package and executes
+// it like ExecuteCommand. Problems with its arguments and error-severity
+// issues from ValidateCommand are returned as a CommandValidationError
+// before anything is sent.
+//
+// Example:
+//
+//	err := client.Execute(ctx, commands.Kick("Player1", "Exploiting"))
+func (c *Client) Execute(ctx context.Context, cmd commands.Command) error {
+	if err := validateBuilt(cmd); err != nil {
+		return err
+	}
+	return c.ExecuteCommand(ctx, cmd.String())
+}
+
+// validateBuilt checks a built command's arguments and rendered string,
+// returning a CommandValidationError listing every error-severity issue.
+func validateBuilt(cmd commands.Command) error {
+	var issues []Issue
+	if err := cmd.Err(); err != nil {
+		errs := []error{err}
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			errs = joined.Unwrap()
+		}
+		for _, e := range errs {
+			issues = append(issues, Issue{Severity: IssueError, Code: "bad_argument", Message: e.Error()})
+		}
+	}
+	for _, issue := range ValidateCommand(cmd.String()) {
+		if issue.Severity == IssueError {
+			issues = append(issues, issue)
+		}
+	}
+	if len(issues) > 0 {
+		return &CommandValidationError{Command: cmd.String(), Issues: issues}
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+// Package commands builds typed ER:LC in-game commands. Each constructor
+// sanitizes its arguments and renders to the string the API expects, so a
+// player name or message cannot break onto a second command.
+//
+// Example:
+//
+//	err := client.Execute(ctx, commands.PM("Player1", "Welcome!"))
+//	err = client.Execute(ctx, commands.SetWeather(commands.WeatherRain))
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Command is a typed in-game command built by one of the constructors in
+// this package. Send it with erlcgo's Client.Execute, which validates it
+// first, or render it with String.
+type Command struct {
+	verb string
+	args []string
+	// text is a trailing free-text argument such as a message or reason.
+	text string
+	// errs are problems with the arguments found by the constructor.
+	errs []error
+}
+
+// String renders the command, e.g. ":pm Player1 Hello".
+func (c Command) String() string {
+	parts := append([]string{":" + c.verb}, c.args...)
+	if text := SanitizeText(c.text); text != "" {
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Verb returns the command name without the ':' prefix, e.g. "pm".
+func (c Command) Verb() string {
+	return c.verb
+}
+
+// Err reports problems with the arguments the command was built from, such
+// as a player name that is empty or contains spaces or an unknown weather.
+// Errors from several arguments are joined with errors.Join.
+func (c Command) Err() error {
+	return errors.Join(c.errs...)
+}
+
+// player adds a player argument, sanitized with SanitizePlayerName. A name
+// that cannot be sanitized is kept as given and reported by Err.
+func (c Command) player(name string) Command {
+	clean, err := SanitizePlayerName(name)
+	if err != nil {
+		c.errs = append(c.errs, fmt.Errorf(":%s: %w", c.verb, err))
+		clean = name
+	}
+	c.args = append(c.args, clean)
+	return c
+}
+
+// Hint shows msg as a hint to everyone in the server.
+func Hint(msg string) Command { return Command{verb: "h", text: msg} }
+
+// Message shows msg as a server message to everyone in the server.
+func Message(msg string) Command { return Command{verb: "m", text: msg} }
+
+// PM sends msg privately to player.
+func PM(player, msg string) Command {
+	return Command{verb: "pm", text: msg}.player(player)
+}
+
+// Kick kicks player, with an optional reason.
+func Kick(player, reason string) Command {
+	return Command{verb: "kick", text: reason}.player(player)
+}
+
+// Ban bans player from the server.
+func Ban(player string) Command { return Command{verb: "ban"}.player(player) }
+
+// Unban lifts a ban on player.
+func Unban(player string) Command { return Command{verb: "unban"}.player(player) }
+
+// Teleport teleports player to target.
+func Teleport(player, target string) Command {
+	return Command{verb: "tp"}.player(player).player(target)
+}
+
+// Kill kills player.
+func Kill(player string) Command { return Command{verb: "kill"}.player(player) }
+
+// Heal heals player.
+func Heal(player string) Command { return Command{verb: "heal"}.player(player) }
+
+// Respawn respawns player.
+func Respawn(player string) Command { return Command{verb: "respawn"}.player(player) }
+
+// Wanted marks player as wanted.
+func Wanted(player string) Command { return Command{verb: "wanted"}.player(player) }
+
+// Unwanted clears player's wanted status.
+func Unwanted(player string) Command { return Command{verb: "unwanted"}.player(player) }
+
+// Jail jails player.
+func Jail(player string) Command { return Command{verb: "jail"}.player(player) }
+
+// Unjail releases player from jail.
+func Unjail(player string) Command { return Command{verb: "unjail"}.player(player) }
+
+// SetWeather changes the weather.
+func SetWeather(w Weather) Command {
+	cmd := Command{verb: "weather", args: []string{string(w)}}
+	if !w.Valid() {
+		cmd.errs = append(cmd.errs, fmt.Errorf(":weather: unknown weather %q", w))
+	}
+	return cmd
+}
+
+// SetTime sets the in-game hour, from 0 to 23.
+func SetTime(hour int) Command {
+	cmd := Command{verb: "time", args: []string{strconv.Itoa(hour)}}
+	if hour < 0 || hour > 23 {
+		cmd.errs = append(cmd.errs, fmt.Errorf(":time: hour %d is outside 0-23", hour))
+	}
+	return cmd
+}
+
+// PeaceTimer turns the peace timer on or off.
+func PeaceTimer(on bool) Command {
+	state := "off"
+	if on {
+		state = "on"
+	}
+	return Command{verb: "pt", args: []string{state}}
+}
+
+// Weather is a weather type accepted by SetWeather.
+type Weather string
+
+const (
+	WeatherClear        Weather = "clear"
+	WeatherRain         Weather = "rain"
+	WeatherThunderstorm Weather = "thunderstorm"
+	WeatherFog          Weather = "fog"
+	WeatherSnow         Weather = "snow"
+)
+
+// Weathers lists every valid Weather.
+var Weathers = []Weather{WeatherClear, WeatherRain, WeatherThunderstorm, WeatherFog, WeatherSnow}
+
+// Valid reports whether w is one of the Weather constants.
+func (w Weather) Valid() bool {
+	for _, v := range Weathers {
+		if w == v {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseWeather converts s, such as a value from a config file, to a Weather.
+// It ignores case and surrounding space and rejects unknown values.
+func ParseWeather(s string) (Weather, error) {
+	w := Weather(strings.ToLower(strings.TrimSpace(s)))
+	if !w.Valid() {
+		return "", fmt.Errorf("unknown weather %q, expected one of %v", s, Weathers)
+	}
+	return w, nil
+}
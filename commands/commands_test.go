@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCommandString(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  Command
+		want string
+	}{
+		{"pm", PM("Player1", "Hello there"), ":pm Player1 Hello there"},
+		{"pm id suffix", PM("Player1:12345", "Hi"), ":pm Player1 Hi"},
+		{"pm quoted", PM(`"Player1"`, "Hi"), ":pm Player1 Hi"},
+		{"pm smart quoted", PM("“Player1”", "Hi"), ":pm Player1 Hi"},
+		{"pm single quoted", PM("'Player1'", "Hi"), ":pm Player1 Hi"},
+		{"pm newline in message", PM("Player1", "line one\n:ban Player2"), ":pm Player1 line one :ban Player2"},
+		{"pm invisible in name", PM("Play\u200ber1", "Hi"), ":pm Player1 Hi"},
+		{"kick reason", Kick("Player1", " Exploiting\t"), ":kick Player1 Exploiting"},
+		{"kick no reason", Kick("Player1", ""), ":kick Player1"},
+		{"ban", Ban("Player1:42"), ":ban Player1"},
+		{"teleport", Teleport("Player1", "Player2:7"), ":tp Player1 Player2"},
+		{"hint", Hint("Restart in\r\n5 minutes"), ":h Restart in  5 minutes"},
+		{"message bidi", Message("abc\u202edef"), ":m abcdef"},
+		{"weather", SetWeather(WeatherRain), ":weather rain"},
+		{"time", SetTime(14), ":time 14"},
+		{"peace timer", PeaceTimer(true), ":pt on"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cmd.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+			if err := tt.cmd.Err(); err != nil {
+				t.Errorf("Err() = %v", err)
+			}
+		})
+	}
+}
+
+func TestCommandErr(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  Command
+		want []string
+	}{
+		{"empty player", PM("", "Hi"), []string{`:pm: player ""`}},
+		{"spaced player", Kick("Player One", "x"), []string{`:kick: player "Player One"`}},
+		{"quotes only", Ban(`""`), []string{`:ban: player`}},
+		{"both players", Teleport("a b", " "), []string{`player "a b"`, `player " "`}},
+		{"weather", SetWeather("sunny"), []string{`unknown weather "sunny"`}},
+		{"time", SetTime(24), []string{"hour 24"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cmd.Err()
+			if err == nil {
+				t.Fatalf("Err() = nil for %q", tt.cmd)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Err() = %q, want it to mention %q", err, want)
+				}
+			}
+		})
+	}
+
+	// An unusable name is kept as given, so the rendered command shows it.
+	if got := PM("Player One", "Hi").String(); got != ":pm Player One Hi" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestSanitizeText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Hello there", "Hello there"},
+		{"  padded  ", "padded"},
+		{"line one\n:ban Player2", "line one :ban Player2"},
+		{"a\r\nb\tc", "a  b c"},
+		{"bell\x07 and\x00 nul", "bell and nul"},
+		{"hid\u200bden \u202ereversed\u202c", "hidden reversed"},
+		{"\ufeff\u2066isolated\u2069", "isolated"},
+		{"emoji \U0001F600 and accents \u00e9", "emoji \U0001F600 and accents \u00e9"},
+		{"\n\t", ""},
+	}
+	for _, tt := range tests {
+		if got := SanitizeText(tt.in); got != tt.want {
+			t.Errorf("SanitizeText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSanitizePlayerName(t *testing.T) {
+	tests := []struct {
+		in, want string
+		ok       bool
+	}{
+		{"Player1", "Player1", true},
+		{" Player1:123 ", "Player1", true},
+		{"Player1:abc", "Player1:abc", true},
+		{":123", ":123", true},
+		{"`Player1`", "Player1", true},
+		{"Pla\tyer", "", false},
+		{"\u200b", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, err := SanitizePlayerName(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("SanitizePlayerName(%q) = %q, %v; want %q, ok=%v", tt.in, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseWeather(t *testing.T) {
+	if w, err := ParseWeather(" Thunderstorm "); err != nil || w != WeatherThunderstorm {
+		t.Errorf("ParseWeather = %q, %v", w, err)
+	}
+	if _, err := ParseWeather("sunny"); err == nil {
+		t.Error("ParseWeather(sunny) succeeded")
+	}
+}
@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// SanitizeText makes s safe to use as the free text of a command, such as a
+// message or kick reason: newlines and tabs become spaces, control and
+// invisible characters are removed, and surrounding space is trimmed, so the
+// text cannot break onto a second command or hide content. The constructors
+// apply it to their text automatically.
+//
+// Example:
+//
+//	err := client.ExecuteCommand(ctx, ":h "+commands.SanitizeText(userInput))
+func SanitizeText(s string) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\r' || r == '\t':
+			return ' '
+		case unicode.IsControl(r) || IsInvisible(r):
+			return -1
+		}
+		return r
+	}, s))
+}
+
+// SanitizePlayerName makes s usable as the player argument of a command. It
+// accepts the "Name:ID" form used in API responses and keeps the name,
+// removes surrounding quotes and invisible characters, and returns an error
+// if what remains is empty or contains whitespace. The constructors apply it
+// to their player arguments automatically.
+//
+// Example:
+//
+//	for _, p := range resp.Players {
+//	    name, err := commands.SanitizePlayerName(p.Player) // "Player1:12345" -> "Player1"
+//	    ...
+//	}
+func SanitizePlayerName(s string) (string, error) {
+	name := strings.Trim(SanitizeText(s), "\"'`“”‘’")
+	if i := strings.LastIndexByte(name, ':'); i > 0 && isDigits(name[i+1:]) {
+		name = name[:i]
+	}
+	if name == "" || strings.IndexFunc(name, unicode.IsSpace) >= 0 {
+		return "", fmt.Errorf("player %q must be a single non-empty word", s)
+	}
+	return name, nil
+}
+
+// IsInvisible reports zero-width and bidirectional override characters,
+// which can hide text from moderators reading command logs.
+func IsInvisible(r rune) bool {
+	switch {
+	case r >= 0x200B && r <= 0x200F, // zero-width space/joiners, LRM/RLM
+		r >= 0x202A && r <= 0x202E, // bidi embeddings and overrides
+		r >= 0x2066 && r <= 0x2069, // bidi isolates
+		r == 0xFEFF:
+		return true
+	}
+	return false
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,47 @@
+package erlcgo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bmrgcorp/erlcgo/commands"
+)
+
+func TestExecute(t *testing.T) {
+	var sent atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		sent.Store(string(body))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	c := NewClient("key", WithBaseURL(srv.URL))
+	defer c.Close()
+
+	if err := c.Execute(context.Background(), commands.PM("Player1:123", "Hi\nthere")); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := sent.Load().(string); !strings.Contains(got, `":pm Player1 Hi there"`) {
+		t.Errorf("sent %s", got)
+	}
+
+	sent.Store("")
+	err := c.Execute(context.Background(), commands.Teleport("Player One", ""))
+	var verr *CommandValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("got %v, want a CommandValidationError", err)
+	}
+	if len(verr.Issues) != 2 || verr.Issues[0].Code != "bad_argument" {
+		t.Errorf("issues %+v, want one bad_argument per player", verr.Issues)
+	}
+	if got := sent.Load().(string); got != "" {
+		t.Errorf("an invalid command was sent: %s", got)
+	}
+}
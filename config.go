@@ -0,0 +1,174 @@
+package erlcgo
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Config holds the commonly tuned client settings in one place, so binaries
+// can fill them from flags with BindFlags or from a file with LoadConfig, and
+// build clients from them. The struct tags let koanf, viper and other
+// mapstructure-based loaders decode into it directly.
+type Config struct {
+	ServerKey    string `json:"server_key" yaml:"server_key" mapstructure:"server_key" koanf:"server_key"`
+	GlobalAPIKey string `json:"global_api_key" yaml:"global_api_key" mapstructure:"global_api_key" koanf:"global_api_key"`
+
+	// Timeout is the per-request HTTP timeout.
+	Timeout time.Duration `json:"timeout" yaml:"timeout" mapstructure:"timeout" koanf:"timeout"`
+
+	// QueueWorkers and QueueInterval configure the request queue. Zero
+	// workers disables queueing.
+	QueueWorkers  int           `json:"queue_workers" yaml:"queue_workers" mapstructure:"queue_workers" koanf:"queue_workers"`
+	QueueInterval time.Duration `json:"queue_interval" yaml:"queue_interval" mapstructure:"queue_interval" koanf:"queue_interval"`
+
+	// CacheTTL enables the response cache with this TTL. Zero disables it.
+	CacheTTL time.Duration `json:"cache_ttl" yaml:"cache_ttl" mapstructure:"cache_ttl" koanf:"cache_ttl"`
+
+	// PollInterval is the default subscription poll interval.
+	PollInterval time.Duration `json:"poll_interval" yaml:"poll_interval" mapstructure:"poll_interval" koanf:"poll_interval"`
+}
+
+// DefaultConfig returns a Config with the ProfileBalanced settings. The
+// keys are read from ERLC_SERVER_KEY and ERLC_GLOBAL_KEY.
+func DefaultConfig() *Config {
+	return &Config{
+		ServerKey:     os.Getenv("ERLC_SERVER_KEY"),
+		GlobalAPIKey:  os.Getenv("ERLC_GLOBAL_KEY"),
+		Timeout:       time.Second * 10,
+		QueueWorkers:  2,
+		QueueInterval: time.Second,
+		CacheTTL:      time.Second * 2,
+		PollInterval:  time.Second * 2,
+	}
+}
+
+// Validate reports every problem with the config.
+func (cfg *Config) Validate() error {
+	var errs []error
+	if cfg.ServerKey == "" {
+		errs = append(errs, errors.New("server key is required; set server_key or ERLC_SERVER_KEY"))
+	}
+	if cfg.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("timeout %s is negative", cfg.Timeout))
+	}
+	if cfg.QueueWorkers < 0 {
+		errs = append(errs, fmt.Errorf("queue workers %d is negative", cfg.QueueWorkers))
+	}
+	if cfg.QueueWorkers > 0 && cfg.QueueInterval <= 0 {
+		errs = append(errs, errors.New("queue interval must be positive when the queue is enabled"))
+	}
+	if cfg.CacheTTL < 0 {
+		errs = append(errs, fmt.Errorf("cache TTL %s is negative", cfg.CacheTTL))
+	}
+	if cfg.PollInterval <= 0 {
+		errs = append(errs, fmt.Errorf("poll interval %s must be positive", cfg.PollInterval))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid erlcgo config: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Options returns the client options for the config. The server key is not
+// an option; pass it to NewClient, or use Config.NewClient.
+func (cfg *Config) Options() []ClientOption {
+	var opts []ClientOption
+	if cfg.GlobalAPIKey != "" {
+		opts = append(opts, WithGlobalAPIKey(cfg.GlobalAPIKey))
+	}
+	if cfg.Timeout > 0 {
+		opts = append(opts, WithTimeout(cfg.Timeout))
+	}
+	if cfg.QueueWorkers > 0 {
+		opts = append(opts, WithRequestQueue(cfg.QueueWorkers, cfg.QueueInterval))
+	}
+	if cfg.CacheTTL > 0 {
+		cache := DefaultCacheConfig()
+		cache.TTL = cfg.CacheTTL
+		opts = append(opts, WithCache(cache))
+	}
+	if cfg.PollInterval > 0 {
+		opts = append(opts, ServerOverrides{PollInterval: cfg.PollInterval}.Option())
+	}
+	return opts
+}
+
+// NewClient validates the config and creates a client from it. Options
+// passed here are applied after the config's own.
+func (cfg *Config) NewClient(opts ...ClientOption) (*Client, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return NewClient(cfg.ServerKey, append(cfg.Options(), opts...)...), nil
+}
+
+// BindFlags registers the standard erlcgo flags on fs, or on the default
+// flag set if fs is nil, and returns the Config they fill in when fs is
+// parsed. Defaults come from DefaultConfig. Malformed and negative values
+// are rejected during parsing; call Config.NewClient, which validates the
+// whole config, once parsing is done.
+//
+// The flags are -erlc-server-key, -erlc-global-key, -erlc-timeout,
+// -erlc-workers, -erlc-queue-interval, -erlc-cache-ttl and
+// -erlc-poll-interval.
+//
+// Example:
+//
+//	cfg := erlcgo.BindFlags(nil)
+//	flag.Parse()
+//	client, err := cfg.NewClient()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func BindFlags(fs *flag.FlagSet) *Config {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+	cfg := DefaultConfig()
+	fs.StringVar(&cfg.ServerKey, "erlc-server-key", cfg.ServerKey, "ER:LC server key (default from ERLC_SERVER_KEY)")
+	fs.StringVar(&cfg.GlobalAPIKey, "erlc-global-key", cfg.GlobalAPIKey, "ER:LC global API key (default from ERLC_GLOBAL_KEY)")
+	fs.Var((*durationFlag)(&cfg.Timeout), "erlc-timeout", "per-request timeout")
+	fs.Var((*countFlag)(&cfg.QueueWorkers), "erlc-workers", "request queue workers; 0 disables the queue")
+	fs.Var((*durationFlag)(&cfg.QueueInterval), "erlc-queue-interval", "spacing between queued requests")
+	fs.Var((*durationFlag)(&cfg.CacheTTL), "erlc-cache-ttl", "response cache TTL; 0 disables the cache")
+	fs.Var((*durationFlag)(&cfg.PollInterval), "erlc-poll-interval", "default subscription poll interval")
+	return cfg
+}
+
+// durationFlag is a flag.Value for a duration that must not be negative.
+type durationFlag time.Duration
+
+func (d *durationFlag) String() string { return time.Duration(*d).String() }
+
+func (d *durationFlag) Set(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	if v < 0 {
+		return fmt.Errorf("duration %s is negative", v)
+	}
+	*d = durationFlag(v)
+	return nil
+}
+
+// countFlag is a flag.Value for an int that must not be negative.
+type countFlag int
+
+func (n *countFlag) String() string { return strconv.Itoa(int(*n)) }
+
+func (n *countFlag) Set(s string) error {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	if v < 0 {
+		return fmt.Errorf("%d is negative", v)
+	}
+	*n = countFlag(v)
+	return nil
+}
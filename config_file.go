@@ -0,0 +1,307 @@
+package erlcgo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// configField maps a config file key to a Config field.
+type configField struct {
+	key string
+	get func(cfg *Config) string
+	set func(cfg *Config, value string) error
+
+	// secret fields are never marshalled or printed.
+	secret bool
+}
+
+func secretField(key string, field func(*Config) *string) configField {
+	return configField{
+		key:    key,
+		secret: true,
+		get:    func(cfg *Config) string { return *field(cfg) },
+		set:    func(cfg *Config, v string) error { *field(cfg) = v; return nil },
+	}
+}
+
+func durationField(key string, field func(*Config) *time.Duration) configField {
+	return configField{
+		key: key,
+		get: func(cfg *Config) string { return field(cfg).String() },
+		set: func(cfg *Config, v string) error {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("%s: %q is not a duration such as \"10s\"", key, v)
+			}
+			*field(cfg) = d
+			return nil
+		},
+	}
+}
+
+func intField(key string, field func(*Config) *int) configField {
+	return configField{
+		key: key,
+		get: func(cfg *Config) string { return strconv.Itoa(*field(cfg)) },
+		set: func(cfg *Config, v string) error {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("%s: %q is not a whole number", key, v)
+			}
+			*field(cfg) = n
+			return nil
+		},
+	}
+}
+
+// configFields lists the config file keys in the order they are written.
+// They match the struct tags on Config.
+var configFields = []configField{
+	secretField("server_key", func(c *Config) *string { return &c.ServerKey }),
+	secretField("global_api_key", func(c *Config) *string { return &c.GlobalAPIKey }),
+	durationField("timeout", func(c *Config) *time.Duration { return &c.Timeout }),
+	intField("queue_workers", func(c *Config) *int { return &c.QueueWorkers }),
+	durationField("queue_interval", func(c *Config) *time.Duration { return &c.QueueInterval }),
+	durationField("cache_ttl", func(c *Config) *time.Duration { return &c.CacheTTL }),
+	durationField("poll_interval", func(c *Config) *time.Duration { return &c.PollInterval }),
+}
+
+func lookupConfigField(key string) (configField, bool) {
+	for _, f := range configFields {
+		if f.key == key {
+			return f, true
+		}
+	}
+	return configField{}, false
+}
+
+// LoadConfig reads a Config from a JSON (.json) or YAML (.yaml, .yml) file.
+// Settings missing from the file keep their DefaultConfig values, so keys
+// can still come from the environment. Durations are written as strings
+// such as "1500ms". A null value, or an empty one in YAML, also keeps the
+// default. Unknown keys are rejected to catch typos, and the result is
+// validated.
+//
+// Only flat key: value YAML is supported, which is all Config needs. Teams
+// already using koanf or viper can unmarshal into Config directly using its
+// koanf and mapstructure tags.
+//
+// Example:
+//
+//	# erlc.yaml
+//	server_key: "abc123"
+//	queue_workers: 4
+//	poll_interval: 1s
+//
+//	cfg, err := erlcgo.LoadConfig("erlc.yaml")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	client, err := cfg.NewClient()
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	cfg := DefaultConfig()
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		err = json.Unmarshal(data, cfg)
+	case ".yaml", ".yml":
+		err = cfg.unmarshalYAML(data)
+	default:
+		return nil, fmt.Errorf("unsupported config file type %q, use .json, .yaml or .yml", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// MarshalJSON writes the config with the file keys and durations as strings.
+// The server and global API keys are left out even though UnmarshalJSON
+// reads them, so a marshalled config can be logged or served safely. Loading
+// it back with LoadConfig therefore fails validation unless ERLC_SERVER_KEY
+// is set or server_key is added to the file by hand.
+func (cfg Config) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for _, f := range configFields {
+		if f.secret {
+			continue
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(f.key)
+		buf.Write(key)
+		buf.WriteByte(':')
+		if f.key == "queue_workers" {
+			buf.WriteString(f.get(&cfg))
+			continue
+		}
+		value, _ := json.Marshal(f.get(&cfg))
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// String describes the config with the server and global API keys redacted.
+func (cfg Config) String() string {
+	var b strings.Builder
+	b.WriteString("erlcgo.Config{")
+	for i, f := range configFields {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		value := f.get(&cfg)
+		if f.secret {
+			value = redact(value)
+		}
+		b.WriteString(f.key + "=" + value)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// GoString redacts the keys from %#v output too.
+func (cfg Config) GoString() string {
+	return cfg.String()
+}
+
+// redact hides a secret, showing only whether it is set.
+func redact(secret string) string {
+	if secret == "" {
+		return `""`
+	}
+	return "[redacted]"
+}
+
+// UnmarshalJSON reads the keys written by MarshalJSON, as well as server_key
+// and global_api_key, leaving fields that are absent or null unchanged.
+func (cfg *Config) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for key, value := range raw {
+		f, ok := lookupConfigField(key)
+		if !ok {
+			return fmt.Errorf("unknown config key %q", key)
+		}
+		if string(bytes.TrimSpace(value)) == "null" {
+			continue
+		}
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			// Numbers are accepted as they are, for queue_workers.
+			s = string(bytes.TrimSpace(value))
+		}
+		if err := f.set(cfg, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unmarshalYAML reads a flat YAML mapping of config keys. Null values leave
+// the field unchanged. Nested mappings, lists and multi-line values are
+// rejected.
+func (cfg *Config) unmarshalYAML(data []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), " \t\r")
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" || trimmed == "---" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if text[0] == ' ' || text[0] == '\t' || trimmed == "-" || strings.HasPrefix(trimmed, "- ") {
+			return fmt.Errorf("line %d: only flat key: value mappings are supported", line)
+		}
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			return fmt.Errorf("line %d: expected key: value", line)
+		}
+		key = strings.TrimSpace(key)
+		f, known := lookupConfigField(key)
+		if !known {
+			return fmt.Errorf("line %d: unknown config key %q", line, key)
+		}
+		v, null, err := yamlScalar(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if null {
+			continue
+		}
+		if err := f.set(cfg, v); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// yamlScalar decodes a plain, single-quoted or double-quoted YAML scalar,
+// dropping a trailing comment. null reports an empty, ~ or null value, as
+// opposed to an empty quoted string.
+func yamlScalar(s string) (value string, null bool, err error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		end := 1
+		for ; end < len(s); end++ {
+			if s[end] == '\\' {
+				end++
+			} else if s[end] == '"' {
+				break
+			}
+		}
+		if end >= len(s) {
+			return "", false, fmt.Errorf("unterminated string %s", s)
+		}
+		if rest := strings.TrimSpace(s[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", false, fmt.Errorf("unexpected text after string: %s", rest)
+		}
+		value, err = strconv.Unquote(s[:end+1])
+		return value, false, err
+	case strings.HasPrefix(s, "'"):
+		var b strings.Builder
+		for i := 1; i < len(s); i++ {
+			if s[i] != '\'' {
+				b.WriteByte(s[i])
+				continue
+			}
+			if i+1 < len(s) && s[i+1] == '\'' {
+				b.WriteByte('\'')
+				i++
+				continue
+			}
+			if rest := strings.TrimSpace(s[i+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+				return "", false, fmt.Errorf("unexpected text after string: %s", rest)
+			}
+			return b.String(), false, nil
+		}
+		return "", false, fmt.Errorf("unterminated string %s", s)
+	case strings.HasPrefix(s, "|") || strings.HasPrefix(s, ">") || strings.HasPrefix(s, "{") || strings.HasPrefix(s, "["):
+		return "", false, fmt.Errorf("only single-line scalar values are supported")
+	}
+	if strings.HasPrefix(s, "#") {
+		s = ""
+	} else if i := strings.Index(s, " #"); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	if s == "" || s == "~" || s == "null" {
+		return "", true, nil
+	}
+	return s, false, nil
+}
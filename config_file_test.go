@@ -0,0 +1,144 @@
+package erlcgo
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestYAMLScalar(t *testing.T) {
+	for _, tc := range []struct {
+		in    string
+		want  string
+		null  bool
+		error string
+	}{
+		{in: `plain`, want: "plain"},
+		{in: `10s # ten seconds`, want: "10s"},
+		{in: `a#b`, want: "a#b"},
+		{in: `"double"`, want: "double"},
+		{in: `"esc\"aped\n"`, want: "esc\"aped\n"},
+		{in: `"has # hash" # comment`, want: "has # hash"},
+		{in: `'single'`, want: "single"},
+		{in: `'it''s'`, want: "it's"},
+		{in: `'' # empty`, want: ""},
+		{in: `""`, want: ""},
+		{in: ``, null: true},
+		{in: `~`, null: true},
+		{in: `null`, null: true},
+		{in: `null # unset`, null: true},
+		{in: `# only a comment`, null: true},
+		{in: `"open`, error: "unterminated"},
+		{in: `'open`, error: "unterminated"},
+		{in: `"a" b`, error: "unexpected text"},
+		{in: `'a' b`, error: "unexpected text"},
+		{in: `|`, error: "single-line"},
+		{in: `>-`, error: "single-line"},
+		{in: `{a: 1}`, error: "single-line"},
+		{in: `[1, 2]`, error: "single-line"},
+	} {
+		got, null, err := yamlScalar(tc.in)
+		if tc.error != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.error) {
+				t.Errorf("yamlScalar(%q) error = %v, want %q", tc.in, err, tc.error)
+			}
+			continue
+		}
+		if err != nil || got != tc.want || null != tc.null {
+			t.Errorf("yamlScalar(%q) = %q, %v, %v; want %q, %v", tc.in, got, null, err, tc.want, tc.null)
+		}
+	}
+}
+
+func TestUnmarshalYAML(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		yaml  string
+		check func(*Config) bool
+		error string
+	}{
+		{
+			name: "flat",
+			yaml: "---\n# settings\nserver_key: \"abc\"\nqueue_workers: 4 # busy\npoll_interval: '500ms'\n\n",
+			check: func(c *Config) bool {
+				return c.ServerKey == "abc" && c.QueueWorkers == 4 && c.PollInterval == 500*time.Millisecond
+			},
+		},
+		{
+			name:  "null keeps the default",
+			yaml:  "timeout: null\ncache_ttl: ~\npoll_interval:\n",
+			check: func(c *Config) bool { return *c == *DefaultConfig() },
+		},
+		{name: "empty quoted duration", yaml: "timeout: ''", error: "not a duration"},
+		{name: "unknown key", yaml: "timout: 1s", error: "line 1: unknown config key"},
+		{name: "nested mapping", yaml: "timeout:\n  seconds: 1", error: "line 2: only flat"},
+		{name: "list", yaml: "- timeout", error: "line 1: only flat"},
+		{name: "block scalar", yaml: "server_key: |\n  abc", error: "single-line"},
+		{name: "folded continuation", yaml: "server_key: abc\n  def", error: "line 2: only flat"},
+		{name: "missing colon", yaml: "timeout 1s", error: "line 1: expected key: value"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			err := cfg.unmarshalYAML([]byte(tc.yaml))
+			if tc.error != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.error) {
+					t.Errorf("error = %v, want %q", err, tc.error)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tc.check(cfg) {
+				t.Errorf("got %v", cfg)
+			}
+		})
+	}
+}
+
+func TestConfigJSONRoundTrip(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ServerKey = "secret-server"
+	cfg.GlobalAPIKey = "secret-global"
+	cfg.QueueWorkers = 3
+	cfg.Timeout = 1500 * time.Millisecond
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Fatalf("marshalled config leaks a key: %s", data)
+	}
+	path := filepath.Join(t.TempDir(), "erlc.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without the keys the file alone does not validate.
+	t.Setenv("ERLC_SERVER_KEY", "")
+	if _, err := LoadConfig(path); err == nil || !strings.Contains(err.Error(), "ERLC_SERVER_KEY") {
+		t.Errorf("LoadConfig without a key: %v, want a hint to set ERLC_SERVER_KEY", err)
+	}
+
+	t.Setenv("ERLC_SERVER_KEY", "from-env")
+	loaded, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.ServerKey != "from-env" || loaded.QueueWorkers != 3 || loaded.Timeout != cfg.Timeout {
+		t.Errorf("loaded %v", loaded)
+	}
+
+	// Keys read from a file, and null values leave defaults.
+	var fromFile Config
+	if err := json.Unmarshal([]byte(`{"server_key":"abc","timeout":null}`), &fromFile); err != nil {
+		t.Fatal(err)
+	}
+	if fromFile.ServerKey != "abc" || fromFile.Timeout != 0 {
+		t.Errorf("unmarshalled %v", fromFile)
+	}
+}
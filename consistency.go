@@ -0,0 +1,68 @@
+package erlcgo
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// cacheTaint tracks the read-your-writes window opened by a command. While the
+// window is open, cached GET responses are bypassed until each route has been
+// refetched once after the command was sent.
+type cacheTaint struct {
+	mu        sync.Mutex
+	at        time.Time
+	until     time.Time
+	refreshed map[string]struct{}
+}
+
+// taint opens a new read-your-writes window of the given length.
+func (t *cacheTaint) taint(window time.Duration) {
+	if window <= 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	t.at = now
+	t.until = now.Add(window)
+	t.refreshed = make(map[string]struct{})
+}
+
+// bypass reports whether the cached value for key must be ignored.
+func (t *cacheTaint) bypass(key string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !time.Now().Before(t.until) {
+		return false
+	}
+	_, ok := t.refreshed[key]
+	return !ok
+}
+
+// markRefreshed records that key was fetched from the API by a request that
+// started at the given time. Requests that started before the command do
+// not count, as they may not reflect its effects.
+func (t *cacheTaint) markRefreshed(key string, started time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.refreshed == nil || started.Before(t.at) {
+		return
+	}
+	t.refreshed[key] = struct{}{}
+}
+
+type freshReadKey struct{}
+
+// withFreshRead marks ctx so its GET requests skip cached responses, for
+// internal pollers that must see every change. The responses still refresh
+// the cache for other callers.
+func withFreshRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, freshReadKey{}, true)
+}
+
+// isFreshRead reports whether ctx was marked with withFreshRead.
+func isFreshRead(ctx context.Context) bool {
+	fresh, _ := ctx.Value(freshReadKey{}).(bool)
+	return fresh
+}
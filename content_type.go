@@ -0,0 +1,50 @@
+package erlcgo
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// ErrUnexpectedContentType is returned when a successful response is not
+// JSON, which usually means a proxy or Cloudflare answered in PRC's place.
+// Preview holds the start of the body, shortened and made printable.
+type ErrUnexpectedContentType struct {
+	ContentType string
+	StatusCode  int
+	Preview     string
+}
+
+func (e *ErrUnexpectedContentType) Error() string {
+	return fmt.Sprintf("unexpected content type %q (status %d): %s", e.ContentType, e.StatusCode, e.Preview)
+}
+
+// isJSONContentType reports whether a Content-Type header allows a JSON body.
+// A missing header is accepted, since some proxies and test servers omit it.
+func isJSONContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || mediaType == "text/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// checkContentType returns ErrUnexpectedContentType if resp is not JSON,
+// reading just enough of the body for a preview.
+func checkContentType(resp *http.Response) error {
+	contentType := resp.Header.Get("Content-Type")
+	if isJSONContentType(contentType) {
+		return nil
+	}
+	head, _ := io.ReadAll(io.LimitReader(resp.Body, 4*maxMessagePreview))
+	return &ErrUnexpectedContentType{
+		ContentType: contentType,
+		StatusCode:  resp.StatusCode,
+		Preview:     previewBody(head, contentType),
+	}
+}
@@ -0,0 +1,232 @@
+package erlcgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxContractDiffs caps how many differences a contract check lists per route.
+const maxContractDiffs = 10
+
+// contractRoute is a route covered by the contract check, with the type its
+// response decodes into.
+type contractRoute struct {
+	path     string
+	newValue func() interface{}
+
+	// command routes have side effects in game, so CheckContract never
+	// calls them; their recorded responses can still be verified.
+	command bool
+}
+
+// contractRoutes lists the documented routes.
+var contractRoutes = []contractRoute{
+	{path: serverPath(optionsFromMask(uint(1)<<len(serverOptionFlags) - 1)), newValue: func() interface{} { return new(ERLCServerResponse) }},
+	{path: "/v1/server/bans", newValue: func() interface{} { return new(ERLCBans) }},
+	{path: "/v2/server/command", newValue: func() interface{} { return new(CommandResult) }, command: true},
+}
+
+// contractFor returns the contract route for path, ignoring its query.
+func contractFor(path string) (contractRoute, bool) {
+	base, _, _ := strings.Cut(path, "?")
+	for _, r := range contractRoutes {
+		if p, _, _ := strings.Cut(r.path, "?"); p == base {
+			return r, true
+		}
+	}
+	return contractRoute{}, false
+}
+
+// VerifyContract checks a recorded response body for path against the
+// response structs: the body must decode, and encoding the decoded value must
+// give back the same document. Fields the structs do not model are reported
+// as warnings, since their data is dropped; values that change on the way
+// through, or fail to decode, are failures. Keep recorded bodies for each
+// route and check them in CI to catch regressions as the structs evolve.
+//
+// Example:
+//
+//	body, _ := os.ReadFile("testdata/server.json")
+//	if report := erlcgo.VerifyContract("/v2/server", body); !report.OK() {
+//	    t.Fatal(report)
+//	}
+func VerifyContract(path string, body []byte) *DoctorReport {
+	report := &DoctorReport{}
+	route, ok := contractFor(path)
+	if !ok {
+		report.add(path, DoctorFail, "no contract for this route")
+		return report
+	}
+	verifyContract(report, route, path, body)
+	return report
+}
+
+// CheckContract fetches every documented read route with all optional data
+// requested and verifies each response with VerifyContract. The command
+// route is skipped, since sending a command has side effects in game. Like
+// Doctor, it bypasses the queue and cache. Point the client at a mock server with
+// WithBaseURL to check a recorded contract, or at the live API to find out
+// whether PRC changed a response shape.
+//
+// Example:
+//
+//	report := client.CheckContract(ctx)
+//	fmt.Print(report)
+func (c *Client) CheckContract(ctx context.Context) *DoctorReport {
+	report := &DoctorReport{}
+	for _, route := range contractRoutes {
+		if route.command {
+			continue
+		}
+		body, err := c.fetchContract(ctx, route.path)
+		if err != nil {
+			report.add(route.path, DoctorFail, "%v", err)
+			continue
+		}
+		verifyContract(report, route, route.path, body)
+	}
+	return report
+}
+
+func (c *Client) fetchContract(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not build request: %w", err)
+	}
+	req.Header.Set("Server-Key", c.apiKey)
+	if c.globalAPIKey != "" {
+		req.Header.Set("Authorization", c.globalAPIKey)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || isErrorEnvelope(body) {
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, previewBody(body, resp.Header.Get("Content-Type")))
+	}
+	return body, nil
+}
+
+func verifyContract(report *DoctorReport, route contractRoute, name string, body []byte) {
+	var raw interface{}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		report.add(name, DoctorFail, "response is not JSON: %v", err)
+		return
+	}
+	v := route.newValue()
+	if err := json.Unmarshal(body, v); err != nil {
+		report.add(name, DoctorFail, "does not decode into %T: %v", v, err)
+		return
+	}
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		report.add(name, DoctorFail, "decoded %T does not encode: %v", v, err)
+		return
+	}
+	var trip interface{}
+	if err := json.Unmarshal(encoded, &trip); err != nil {
+		report.add(name, DoctorFail, "re-encoded %T is not JSON: %v", v, err)
+		return
+	}
+
+	var d contractDiff
+	d.compare("$", raw, trip)
+	switch {
+	case len(d.changed) > 0:
+		report.add(name, DoctorFail, "values change on round trip: %s", summarizeDiffs(d.changed))
+	case len(d.unknown) > 0:
+		report.add(name, DoctorWarn, "fields not modelled by %T: %s", v, summarizeDiffs(d.unknown))
+	case len(d.missing) > 0:
+		report.add(name, DoctorWarn, "fields of %T not sent by the API: %s", v, summarizeDiffs(d.missing))
+	default:
+		report.add(name, DoctorOK, "round trips through %T", v)
+	}
+}
+
+// contractDiff collects the JSON paths where a response and its round trip
+// disagree.
+type contractDiff struct {
+	unknown []string // in the response but dropped by the structs
+	missing []string // added by the structs but absent from the response
+	changed []string // present in both with different values
+}
+
+func (d *contractDiff) compare(path string, raw, trip interface{}) {
+	switch r := raw.(type) {
+	case map[string]interface{}:
+		t, ok := trip.(map[string]interface{})
+		if !ok {
+			d.changed = append(d.changed, path)
+			return
+		}
+		for _, key := range sortedKeys(r) {
+			tv, ok := t[key]
+			switch {
+			case ok:
+				d.compare(path+"."+key, r[key], tv)
+			case r[key] != nil:
+				d.unknown = append(d.unknown, path+"."+key)
+			}
+		}
+		for _, key := range sortedKeys(t) {
+			if _, ok := r[key]; !ok && !isZeroJSON(t[key]) {
+				d.changed = append(d.changed, path+"."+key)
+			} else if !ok {
+				d.missing = append(d.missing, path+"."+key)
+			}
+		}
+	case []interface{}:
+		t, ok := trip.([]interface{})
+		if !ok || len(t) != len(r) {
+			d.changed = append(d.changed, path)
+			return
+		}
+		for i := range r {
+			d.compare(fmt.Sprintf("%s[%d]", path, i), r[i], t[i])
+		}
+	case nil:
+		// PRC sends null for empty lists and unset values.
+		if !isZeroJSON(trip) {
+			d.changed = append(d.changed, path)
+		}
+	default:
+		if raw != trip {
+			d.changed = append(d.changed, path)
+		}
+	}
+}
+
+// isZeroJSON reports whether v is the decoded form of a Go zero value.
+func isZeroJSON(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case float64:
+		return v == 0
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+func summarizeDiffs(paths []string) string {
+	if len(paths) <= maxContractDiffs {
+		return strings.Join(paths, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(paths[:maxContractDiffs], ", "), len(paths)-maxContractDiffs)
+}
@@ -0,0 +1,171 @@
+package erlcgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// contractFixtures maps each contract route, without its query, to the
+// recorded response under testdata/contract.
+var contractFixtures = map[string]string{
+	"/v2/server":         "server.json",
+	"/v1/server/bans":    "bans.json",
+	"/v2/server/command": "command.json",
+}
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", "contract", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+// contractServer replays the recorded fixtures. Commands starting with
+// ":restricted" get the recorded restricted error, and GET /v1/server/limited
+// the recorded rate limit error.
+func contractServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Server-Key") != "contract-key" {
+			t.Errorf("%s %s: missing server key", r.Method, r.URL.Path)
+		}
+		status := http.StatusOK
+		name, ok := contractFixtures[r.URL.Path]
+		switch {
+		case r.URL.Path == "/v2/server/command":
+			if r.Method != http.MethodPost {
+				t.Errorf("command sent with %s", r.Method)
+			}
+			body, _ := io.ReadAll(r.Body)
+			var payload struct {
+				Command string `json:"command"`
+			}
+			if err := json.Unmarshal(body, &payload); err != nil || payload.Command == "" {
+				t.Errorf("malformed command body %q", body)
+			}
+			if strings.HasPrefix(payload.Command, ":restricted") {
+				status, name = http.StatusForbidden, "error_restricted.json"
+			}
+		case r.URL.Path == "/v1/server/limited":
+			status, name, ok = http.StatusTooManyRequests, "error_rate_limited.json", true
+		case !ok:
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write(readFixture(t, name))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newContractClient(t *testing.T, srv *httptest.Server) *Client {
+	t.Helper()
+	c := NewClient("contract-key", WithBaseURL(srv.URL))
+	t.Cleanup(c.Close)
+	return c
+}
+
+func TestRecordedContract(t *testing.T) {
+	for _, route := range contractRoutes {
+		base, _, _ := strings.Cut(route.path, "?")
+		name, ok := contractFixtures[base]
+		if !ok {
+			t.Errorf("%s: no recorded fixture", base)
+			continue
+		}
+		report := VerifyContract(route.path, readFixture(t, name))
+		for _, check := range report.Checks {
+			if check.Status != DoctorOK {
+				t.Errorf("%s: %s", base, report)
+			}
+		}
+	}
+}
+
+func TestContractReplay(t *testing.T) {
+	srv := contractServer(t)
+	c := newContractClient(t, srv)
+	ctx := context.Background()
+
+	report := c.CheckContract(ctx)
+	if !report.OK() || len(report.Checks) != len(contractRoutes)-1 {
+		t.Fatalf("contract check against recorded fixtures:\n%s", report)
+	}
+
+	var wantServer ERLCServerResponse
+	if err := json.Unmarshal(readFixture(t, "server.json"), &wantServer); err != nil {
+		t.Fatal(err)
+	}
+	server, err := c.GetServer(ctx, ServerQueryOptions{
+		Players: true, Staff: true, JoinLogs: true, Queue: true, KillLogs: true,
+		CommandLogs: true, ModCalls: true, EmergencyCalls: true, Vehicles: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*server, wantServer) {
+		t.Errorf("GetServer = %+v, want %+v", *server, wantServer)
+	}
+	if len(server.Players) == 0 || len(server.Vehicles) == 0 || server.Staff == nil || len(server.EmergencyCalls) == 0 {
+		t.Errorf("server fixture decoded without its data sets: %+v", *server)
+	}
+
+	var wantBans ERLCBans
+	if err := json.Unmarshal(readFixture(t, "bans.json"), &wantBans); err != nil {
+		t.Fatal(err)
+	}
+	bans, err := c.GetBans(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(bans, wantBans) {
+		t.Errorf("GetBans = %v, want %v", bans, wantBans)
+	}
+
+	result, err := c.ExecuteCommandWithResult(ctx, ":h contract")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.CommandID != "b1946ac9-2492-4a1b-bd6e-0a1d0e24f2c7" || result.Message != "Success" {
+		t.Errorf("ExecuteCommandWithResult = %+v", result)
+	}
+}
+
+func TestContractErrors(t *testing.T) {
+	srv := contractServer(t)
+	ctx := context.Background()
+
+	err := newContractClient(t, srv).ExecuteCommand(ctx, ":restricted :unadmin all")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("restricted command: got %v, want an APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusForbidden || apiErr.Code != ErrorCodeRestricted || apiErr.CommandID != "0c9d3c1e-58f4-4f2a-9a52-3b6f8f2e1d44" {
+		t.Errorf("restricted command: got %+v", apiErr)
+	}
+
+	err = newContractClient(t, srv).GetJSON(ctx, "/v1/server/limited", new(json.RawMessage))
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("rate limited route: got %v, want an APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusTooManyRequests || apiErr.Code != ErrorCodeRateLimited {
+		t.Errorf("rate limited route: got %+v", apiErr)
+	}
+	if apiErr.RetryAfter == nil || *apiErr.RetryAfter != 2500*time.Millisecond {
+		t.Errorf("rate limited route: retry after %v, want 2.5s", apiErr.RetryAfter)
+	}
+}
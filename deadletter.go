@@ -0,0 +1,57 @@
+package erlcgo
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DeadLetter describes a queued request that failed permanently.
+type DeadLetter struct {
+	// Route is the method and path of the request, e.g. "POST /v2/server/command".
+	Route string
+	// Body is a copy of the request body, if any, so the request can be replayed.
+	Body []byte
+	// Attempts is how many times the request was sent.
+	Attempts int
+	// Err is the last error returned for the request.
+	Err error
+	// FailedAt is when the request was given up on.
+	FailedAt time.Time
+	// Tenant is the tenant the request was made for, if any. See WithTenant.
+	Tenant string
+}
+
+// DeadLetterHandler receives requests that failed after all queue retries.
+type DeadLetterHandler func(DeadLetter)
+
+// retryableFailure reports whether a queued request that failed with err
+// failed in a way retrying could fix: a rate limit, a server or edge error, a
+// dropped connection or a client timeout. Such failures are retried by the
+// queue and dead-lettered once its retries run out. Rejections such as a 4xx,
+// and failures the caller caused by canceling, are only returned to the
+// caller.
+func retryableFailure(err error) bool {
+	if errors.Is(err, ErrCallerCanceled) || errors.Is(err, ErrCallerDeadline) {
+		return false
+	}
+	return IsRetryable(err) || isTransientTransportErr(err) || errors.Is(err, ErrClientTimeout)
+}
+
+func newDeadLetter(req *http.Request, attempts int, err error) DeadLetter {
+	dl := DeadLetter{
+		Route:    req.Method + " " + req.URL.Path,
+		Attempts: attempts,
+		Err:      err,
+		FailedAt: time.Now(),
+		Tenant:   TenantFrom(req.Context()),
+	}
+	if req.GetBody != nil {
+		if rc, bodyErr := req.GetBody(); bodyErr == nil {
+			dl.Body, _ = io.ReadAll(rc)
+			rc.Close()
+		}
+	}
+	return dl
+}
@@ -0,0 +1,131 @@
+package erlcgo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// deadLetterClient returns a queued client, retrying rate limits and
+// failures once, whose requests are answered by status and body, and the
+// dead letters it records.
+func deadLetterClient(t *testing.T, status int, body string) (*Client, func() []DeadLetter) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	var mu sync.Mutex
+	var letters []DeadLetter
+	c := NewClient("key",
+		WithBaseURL(srv.URL),
+		WithRequestQueue(1, time.Millisecond),
+		WithDeadLetterHandler(func(dl DeadLetter) {
+			mu.Lock()
+			defer mu.Unlock()
+			letters = append(letters, dl)
+		}),
+	)
+	c.queue.SetRateLimitRetries(1)
+	c.queue.SetFailureRetries(1)
+	t.Cleanup(c.Close)
+	return c, func() []DeadLetter {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]DeadLetter(nil), letters...)
+	}
+}
+
+func TestDeadLetterAfterRetriesRunOut(t *testing.T) {
+	c, letters := deadLetterClient(t, http.StatusTooManyRequests, `{"code":4001,"message":"rate limited","retry_after":0.05}`)
+	if err := c.ExecuteCommand(context.Background(), ":h hello"); err == nil {
+		t.Fatal("rate limited command succeeded")
+	}
+	got := letters()
+	if len(got) != 1 {
+		t.Fatalf("got %d dead letters, want 1", len(got))
+	}
+	if got[0].Attempts != 2 || got[0].Route != "POST /v2/server/command" || len(got[0].Body) == 0 {
+		t.Errorf("dead letter %+v, want both attempts of the command with its body", got[0])
+	}
+}
+
+func TestDeadLetterAfterServerErrorRetries(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"code":1001,"message":"internal error"}`))
+	}))
+	defer srv.Close()
+
+	for _, retries := range []int{0, 2} {
+		atomic.StoreInt32(&hits, 0)
+		var letters []DeadLetter
+		c := NewClient("key",
+			WithBaseURL(srv.URL),
+			WithRequestQueue(1, time.Millisecond),
+			WithDeadLetterHandler(func(dl DeadLetter) { letters = append(letters, dl) }),
+		)
+		c.queue.SetFailureRetries(retries)
+		err := c.ExecuteCommand(context.Background(), ":h hello")
+		c.Close()
+		if err == nil {
+			t.Fatalf("%d retries: failing command succeeded", retries)
+		}
+		if n := atomic.LoadInt32(&hits); n != int32(retries+1) {
+			t.Errorf("%d retries: server got %d requests, want %d", retries, n, retries+1)
+		}
+		switch {
+		case retries == 0 && len(letters) != 0:
+			t.Errorf("a command that was never retried was dead-lettered: %+v", letters)
+		case retries > 0 && (len(letters) != 1 || letters[0].Attempts != retries+1):
+			t.Errorf("%d retries: dead letters %+v, want one after %d attempts", retries, letters, retries+1)
+		}
+	}
+}
+
+func TestNoDeadLetterForRejections(t *testing.T) {
+	for name, tc := range map[string]struct {
+		status int
+		body   string
+	}{
+		"invalid command": {http.StatusBadRequest, `{"code":3001,"message":"invalid command"}`},
+		"bad server key":  {http.StatusForbidden, `{"code":2002,"message":"invalid server key"}`},
+		"not found":       {http.StatusNotFound, `{"code":0,"message":"not found"}`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c, letters := deadLetterClient(t, tc.status, tc.body)
+			if err := c.ExecuteCommand(context.Background(), ":h hello"); err == nil {
+				t.Fatal("rejected command succeeded")
+			}
+			if got := letters(); len(got) != 0 {
+				t.Errorf("rejection was dead-lettered: %+v", got)
+			}
+		})
+	}
+}
+
+func TestNoDeadLetterForTenantQuota(t *testing.T) {
+	c, letters := deadLetterClient(t, http.StatusOK, `{"message":"Success"}`)
+	c.rateLimiter.SetTenantQuota("guild", 1)
+	ctx := WithTenant(context.Background(), "guild")
+
+	if err := c.ExecuteCommand(ctx, ":h one"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ExecuteCommand(ctx, ":h two"); err == nil {
+		t.Fatal("command over the tenant quota succeeded")
+	}
+	if got := letters(); len(got) != 0 {
+		t.Errorf("tenant quota rejection was dead-lettered: %+v", got)
+	}
+}
@@ -0,0 +1,86 @@
+package erlcgo
+
+import (
+	"context"
+	"encoding/json"
+	"runtime"
+	"sync"
+)
+
+// DecodePool decodes JSON responses on a fixed number of worker goroutines.
+// Sharing one pool between clients bounds how much CPU response decoding can
+// take when many servers are fetched at once, keeping latency predictable for
+// the rest of the process.
+type DecodePool struct {
+	jobs   chan decodeJob
+	closed chan struct{}
+	once   sync.Once
+}
+
+type decodeJob struct {
+	body []byte
+	v    interface{}
+	done chan error
+}
+
+// NewDecodePool starts a pool with the given number of workers. Zero or less
+// uses GOMAXPROCS.
+func NewDecodePool(workers int) *DecodePool {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	p := &DecodePool{jobs: make(chan decodeJob), closed: make(chan struct{})}
+	for i := 0; i < workers; i++ {
+		spawn(p, "decode worker", p.work)
+	}
+	return p
+}
+
+func (p *DecodePool) work() {
+	for {
+		select {
+		case <-p.closed:
+			return
+		case job := <-p.jobs:
+			job.done <- json.Unmarshal(job.body, job.v)
+		}
+	}
+}
+
+// Decode unmarshals body into v on a pool worker, waiting for a free worker
+// until ctx is done. It returns ErrDecodePoolClosed once the pool is closed.
+// A decode a worker has started always runs to completion, so v is never
+// written after Decode returns.
+func (p *DecodePool) Decode(ctx context.Context, body []byte, v interface{}) error {
+	done := make(chan error, 1)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.closed:
+		return ErrDecodePoolClosed
+	case p.jobs <- decodeJob{body: body, v: v, done: done}:
+	}
+	return <-done
+}
+
+// Close stops the workers once they finish their current decode. Later calls
+// to Decode fail with ErrDecodePoolClosed.
+func (p *DecodePool) Close() {
+	p.once.Do(func() { close(p.closed) })
+}
+
+// WithDecodePool decodes responses on a shared DecodePool instead of the
+// calling goroutine.
+func WithDecodePool(pool *DecodePool) ClientOption {
+	return func(c *Client) {
+		c.decodePool = pool
+	}
+}
+
+// decode unmarshals a response body, on the decode pool if one is set.
+func (c *Client) decode(ctx context.Context, body []byte, v interface{}) error {
+	if c.decodePool != nil {
+		return c.decodePool.Decode(ctx, body, v)
+	}
+	return json.Unmarshal(body, v)
+}
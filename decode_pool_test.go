@@ -0,0 +1,146 @@
+package erlcgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestDecodePoolDecodes(t *testing.T) {
+	p := NewDecodePool(2)
+	defer p.Close()
+
+	var got map[string]int
+	if err := p.Decode(context.Background(), []byte(`{"a":1}`), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["a"] != 1 {
+		t.Errorf("got %v", got)
+	}
+	if err := p.Decode(context.Background(), []byte(`{`), &got); err == nil {
+		t.Error("malformed JSON decoded without error")
+	}
+}
+
+func TestDecodePoolAfterClose(t *testing.T) {
+	p := NewDecodePool(1)
+	p.Close()
+	p.Close()
+
+	var v interface{}
+	if err := p.Decode(context.Background(), []byte(`{}`), &v); !errors.Is(err, ErrDecodePoolClosed) {
+		t.Errorf("Decode after Close: got %v, want ErrDecodePoolClosed", err)
+	}
+}
+
+func TestDecodePoolCanceledWhileWaiting(t *testing.T) {
+	// A pool without workers never picks the job up.
+	p := &DecodePool{jobs: make(chan decodeJob), closed: make(chan struct{})}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var v interface{}
+	if err := p.Decode(ctx, []byte(`{}`), &v); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}
+
+// fleetSnapshotBody returns a /v2/server response for a busy server.
+func fleetSnapshotBody(b *testing.B) []byte {
+	b.Helper()
+	resp := ERLCServerResponse{Name: "Fleet", MaxPlayers: 40, CurrentPlayers: 40}
+	for i := 0; i < 40; i++ {
+		name := "Player" + strconv.Itoa(i) + ":" + strconv.Itoa(1000+i)
+		resp.Players = append(resp.Players, ERLCServerPlayer{Player: name, Permission: "Normal", Team: "Civilian", Location: ERLCLocation{LocationX: float64(i), PostalCode: "204", StreetName: "Main Street"}})
+		resp.Vehicles = append(resp.Vehicles, ERLCVehicle{Name: "Falcon", Owner: name, Plate: fmt.Sprintf("P%04d", i), ColorHex: "#000000"})
+	}
+	for i := 0; i < 100; i++ {
+		ts := int64(1704614400 + i)
+		resp.CommandLogs = append(resp.CommandLogs, ERLCCommandLog{Player: "Admin:1", Timestamp: ts, Command: ":h announcement " + strconv.Itoa(i)})
+		resp.JoinLogs = append(resp.JoinLogs, ERLCJoinLog{Join: i%2 == 0, Timestamp: ts, Player: "Player:" + strconv.Itoa(i)})
+		resp.KillLogs = append(resp.KillLogs, ERLCKillLog{Killed: "A:1", Killer: "B:2", Timestamp: ts})
+	}
+	body, err := json.Marshal(resp)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return body
+}
+
+// BenchmarkFleetSnapshot measures the latency of snapshotting 50 servers
+// with decoding on each request's goroutine and on a shared decode pool.
+func BenchmarkFleetSnapshot(b *testing.B) {
+	body := fleetSnapshotBody(b)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	opts := ServerQueryOptions{Players: true, Vehicles: true, CommandLogs: true, JoinLogs: true, KillLogs: true}
+	for _, bench := range []struct {
+		name string
+		opts []ManagerOption
+	}{
+		{"inline", nil},
+		{"pool", []ManagerOption{WithDecodeWorkers(0)}},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			m := NewClientManager([]ClientOption{WithBaseURL(srv.URL)}, bench.opts...)
+			defer m.Close()
+			for i := 0; i < 50; i++ {
+				m.Add("server"+strconv.Itoa(i), "key"+strconv.Itoa(i))
+			}
+			ctx := context.Background()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if snap := m.Snapshot(ctx, opts); len(snap.Errors) > 0 {
+					b.Fatal(snap.Errors)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkDecodePool measures decoding throughput of 50 concurrent
+// responses, without the network, inline and on a pool.
+func BenchmarkDecodePool(b *testing.B) {
+	body := fleetSnapshotBody(b)
+	decodeAll := func(b *testing.B, decode func([]byte, interface{}) error) {
+		var wg sync.WaitGroup
+		for j := 0; j < 50; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				var resp ERLCServerResponse
+				if err := decode(body, &resp); err != nil {
+					b.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	b.Run("inline", func(b *testing.B) {
+		b.SetBytes(int64(50 * len(body)))
+		for i := 0; i < b.N; i++ {
+			decodeAll(b, json.Unmarshal)
+		}
+	})
+	b.Run("pool", func(b *testing.B) {
+		p := NewDecodePool(0)
+		defer p.Close()
+		ctx := context.Background()
+		b.SetBytes(int64(50 * len(body)))
+		for i := 0; i < b.N; i++ {
+			decodeAll(b, func(data []byte, v interface{}) error { return p.Decode(ctx, data, v) })
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package erlcgo
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// WithDefaultTimeoutPerRequest bounds requests whose context has no deadline,
+// such as context.Background(), to d in total, including time spent waiting
+// in the request queue or on rate limits. WithTimeout only bounds the HTTP
+// exchange itself. Routes given a longer timeout with WithRouteTimeouts get
+// that instead, so a slow command is not cut short. Contexts that already
+// have a deadline are left alone.
+//
+// Example:
+//
+//	client := NewClient("your-server-key",
+//	    WithRequestQueue(1, time.Second),
+//	    WithDefaultTimeoutPerRequest(30*time.Second),
+//	)
+func WithDefaultTimeoutPerRequest(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.defaultRequestTimeout = d
+	}
+}
+
+// noDeadlineWarnings remembers the routes already reported as called without
+// a deadline, so each is reported once.
+type noDeadlineWarnings struct {
+	seen sync.Map
+}
+
+// applyDefaultDeadline gives req the default per-request timeout, or its
+// route's timeout if longer, when its context has no deadline. Without a
+// default, it publishes a LifecycleNoDeadline event the first time a route is
+// called without one, except by the library's own pollers. The returned
+// function releases the timeout.
+func (c *Client) applyDefaultDeadline(req *http.Request) (*http.Request, context.CancelFunc) {
+	if _, ok := req.Context().Deadline(); ok {
+		return req, func() {}
+	}
+	if c.defaultRequestTimeout > 0 {
+		timeout := c.defaultRequestTimeout
+		if routeTimeout, ok := c.routeTimeout(req.URL.Path); ok && routeTimeout > timeout {
+			timeout = routeTimeout
+		}
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		return req.WithContext(ctx), cancel
+	}
+	if isPoll(req.Context()) {
+		return req, func() {}
+	}
+	route := req.Method + " " + req.URL.Path
+	if _, warned := c.noDeadline.seen.LoadOrStore(route, struct{}{}); !warned {
+		c.bus.publish(LifecycleEvent{Type: LifecycleNoDeadline, Route: route, Tenant: TenantFrom(req.Context())})
+	}
+	return req, func() {}
+}
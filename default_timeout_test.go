@@ -0,0 +1,70 @@
+package erlcgo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDefaultDeadlineUsesLongerRouteTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/server/command" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"message":"Success"}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient("key",
+		WithBaseURL(srv.URL),
+		WithDefaultTimeoutPerRequest(50*time.Millisecond),
+		WithRouteTimeouts(map[string]time.Duration{"/server/command": 5 * time.Second}),
+	)
+	defer c.Close()
+
+	if err := c.ExecuteCommand(context.Background(), ":h slow"); err != nil {
+		t.Errorf("command with a 5s route timeout: %v", err)
+	}
+}
+
+func TestNoDeadlineWarningSkipsPollers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"Name":"Test"}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient("key", WithBaseURL(srv.URL))
+	defer c.Close()
+	events, stop := c.Events()
+	defer stop()
+	warnings := func() int {
+		n := 0
+		for {
+			select {
+			case e := <-events:
+				if e.Type == LifecycleNoDeadline {
+					n++
+				}
+			default:
+				return n
+			}
+		}
+	}
+
+	if _, err := c.GetServer(pollContext(context.Background())); err != nil {
+		t.Fatal(err)
+	}
+	if n := warnings(); n != 0 {
+		t.Errorf("poller request: got %d no-deadline warnings, want none", n)
+	}
+	if _, err := c.GetServer(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := warnings(); n != 1 {
+		t.Errorf("caller request: got %d no-deadline warnings, want 1", n)
+	}
+}
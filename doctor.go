@@ -0,0 +1,203 @@
+package erlcgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DoctorStatus is the outcome of a single Doctor check.
+type DoctorStatus string
+
+const (
+	DoctorOK   DoctorStatus = "ok"
+	DoctorWarn DoctorStatus = "warn"
+	DoctorFail DoctorStatus = "fail"
+	DoctorSkip DoctorStatus = "skip"
+)
+
+// DoctorCheck is the result of one Doctor check.
+type DoctorCheck struct {
+	Name   string
+	Status DoctorStatus
+	Detail string
+}
+
+// DoctorReport is the structured result of Client.Doctor.
+type DoctorReport struct {
+	Checks []DoctorCheck
+}
+
+// OK reports whether no check failed. Warnings do not count as failures.
+func (r *DoctorReport) OK() bool {
+	for _, c := range r.Checks {
+		if c.Status == DoctorFail {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *DoctorReport) String() string {
+	var b strings.Builder
+	for _, c := range r.Checks {
+		fmt.Fprintf(&b, "[%s] %s: %s\n", c.Status, c.Name, c.Detail)
+	}
+	return b.String()
+}
+
+func (r *DoctorReport) add(name string, status DoctorStatus, format string, args ...interface{}) {
+	r.Checks = append(r.Checks, DoctorCheck{Name: name, Status: status, Detail: fmt.Sprintf(format, args...)})
+}
+
+// Doctor runs a series of self-tests against the API and the client's own
+// configuration: key validity, clock skew, latency, rate limit headroom, cache
+// reachability and queue health. It makes one request to the API, bypassing
+// the queue and cache so it reflects the live state. Attach the report to
+// support requests.
+//
+// Example:
+//
+//	report := client.Doctor(ctx)
+//	fmt.Print(report)
+//	if !report.OK() {
+//	    os.Exit(1)
+//	}
+func (c *Client) Doctor(ctx context.Context) *DoctorReport {
+	report := &DoctorReport{}
+	c.doctorProbe(ctx, report)
+	c.doctorCache(ctx, report)
+	c.doctorQueue(report)
+	return report
+}
+
+func (c *Client) doctorProbe(ctx context.Context, report *DoctorReport) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/v2/server", nil)
+	if err != nil {
+		report.add("connectivity", DoctorFail, "could not build request: %v", err)
+		return
+	}
+	req.Header.Set("Server-Key", c.apiKey)
+	if c.globalAPIKey != "" {
+		req.Header.Set("Authorization", c.globalAPIKey)
+	}
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	latency := time.Since(start)
+	if err != nil {
+		report.add("connectivity", DoctorFail, "request to %s failed: %v", c.baseURL, err)
+		return
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	report.add("connectivity", DoctorOK, "reached %s", c.baseURL)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300 && !isErrorEnvelope(body):
+		report.add("server key", DoctorOK, "accepted")
+	case resp.StatusCode == http.StatusTooManyRequests:
+		report.add("server key", DoctorWarn, "could not verify, currently rate limited")
+	default:
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		parseErrorBody(apiErr, body, resp.Header.Get("Content-Type"))
+		if errors.Is(apiErr, ErrAuth) {
+			report.add("server key", DoctorFail, "%s", GetFriendlyErrorMessage(apiErr))
+		} else {
+			report.add("server key", DoctorWarn, "unexpected status %d: %s", resp.StatusCode, GetFriendlyErrorMessage(apiErr))
+		}
+	}
+
+	if date, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+		// Date has one-second resolution and was stamped mid-request.
+		skew := time.Until(date.Add(latency / 2))
+		if skew < 0 {
+			skew = -skew
+		}
+		switch {
+		case skew > time.Second*30:
+			report.add("clock skew", DoctorFail, "local clock is off by %s; rate limit resets will be misjudged", skew.Round(time.Second))
+		case skew > time.Second*3:
+			report.add("clock skew", DoctorWarn, "local clock is off by %s", skew.Round(time.Second))
+		default:
+			report.add("clock skew", DoctorOK, "within %s", skew.Round(time.Second))
+		}
+	} else {
+		report.add("clock skew", DoctorSkip, "server sent no Date header")
+	}
+
+	switch {
+	case latency > c.httpClient.Timeout/2 && c.httpClient.Timeout > 0:
+		report.add("latency", DoctorWarn, "%s, more than half the %s timeout", latency.Round(time.Millisecond), c.httpClient.Timeout)
+	case latency > time.Second*2:
+		report.add("latency", DoctorWarn, "%s", latency.Round(time.Millisecond))
+	default:
+		report.add("latency", DoctorOK, "%s", latency.Round(time.Millisecond))
+	}
+
+	if rl := parseRateLimitHeaders(resp.Header); rl != nil && rl.Limit > 0 {
+		if rl.Remaining*5 < rl.Limit {
+			report.add("rate limit headroom", DoctorWarn, "%d of %d requests left in bucket %q", rl.Remaining, rl.Limit, rl.Bucket)
+		} else {
+			report.add("rate limit headroom", DoctorOK, "%d of %d requests left in bucket %q", rl.Remaining, rl.Limit, rl.Bucket)
+		}
+	} else {
+		report.add("rate limit headroom", DoctorSkip, "no rate limit headers in response")
+	}
+}
+
+func (c *Client) doctorCache(ctx context.Context, report *DoctorReport) {
+	if c.cache == nil || !c.cache.Enabled {
+		report.add("cache", DoctorSkip, "caching disabled")
+		return
+	}
+	backend := c.cache.backend()
+	if backend == nil {
+		report.add("cache", DoctorSkip, "cache not created yet")
+		return
+	}
+	if _, ok := c.cache.Cache.(*MemoryCache); ok && c.cache.CacheCtx == nil {
+		report.add("cache", DoctorOK, "in-memory cache")
+		return
+	}
+
+	key := c.cache.Prefix + "doctor:" + newRandomID()
+	start := time.Now()
+	if err := backend.Set(ctx, key, "ok", time.Minute); err != nil {
+		report.add("cache", DoctorFail, "write failed: %v", err)
+		return
+	}
+	if _, ok, err := backend.Get(ctx, key); err != nil || !ok {
+		report.add("cache", DoctorFail, "read back failed (found=%v): %v", ok, err)
+		return
+	}
+	backend.Delete(ctx, key)
+	report.add("cache", DoctorOK, "round trip in %s", time.Since(start).Round(time.Millisecond))
+}
+
+func (c *Client) doctorQueue(report *DoctorReport) {
+	if c.queue == nil {
+		report.add("queue", DoctorSkip, "request queue not configured")
+		return
+	}
+	c.queue.mu.Lock()
+	running := c.queue.running
+	c.queue.mu.Unlock()
+	if !running {
+		report.add("queue", DoctorFail, "queue is not running; queued requests will block")
+		return
+	}
+	if until, paused := c.queue.Paused(); paused {
+		report.add("queue", DoctorWarn, "paused for rate limits until %s", until.Format(time.RFC3339))
+		return
+	}
+	if depth := c.queue.Depth(); depth > 0 {
+		report.add("queue", DoctorWarn, "%d requests waiting", depth)
+		return
+	}
+	report.add("queue", DoctorOK, "running with %d workers", c.queue.workers)
+}
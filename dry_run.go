@@ -0,0 +1,101 @@
+package erlcgo
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// maxDryRunLog is how many commands a client keeps in its dry-run log.
+const maxDryRunLog = 1000
+
+// DryRunEntry is a command that was recorded instead of sent.
+type DryRunEntry struct {
+	Command string
+	Tenant  string
+	Tags    []string // see WithCommandTags
+	Time    time.Time
+}
+
+type dryRunKey struct{}
+
+// DryRunContext returns a context under which ExecuteCommand records
+// commands instead of sending them, as WithDryRun does for a whole client.
+//
+// Example:
+//
+//	err := client.ExecuteCommand(erlcgo.DryRunContext(ctx), ":kick Player1 Testing")
+func DryRunContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+func isDryRun(ctx context.Context) bool {
+	on, _ := ctx.Value(dryRunKey{}).(bool)
+	return on
+}
+
+// WithDryRun makes ExecuteCommand and its variants validate and record
+// commands without sending them, returning success. This allows moderation
+// automations to be tested against a production server key without kicking
+// anyone. Reads are unaffected. onCommand, if not nil, is called with each
+// recorded command; the latest commands are also kept in DryRunLog.
+//
+// Example:
+//
+//	client := NewClient("your-server-key",
+//	    WithDryRun(func(e DryRunEntry) {
+//	        log.Printf("dry run: %s", e.Command)
+//	    }),
+//	)
+func WithDryRun(onCommand func(DryRunEntry)) ClientOption {
+	return func(c *Client) {
+		c.dryRun.enabled = true
+		c.dryRun.onCommand = onCommand
+	}
+}
+
+// DryRunLog returns the commands recorded in dry-run mode, oldest first.
+func (c *Client) DryRunLog() []DryRunEntry {
+	if c.dryRun == nil {
+		return nil
+	}
+	c.dryRun.mu.Lock()
+	defer c.dryRun.mu.Unlock()
+	return append([]DryRunEntry(nil), c.dryRun.entries...)
+}
+
+type dryRunLog struct {
+	enabled   bool
+	onCommand func(DryRunEntry)
+
+	mu      sync.Mutex
+	entries []DryRunEntry
+}
+
+// dryRunning reports whether commands sent with ctx are recorded instead of
+// sent.
+func (c *Client) dryRunning(ctx context.Context) bool {
+	return c.dryRun != nil && (c.dryRun.enabled || isDryRun(ctx))
+}
+
+// recordDryRun records command if the client or ctx is in dry-run mode and
+// reports whether it did.
+func (c *Client) recordDryRun(ctx context.Context, command string) bool {
+	if !c.dryRunning(ctx) {
+		return false
+	}
+	entry := DryRunEntry{Command: command, Tenant: c.tenantOf(ctx), Tags: CommandTagsFrom(ctx), Time: time.Now()}
+
+	d := c.dryRun
+	d.mu.Lock()
+	if len(d.entries) >= maxDryRunLog {
+		d.entries = append(d.entries[:0], d.entries[1:]...)
+	}
+	d.entries = append(d.entries, entry)
+	d.mu.Unlock()
+
+	if d.onCommand != nil {
+		d.onCommand(entry)
+	}
+	return true
+}
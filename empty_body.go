@@ -0,0 +1,59 @@
+package erlcgo
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+)
+
+// EmptyBodyPolicy controls how a successful response with no JSON payload
+// (an empty body, such as a 204, or a literal null) is decoded.
+type EmptyBodyPolicy int
+
+const (
+	// EmptyBodyError reports a decode error, as the client always has.
+	EmptyBodyError EmptyBodyPolicy = iota
+	// EmptyBodyNil leaves the destination at its zero value, so slices are nil.
+	EmptyBodyNil
+	// EmptyBodyEmpty sets slice destinations to an empty, non-nil slice, so an
+	// empty body behaves exactly like "[]". Other destinations are left at
+	// their zero value.
+	EmptyBodyEmpty
+)
+
+// ErrEmptyBody is returned for empty responses under EmptyBodyError.
+var ErrEmptyBody = errors.New("erlc: empty response body")
+
+// WithEmptyBodyPolicy sets how empty successful responses are decoded.
+//
+// Example:
+//
+//	client := erlcgo.NewClient(apiKey, erlcgo.WithEmptyBodyPolicy(erlcgo.EmptyBodyEmpty))
+//	var logs []erlcgo.ERLCKillLog
+//	err := client.GetJSON(ctx, "/v1/server/killlogs", &logs) // logs is non-nil on success
+func WithEmptyBodyPolicy(policy EmptyBodyPolicy) ClientOption {
+	return func(c *Client) {
+		c.emptyBodyPolicy = policy
+	}
+}
+
+func isEmptyBody(body []byte) bool {
+	body = bytes.TrimSpace(body)
+	return len(body) == 0 || bytes.Equal(body, []byte("null"))
+}
+
+// decodeEmptyBody applies the client's EmptyBodyPolicy to v.
+func (c *Client) decodeEmptyBody(v interface{}) error {
+	switch c.emptyBodyPolicy {
+	case EmptyBodyNil:
+		return nil
+	case EmptyBodyEmpty:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() == reflect.Ptr && !rv.IsNil() && rv.Elem().Kind() == reflect.Slice {
+			rv.Elem().Set(reflect.MakeSlice(rv.Elem().Type(), 0, 0))
+		}
+		return nil
+	default:
+		return ErrEmptyBody
+	}
+}
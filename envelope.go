@@ -0,0 +1,45 @@
+package erlcgo
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// isErrorEnvelope reports whether a successful response body is actually an
+// error in disguise. The PRC API occasionally answers failures with HTTP 200 and
+// a body such as {"code":3002,"message":"..."}; decoding that into a response
+// struct would silently produce zero values.
+//
+// A body is treated as an error only if it is an object with a non-zero numeric
+// code, a message, and no fields other than those found on APIError.
+func isErrorEnvelope(body []byte) bool {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return false
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &fields); err != nil {
+		return false
+	}
+	if _, ok := fields["message"]; !ok {
+		return false
+	}
+	rawCode, ok := fields["code"]
+	if !ok {
+		return false
+	}
+	var code int
+	if err := json.Unmarshal(rawCode, &code); err != nil || code == 0 {
+		return false
+	}
+
+	for key := range fields {
+		switch key {
+		case "code", "message", "commandId":
+		default:
+			return false
+		}
+	}
+	return true
+}
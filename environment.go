@@ -0,0 +1,29 @@
+package erlcgo
+
+import (
+	"context"
+
+	"github.com/bmrgcorp/erlcgo/commands"
+)
+
+// SetServerTime sets the in-game hour, from 0 to 23. Hours outside that range
+// are rejected with a CommandValidationError before anything is sent.
+//
+// Example:
+//
+//	err := client.SetServerTime(ctx, 14)
+func (c *Client) SetServerTime(ctx context.Context, hour int) error {
+	return c.Execute(ctx, commands.SetTime(hour))
+}
+
+// SetWeather changes the in-game weather. Values other than the
+// commands.Weather constants are rejected with a CommandValidationError
+// before anything is sent; use commands.ParseWeather to convert strings from
+// configuration.
+//
+// Example:
+//
+//	err := client.SetWeather(ctx, commands.WeatherFog)
+func (c *Client) SetWeather(ctx context.Context, w commands.Weather) error {
+	return c.Execute(ctx, commands.SetWeather(w))
+}
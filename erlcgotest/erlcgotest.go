@@ -0,0 +1,32 @@
+// Package erlcgotest provides helpers for testing code built on erlcgo.
+package erlcgotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bmrgcorp/erlcgo"
+)
+
+// LeakTimeout is how long AssertNoLeaks waits for a client's background
+// goroutines to exit.
+var LeakTimeout = time.Second
+
+// AssertNoLeaks fails t if the client's background goroutines are still
+// running after LeakTimeout. Call it after closing the client and canceling
+// any subscription contexts.
+//
+// Example:
+//
+//	client := erlcgo.NewClient(key)
+//	defer erlcgotest.AssertNoLeaks(t, client)
+//	defer client.Close()
+func AssertNoLeaks(t testing.TB, c *erlcgo.Client) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), LeakTimeout)
+	defer cancel()
+	if err := c.LeakCheck(ctx); err != nil {
+		t.Error(err)
+	}
+}
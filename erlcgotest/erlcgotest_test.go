@@ -0,0 +1,51 @@
+package erlcgotest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bmrgcorp/erlcgo"
+)
+
+// recorder is a testing.TB that records failures instead of reporting them.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Error(args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprint(args...))
+}
+
+func newClient() *erlcgo.Client {
+	return erlcgo.NewClient("key",
+		erlcgo.WithRequestQueue(2, time.Millisecond),
+		erlcgo.WithCache(&erlcgo.CacheConfig{Enabled: true, TTL: time.Second, Cache: erlcgo.NewMemoryCache()}),
+	)
+}
+
+func TestAssertNoLeaksAfterClose(t *testing.T) {
+	c := newClient()
+	c.Close()
+	AssertNoLeaks(t, c)
+}
+
+func TestAssertNoLeaksReportsRunningGoroutines(t *testing.T) {
+	defer func(d time.Duration) { LeakTimeout = d }(LeakTimeout)
+	LeakTimeout = 50 * time.Millisecond
+
+	c := newClient()
+	defer c.Close()
+	if len(c.ActiveGoroutines()) == 0 {
+		t.Fatal("client started no background goroutines")
+	}
+
+	r := &recorder{TB: t}
+	AssertNoLeaks(r, c)
+	if len(r.errors) != 1 {
+		t.Fatalf("got %d failures for an open client, want 1", len(r.errors))
+	}
+}
@@ -0,0 +1,104 @@
+package erlcgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxMessagePreview caps how much of a non-JSON error body is copied into
+// APIError.Message. The full body stays in APIError.Body.
+const maxMessagePreview = 256
+
+var htmlTitle = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// previewBody renders an error body that is not a PRC error envelope as a
+// short, printable message. HTML pages from proxies are reduced to their
+// title, binary bodies to a size and type, and everything else is truncated.
+func previewBody(body []byte, contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "text/html" || looksLikeHTML(body) {
+		if m := htmlTitle.FindSubmatch(body); m != nil {
+			if title := strings.Join(strings.Fields(string(m[1])), " "); title != "" {
+				return fmt.Sprintf("HTML response: %s", truncatePreview(title))
+			}
+		}
+		return fmt.Sprintf("HTML response (%d bytes)", len(body))
+	}
+	if !isPrintable(body) {
+		if mediaType == "" {
+			mediaType = "unknown content type"
+		}
+		return fmt.Sprintf("binary response (%d bytes, %s)", len(body), mediaType)
+	}
+	return truncatePreview(strings.TrimSpace(string(body)))
+}
+
+// parseErrorBody fills apiErr's code, message and command ID from an error
+// body. Codes sent as numeric strings are accepted. A body that does not
+// decode as a whole, such as one with a field of the wrong type, leaves the
+// code unknown and uses a preview of the body as the message, so fields from
+// a partial decode are never mixed in. Overlong messages are truncated.
+func parseErrorBody(apiErr *APIError, body []byte, contentType string) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		apiErr.Message = fmt.Sprintf("unknown error (status %d)", apiErr.StatusCode)
+		return
+	}
+	var envelope struct {
+		Code      json.Number `json:"code"`
+		Message   string      `json:"message"`
+		CommandID string      `json:"commandId"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		apiErr.Message = previewBody(body, contentType)
+		return
+	}
+	if envelope.Code != "" {
+		if code, err := envelope.Code.Int64(); err == nil && code >= math.MinInt32 && code <= math.MaxInt32 {
+			apiErr.Code = ErrorCode(code)
+		}
+	}
+	apiErr.Message = truncatePreview(envelope.Message)
+	apiErr.CommandID = truncatePreview(envelope.CommandID)
+	if apiErr.Code == ErrorCodeUnknown && apiErr.Message == "" {
+		apiErr.Message = previewBody(body, contentType)
+	}
+}
+
+// truncatePreview shortens s to maxMessagePreview bytes without splitting a
+// UTF-8 sequence.
+func truncatePreview(s string) string {
+	if len(s) <= maxMessagePreview {
+		return s
+	}
+	cut := maxMessagePreview
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s... (%d bytes total)", s[:cut], len(s))
+}
+
+func looksLikeHTML(body []byte) bool {
+	head := strings.ToLower(strings.TrimSpace(string(body[:min(len(body), 64)])))
+	return strings.HasPrefix(head, "<!doctype html") || strings.HasPrefix(head, "<html")
+}
+
+// isPrintable reports whether body is valid UTF-8 text without control
+// characters other than whitespace.
+func isPrintable(body []byte) bool {
+	if !utf8.Valid(body) {
+		return false
+	}
+	for _, r := range string(body) {
+		if unicode.IsControl(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
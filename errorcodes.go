@@ -0,0 +1,140 @@
+package erlcgo
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// ErrorCode is an error code documented by the PRC API.
+type ErrorCode int
+
+const (
+	ErrorCodeUnknown          ErrorCode = 0
+	ErrorCodeCommunication    ErrorCode = 1001
+	ErrorCodeInternal         ErrorCode = 1002
+	ErrorCodeNoServerKey      ErrorCode = 2000
+	ErrorCodeBadServerKey     ErrorCode = 2001
+	ErrorCodeInvalidServerKey ErrorCode = 2002
+	ErrorCodeInvalidAPIKey    ErrorCode = 2003
+	ErrorCodeBannedServerKey  ErrorCode = 2004
+	ErrorCodeInvalidCommand   ErrorCode = 3001
+	ErrorCodeServerOffline    ErrorCode = 3002
+	ErrorCodeRateLimited      ErrorCode = 4001
+	ErrorCodeRestricted       ErrorCode = 4002
+	ErrorCodeProhibitedMsg    ErrorCode = 4003
+	ErrorCodeAccessRestricted ErrorCode = 9998
+	ErrorCodeModuleOutdated   ErrorCode = 9999
+)
+
+// ErrorCategory groups error codes by what the caller can do about them.
+type ErrorCategory string
+
+const (
+	CategoryUnknown     ErrorCategory = "unknown"
+	CategoryUpstream    ErrorCategory = "upstream"    // PRC or the game server is unavailable
+	CategoryAuth        ErrorCategory = "auth"        // keys are missing, invalid or banned
+	CategoryCommand     ErrorCategory = "command"     // the command could not be run
+	CategoryRateLimit   ErrorCategory = "ratelimit"   // too many requests
+	CategoryRestricted  ErrorCategory = "restricted"  // the command or content is not allowed
+	CategoryMaintenance ErrorCategory = "maintenance" // the server needs operator action
+)
+
+// Sentinel errors matched by APIError via errors.Is, one per category.
+var (
+	ErrUpstream    = errors.New("erlc: upstream unavailable")
+	ErrAuth        = errors.New("erlc: authentication failed")
+	ErrCommand     = errors.New("erlc: command failed")
+	ErrRateLimit   = errors.New("erlc: rate limited")
+	ErrRestricted  = errors.New("erlc: restricted")
+	ErrMaintenance = errors.New("erlc: maintenance required")
+)
+
+type errorCodeInfo struct {
+	category  ErrorCategory
+	retryable bool
+	message   string
+}
+
+var errorCodes = map[ErrorCode]errorCodeInfo{
+	ErrorCodeUnknown:          {CategoryUnknown, false, "An unknown error occurred. If this persists, please contact PRC support."},
+	ErrorCodeCommunication:    {CategoryUpstream, true, "Failed to communicate with the game server. Please try again in a few minutes."},
+	ErrorCodeInternal:         {CategoryUpstream, true, "An internal system error occurred. Please try again later."},
+	ErrorCodeNoServerKey:      {CategoryAuth, false, "No server key provided. Please configure your server key."},
+	ErrorCodeBadServerKey:     {CategoryAuth, false, "Invalid server key. Please check your configuration."},
+	ErrorCodeInvalidServerKey: {CategoryAuth, false, "Invalid server key. Please check your configuration."},
+	ErrorCodeInvalidAPIKey:    {CategoryAuth, false, "Invalid API key. Please check your configuration."},
+	ErrorCodeBannedServerKey:  {CategoryAuth, false, "This server key has been banned from accessing the API."},
+	ErrorCodeInvalidCommand:   {CategoryCommand, false, "Invalid command format. Please check your input."},
+	ErrorCodeServerOffline:    {CategoryCommand, false, "The server is currently offline (no players). Please try again when players are in the server."},
+	ErrorCodeRateLimited:      {CategoryRateLimit, true, "You are being rate limited. Please wait a moment and try again."},
+	ErrorCodeRestricted:       {CategoryRestricted, false, "This command is restricted and cannot be executed."},
+	ErrorCodeProhibitedMsg:    {CategoryRestricted, false, "The message you're trying to send contains prohibited content."},
+	ErrorCodeAccessRestricted: {CategoryRestricted, false, "Access to this resource is restricted."},
+	ErrorCodeModuleOutdated:   {CategoryMaintenance, false, "The server module is out of date. Please kick all players and try again."},
+}
+
+var categorySentinels = map[ErrorCategory]error{
+	CategoryUpstream:    ErrUpstream,
+	CategoryAuth:        ErrAuth,
+	CategoryCommand:     ErrCommand,
+	CategoryRateLimit:   ErrRateLimit,
+	CategoryRestricted:  ErrRestricted,
+	CategoryMaintenance: ErrMaintenance,
+}
+
+// Known reports whether the code is documented by the PRC API.
+func (c ErrorCode) Known() bool {
+	_, ok := errorCodes[c]
+	return ok
+}
+
+// Category returns the category of the code, or CategoryUnknown.
+func (c ErrorCode) Category() ErrorCategory {
+	if info, ok := errorCodes[c]; ok {
+		return info.category
+	}
+	return CategoryUnknown
+}
+
+// Retryable reports whether a request failing with this code may succeed if retried later.
+func (c ErrorCode) Retryable() bool {
+	return errorCodes[c].retryable
+}
+
+// FriendlyMessage returns a human-readable description of the code, or an
+// empty string if the code is not documented.
+func (c ErrorCode) FriendlyMessage() string {
+	return errorCodes[c].message
+}
+
+func (c ErrorCode) String() string {
+	return strconv.Itoa(int(c))
+}
+
+// Is lets errors.Is match an APIError against the sentinel for its category,
+// for example errors.Is(err, ErrAuth). Cloudflare errors match ErrEdgeOutage
+// and ErrUpstream.
+func (e *APIError) Is(target error) bool {
+	if (target == ErrEdgeOutage || target == ErrUpstream) && e.IsEdgeError() {
+		return true
+	}
+	sentinel, ok := categorySentinels[e.Code.Category()]
+	return ok && sentinel == target
+}
+
+// IsRetryable reports whether err is an APIError whose code or status suggests
+// the request may succeed if retried later.
+func IsRetryable(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if apiErr.IsEdgeError() {
+		return true
+	}
+	if apiErr.Code.Known() && apiErr.Code != ErrorCodeUnknown {
+		return apiErr.Code.Retryable()
+	}
+	return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= http.StatusInternalServerError
+}
@@ -0,0 +1,125 @@
+package erlcgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// ErrQueuedRateLimited is returned when a request that went through the request
+// queue was rejected with HTTP 429. RetryAfter is how long the caller should wait
+// before trying again; it is zero if the API did not say.
+type ErrQueuedRateLimited struct {
+	RetryAfter time.Duration
+	Err        *APIError
+}
+
+func (e *ErrQueuedRateLimited) Error() string {
+	return fmt.Sprintf("queued request rate limited, retry after %s: %v", e.RetryAfter, e.Err)
+}
+
+// Unwrap returns the underlying APIError.
+func (e *ErrQueuedRateLimited) Unwrap() error {
+	return e.Err
+}
+
+// ErrClientClosed is returned for requests made after Client.Close. Requests
+// that were queued or in flight when the client closed fail as canceled.
+var ErrClientClosed = errors.New("erlc: client closed")
+
+// ErrDecodePoolClosed is returned by DecodePool.Decode after the pool is closed.
+var ErrDecodePoolClosed = errors.New("erlc: decode pool closed")
+
+// ErrForeignHost is returned by DoRequest for a request to a scheme or host
+// other than the client's base URL, which would receive the server key.
+var ErrForeignHost = errors.New("erlc: request is not for the API host")
+
+// QueueError is returned when a request that went through the request queue
+// failed. Err holds the underlying failure, so errors.As still finds an APIError.
+type QueueError struct {
+	Route    string
+	Attempts int
+	Err      error
+}
+
+func (e *QueueError) Error() string {
+	return fmt.Sprintf("queued request %s failed after %d attempt(s): %v", e.Route, e.Attempts, e.Err)
+}
+
+func (e *QueueError) Unwrap() error {
+	return e.Err
+}
+
+// CacheFallbackError is returned when a request failed and serving stale data
+// from the cache also failed. Both errors are kept in the chain.
+type CacheFallbackError struct {
+	Err         error // The original request error
+	FallbackErr error // Why the cached value could not be used
+}
+
+func (e *CacheFallbackError) Error() string {
+	return fmt.Sprintf("%v (stale cache fallback failed: %v)", e.Err, e.FallbackErr)
+}
+
+func (e *CacheFallbackError) Unwrap() []error {
+	return []error{e.Err, e.FallbackErr}
+}
+
+// Reasons carried by ContextError, for use with errors.Is.
+var (
+	// ErrClientTimeout means the client's own HTTP timeout (WithTimeout) elapsed.
+	ErrClientTimeout = errors.New("erlc: client timeout exceeded")
+	// ErrCallerCanceled means the caller's context was canceled.
+	ErrCallerCanceled = errors.New("erlc: canceled by caller")
+	// ErrCallerDeadline means the caller's context deadline passed during the request.
+	ErrCallerDeadline = errors.New("erlc: caller deadline exceeded")
+	// ErrQueueWaitTimeout means the caller's deadline passed before the request left the queue.
+	ErrQueueWaitTimeout = errors.New("erlc: deadline exceeded while waiting in queue")
+)
+
+// ContextError explains why a request was cut short. Reason is one of
+// ErrClientTimeout, ErrCallerCanceled, ErrCallerDeadline or ErrQueueWaitTimeout,
+// and Err is the original error, so errors.Is(err, context.DeadlineExceeded)
+// keeps working.
+type ContextError struct {
+	Reason error
+	Err    error
+}
+
+func (e *ContextError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Reason, e.Err)
+}
+
+func (e *ContextError) Unwrap() []error {
+	return []error{e.Reason, e.Err}
+}
+
+// classifyTransportErr attributes a failed HTTP round trip to the caller's
+// context or the client's own timeout. Other errors are returned unchanged.
+func classifyTransportErr(ctx context.Context, err error) error {
+	switch ctx.Err() {
+	case context.Canceled:
+		return &ContextError{Reason: ErrCallerCanceled, Err: err}
+	case context.DeadlineExceeded:
+		return &ContextError{Reason: ErrCallerDeadline, Err: err}
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return &ContextError{Reason: ErrClientTimeout, Err: err}
+	}
+	return err
+}
+
+// classifyQueueErr attributes a context error returned before a queued
+// request was executed.
+func classifyQueueErr(err error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return &ContextError{Reason: ErrQueueWaitTimeout, Err: err}
+	case errors.Is(err, context.Canceled):
+		return &ContextError{Reason: ErrCallerCanceled, Err: err}
+	}
+	return err
+}
@@ -0,0 +1,128 @@
+package erlcgo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// hashFields returns a stable identifier for the given field values.
+func hashFields(fields ...string) string {
+	h := sha256.New()
+	for _, f := range fields {
+		h.Write([]byte(f))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// observedField formats the poll time of a change for hashFields. Changes
+// worked out by diffing polls carry no timestamp of their own, so the poll
+// that saw them tells a rejoin or a respawn apart from the original.
+func observedField(at time.Time) string {
+	if at.IsZero() {
+		return ""
+	}
+	return strconv.FormatInt(at.UnixNano(), 10)
+}
+
+// ID returns a stable content hash of the entry, usable for deduplication
+// across restarts and overlapping polls.
+func (l ERLCCommandLog) ID() string {
+	return hashFields("command", l.Player, strconv.FormatInt(l.Timestamp, 10), l.Command)
+}
+
+// ID returns a stable content hash of the entry.
+func (l ERLCModCallLog) ID() string {
+	return hashFields("modcall", l.Caller, l.Moderator, strconv.FormatInt(l.Timestamp, 10))
+}
+
+// ID returns a stable content hash of the entry.
+func (l ERLCKillLog) ID() string {
+	return hashFields("kill", l.Killer, l.Killed, strconv.FormatInt(l.Timestamp, 10))
+}
+
+// ID returns a stable content hash of the entry.
+func (l ERLCJoinLog) ID() string {
+	return hashFields("join", l.Player, strconv.FormatBool(l.Join), strconv.FormatInt(l.Timestamp, 10))
+}
+
+// ID returns a stable content hash of the call. Call numbers are reused across
+// server restarts, so the start time is included.
+func (ec ERLCEmergencyCall) ID() string {
+	return hashFields("emergencycall", strconv.Itoa(ec.CallNumber), strconv.FormatInt(ec.StartedAt, 10), ec.Team, strconv.FormatInt(ec.Caller, 10))
+}
+
+// ID returns a content hash of the vehicle's identity and, for vehicles
+// delivered by a subscription, the poll that first saw it, so a respawned
+// vehicle gets a new ID.
+func (v ERLCVehicle) ID() string {
+	return hashFields("vehicle", v.Owner, v.Name, v.Plate, observedField(v.ObservedAt))
+}
+
+// ID returns a content hash of the player change and the poll that saw it, so
+// a player who rejoins gets a new ID.
+func (e PlayerEvent) ID() string {
+	return hashFields("player", e.Type, e.Player.Player, observedField(e.ObservedAt))
+}
+
+// ID returns a content hash of the queue change and the poll that saw it.
+func (e QueueEvent) ID() string {
+	return hashFields("queue", e.Type, strconv.FormatInt(e.UserID, 10), observedField(e.ObservedAt))
+}
+
+// newEvent builds an Event whose ID is derived from the IDs of its entries,
+// so the same batch of entries always yields the same event ID.
+func newEvent(eventType EventType, data interface{}) Event {
+	var ids []string
+	switch entries := data.(type) {
+	case []PlayerEvent:
+		for _, e := range entries {
+			ids = append(ids, e.ID())
+		}
+	case []ERLCCommandLog:
+		for _, e := range entries {
+			ids = append(ids, e.ID())
+		}
+	case []ERLCModCallLog:
+		for _, e := range entries {
+			ids = append(ids, e.ID())
+		}
+	case []ERLCKillLog:
+		for _, e := range entries {
+			ids = append(ids, e.ID())
+		}
+	case []ERLCJoinLog:
+		for _, e := range entries {
+			ids = append(ids, e.ID())
+		}
+	case []ERLCVehicle:
+		for _, e := range entries {
+			ids = append(ids, e.ID())
+		}
+	case []ERLCEmergencyCall:
+		for _, e := range entries {
+			ids = append(ids, e.ID())
+		}
+	case []QueueEvent:
+		for _, e := range entries {
+			ids = append(ids, e.ID())
+		}
+	case []BanEvent:
+		for _, e := range entries {
+			ids = append(ids, e.ID())
+		}
+	case []StaffEvent:
+		for _, e := range entries {
+			ids = append(ids, e.ID())
+		}
+	}
+	return Event{
+		ID:      hashFields(string(eventType), strings.Join(ids, ",")),
+		Type:    eventType,
+		Data:    data,
+		Version: SchemaVersion,
+	}
+}
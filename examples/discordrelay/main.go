@@ -0,0 +1,155 @@
+// Command discordrelay posts server events to a Discord channel through an
+// incoming webhook. Events pass through a Pipeline, which filters and
+// throttles them, into an Outbox, so events raised while Discord is
+// unreachable are delivered once it recovers, including across restarts.
+//
+// Usage:
+//
+//	ERLC_SERVER_KEY=... DISCORD_WEBHOOK_URL=https://discord.com/api/webhooks/... \
+//	    go run ./examples/discordrelay -events kills,modcalls,joins
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/bmrgcorp/erlcgo"
+)
+
+func main() {
+	var (
+		serverKey  = flag.String("key", os.Getenv("ERLC_SERVER_KEY"), "server key (or ERLC_SERVER_KEY)")
+		globalKey  = flag.String("global-key", os.Getenv("ERLC_GLOBAL_KEY"), "optional global API key (or ERLC_GLOBAL_KEY)")
+		webhookURL = flag.String("webhook", os.Getenv("DISCORD_WEBHOOK_URL"), "Discord webhook URL (or DISCORD_WEBHOOK_URL)")
+		events     = flag.String("events", "kills,modcalls,joins,bans", "comma separated event types to relay")
+		outboxPath = flag.String("outbox", "discordrelay-outbox.json", "file holding undelivered events")
+		interval   = flag.Duration("interval", 5*time.Second, "poll interval")
+		perSecond  = flag.Float64("rate", 0.5, "maximum messages per second sent to Discord")
+	)
+	flag.Parse()
+	if *serverKey == "" || *webhookURL == "" {
+		log.Fatal("a server key and a webhook URL are required")
+	}
+
+	var types []erlcgo.EventType
+	for _, t := range strings.Split(*events, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types = append(types, erlcgo.EventType(t))
+		}
+	}
+
+	client := erlcgo.NewClient(*serverKey, erlcgo.WithGlobalAPIKey(*globalKey))
+	defer client.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	config := erlcgo.DefaultEventConfig()
+	config.PollInterval = *interval
+	config.LogErrors = true
+	config.CatchUpAfter = time.Minute
+	sub, err := client.SubscribeWithConfig(ctx, config, types...)
+	if err != nil {
+		log.Fatalf("subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	outbox := erlcgo.NewOutbox(&discordSink{url: *webhookURL}, erlcgo.NewFileOutboxStore(*outboxPath), erlcgo.OutboxConfig{
+		ErrorHandler: func(err error) { log.Printf("outbox: %v", err) },
+	})
+	go outbox.Run(ctx)
+
+	p := erlcgo.NewPipeline().
+		Filter(func(e erlcgo.Event) bool { return !e.Replayed || e.Type != erlcgo.EventTypeJoins }).
+		Throttle(*perSecond).
+		OnError(func(e erlcgo.Event, err error) { log.Printf("relay %s: %v", e.Type, err) }).
+		To(outbox)
+
+	log.Printf("relaying %s to Discord", *events)
+	if err := p.Run(ctx, sub); err != nil && ctx.Err() == nil {
+		log.Fatal(err)
+	}
+}
+
+// discordSink posts each event as a message to a Discord webhook.
+type discordSink struct {
+	url    string
+	client http.Client
+}
+
+func (d *discordSink) Send(ctx context.Context, event erlcgo.Event) error {
+	content := format(event)
+	if content == "" {
+		return nil
+	}
+	body, err := json.Marshal(map[string]string{"content": content})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("discord returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// format renders an event as a Discord message. Events reloaded from the
+// outbox after a restart carry decoded JSON rather than typed slices, so
+// those fall back to a generic summary.
+func format(event erlcgo.Event) string {
+	var lines []string
+	switch data := event.Data.(type) {
+	case []erlcgo.ERLCKillLog:
+		for _, k := range data {
+			lines = append(lines, fmt.Sprintf(":skull: **%s** killed **%s**", k.Killer, k.Killed))
+		}
+	case []erlcgo.ERLCModCallLog:
+		for _, m := range data {
+			if m.Moderator == "" {
+				lines = append(lines, fmt.Sprintf(":rotating_light: **%s** called for a moderator", m.Caller))
+			} else {
+				lines = append(lines, fmt.Sprintf(":white_check_mark: **%s** answered **%s**", m.Moderator, m.Caller))
+			}
+		}
+	case []erlcgo.ERLCJoinLog:
+		for _, j := range data {
+			verb := "left"
+			if j.Join {
+				verb = "joined"
+			}
+			lines = append(lines, fmt.Sprintf("**%s** %s", j.Player, verb))
+		}
+	case []erlcgo.BanEvent:
+		for _, b := range data {
+			name := b.Name
+			if name == "" {
+				name = b.UserID
+			}
+			lines = append(lines, fmt.Sprintf(":hammer: **%s** was %s", name, b.Type))
+		}
+	default:
+		lines = append(lines, fmt.Sprintf("%s event", event.Type))
+	}
+	if event.Replayed {
+		lines = append(lines, "_(caught up after a gap in polling)_")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,156 @@
+// Command modbot is a moderation bot skeleton. It greets joining players,
+// reports mod calls and answers a small set of in-game commands, and prints
+// client metrics on an interval.
+//
+// Usage:
+//
+//	ERLC_SERVER_KEY=... go run ./examples/modbot -welcome "Welcome to the server!"
+//
+// Pass -dry-run to record commands instead of sending them, which makes the
+// program safe to point at a live server while trying it out.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/bmrgcorp/erlcgo"
+	"github.com/bmrgcorp/erlcgo/commands"
+)
+
+func main() {
+	var (
+		serverKey = flag.String("key", os.Getenv("ERLC_SERVER_KEY"), "server key (or ERLC_SERVER_KEY)")
+		globalKey = flag.String("global-key", os.Getenv("ERLC_GLOBAL_KEY"), "optional global API key (or ERLC_GLOBAL_KEY)")
+		welcome   = flag.String("welcome", "", "message sent to players when they join; empty disables greetings")
+		prefix    = flag.String("prefix", "!", "prefix for bot commands typed in game")
+		interval  = flag.Duration("interval", 5*time.Second, "poll interval")
+		stats     = flag.Duration("stats", time.Minute, "how often to log client metrics; 0 disables")
+		dryRun    = flag.Bool("dry-run", false, "log commands instead of sending them")
+	)
+	flag.Parse()
+	if *serverKey == "" {
+		log.Fatal("a server key is required: pass -key or set ERLC_SERVER_KEY")
+	}
+
+	opts := []erlcgo.ClientOption{
+		erlcgo.WithGlobalAPIKey(*globalKey),
+		erlcgo.WithRequestQueue(1, time.Second),
+		erlcgo.WithCommandValidation(true),
+	}
+	if *dryRun {
+		opts = append(opts, erlcgo.WithDryRun(func(e erlcgo.DryRunEntry) {
+			log.Printf("dry run: %s", e.Command)
+		}))
+	}
+	client := erlcgo.NewClient(*serverKey, opts...)
+	defer client.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	config := erlcgo.DefaultEventConfig()
+	config.PollInterval = *interval
+	config.LogErrors = true
+	sub, err := client.SubscribeWithConfig(ctx, config,
+		erlcgo.EventTypeJoins, erlcgo.EventTypeModCalls, erlcgo.EventTypeCommands)
+	if err != nil {
+		log.Fatalf("subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	bot := &bot{client: client, ctx: ctx, welcome: *welcome, prefix: *prefix}
+	sub.Handle(erlcgo.HandlerRegistration{
+		JoinHandler:    bot.onJoins,
+		ModCallHandler: bot.onModCalls,
+		CommandHandler: bot.onCommands,
+	})
+
+	if *stats > 0 {
+		go logMetrics(ctx, client, *stats)
+	}
+	log.Printf("modbot running; press Ctrl+C to stop")
+	<-ctx.Done()
+}
+
+type bot struct {
+	client  *erlcgo.Client
+	ctx     context.Context
+	welcome string
+	prefix  string
+}
+
+func (b *bot) onJoins(joins []erlcgo.ERLCJoinLog) {
+	if b.welcome == "" {
+		return
+	}
+	for _, j := range joins {
+		if !j.Join {
+			continue
+		}
+		if err := b.client.Execute(b.ctx, commands.PM(j.Player, b.welcome)); err != nil {
+			log.Printf("greet %s: %v", j.Player, err)
+		}
+	}
+}
+
+func (b *bot) onModCalls(calls []erlcgo.ERLCModCallLog) {
+	for _, call := range calls {
+		if call.Moderator == "" {
+			log.Printf("unanswered mod call from %s", call.Caller)
+		} else {
+			log.Printf("%s answered a mod call from %s", call.Moderator, call.Caller)
+		}
+	}
+}
+
+// onCommands answers bot commands typed in game as ":log !name args". PRC
+// logs every command a player runs, so the bot reads its own commands back
+// from the command log.
+func (b *bot) onCommands(cmds []erlcgo.ERLCCommandLog) {
+	for _, cmd := range cmds {
+		text := strings.TrimSpace(strings.TrimPrefix(cmd.Command, ":log"))
+		if !strings.HasPrefix(text, b.prefix) {
+			continue
+		}
+		name, _, _ := strings.Cut(strings.TrimPrefix(text, b.prefix), " ")
+		var reply string
+		switch name {
+		case "ping":
+			reply = "pong"
+		case "players":
+			resp, err := b.client.GetServer(b.ctx, erlcgo.ServerQueryOptions{})
+			if err != nil {
+				log.Printf("players: %v", err)
+				continue
+			}
+			reply = fmt.Sprintf("Players online: %d/%d", resp.CurrentPlayers, resp.MaxPlayers)
+		default:
+			continue
+		}
+		if err := b.client.Execute(b.ctx, commands.PM(cmd.Player, reply)); err != nil {
+			log.Printf("reply to %s: %v", cmd.Player, err)
+		}
+	}
+}
+
+func logMetrics(ctx context.Context, client *erlcgo.Client, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m := client.Metrics()
+			log.Printf("requests=%d errors=%d rate_limits=%d avg=%s",
+				m.TotalRequests, m.TotalErrors, m.TotalRateLimits, m.AvgResponseTime)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// Command statusexporter serves the health of a fleet of servers for status
+// pages and monitoring. It probes every server through a ClientManager on an
+// interval and serves the latest result as JSON on /status and in the
+// Prometheus text format on /metrics.
+//
+// Usage:
+//
+//	ERLC_SERVERS="main=key1,training=key2" go run ./examples/statusexporter -addr :9100
+//
+// Servers can also be given with repeated -server name=key flags.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bmrgcorp/erlcgo"
+)
+
+type serverList []string
+
+func (s *serverList) String() string     { return strings.Join(*s, ",") }
+func (s *serverList) Set(v string) error { *s = append(*s, v); return nil }
+
+func main() {
+	var servers serverList
+	if env := os.Getenv("ERLC_SERVERS"); env != "" {
+		servers = strings.Split(env, ",")
+	}
+	var (
+		addr      = flag.String("addr", ":9100", "listen address")
+		globalKey = flag.String("global-key", os.Getenv("ERLC_GLOBAL_KEY"), "optional global API key (or ERLC_GLOBAL_KEY)")
+		interval  = flag.Duration("interval", 30*time.Second, "time between health probes")
+	)
+	flag.Var(&servers, "server", "server as name=key; repeatable (or ERLC_SERVERS, comma separated)")
+	flag.Parse()
+
+	queue := erlcgo.NewRequestQueue(2, 500*time.Millisecond)
+	queue.Start()
+	defer queue.Stop()
+
+	m := erlcgo.NewClientManager([]erlcgo.ClientOption{
+		erlcgo.WithGlobalAPIKey(*globalKey),
+		erlcgo.WithQueue(queue),
+	})
+	defer m.Close()
+	for _, s := range servers {
+		name, key, ok := strings.Cut(strings.TrimSpace(s), "=")
+		if !ok || name == "" || key == "" {
+			log.Fatalf("invalid server %q: want name=key", s)
+		}
+		m.Add(name, key)
+	}
+	if len(m.Names()) == 0 {
+		log.Fatal("no servers configured: pass -server name=key or set ERLC_SERVERS")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	e := &exporter{manager: m}
+	e.probe(ctx)
+	go func() {
+		ticker := time.NewTicker(*interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				e.probe(ctx)
+			}
+		}
+	}()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", e.serveStatus)
+	mux.HandleFunc("/metrics", e.serveMetrics)
+	srv := &http.Server{Addr: *addr, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	log.Printf("serving %d servers on %s", len(m.Names()), *addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
+
+type exporter struct {
+	manager *erlcgo.ClientManager
+
+	mu     sync.RWMutex
+	health erlcgo.FleetHealth
+}
+
+func (e *exporter) probe(ctx context.Context) {
+	health := e.manager.Health(ctx)
+	e.mu.Lock()
+	e.health = health
+	e.mu.Unlock()
+}
+
+func (e *exporter) latest() erlcgo.FleetHealth {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.health
+}
+
+type serverStatus struct {
+	Name       string  `json:"name"`
+	Reachable  bool    `json:"reachable"`
+	LatencyMS  int64   `json:"latency_ms"`
+	Players    int     `json:"players"`
+	MaxPlayers int     `json:"max_players"`
+	Headroom   float64 `json:"rate_limit_headroom"`
+	LastError  string  `json:"last_error,omitempty"`
+}
+
+func (e *exporter) serveStatus(w http.ResponseWriter, req *http.Request) {
+	health := e.latest()
+	out := struct {
+		CheckedAt time.Time      `json:"checked_at"`
+		Players   int            `json:"players"`
+		Servers   []serverStatus `json:"servers"`
+	}{CheckedAt: health.CheckedAt, Players: health.Players}
+	for _, s := range health.Servers {
+		status := serverStatus{
+			Name:       s.Name,
+			Reachable:  s.Reachable,
+			LatencyMS:  s.Latency.Milliseconds(),
+			Players:    s.Players,
+			MaxPlayers: s.MaxPlayers,
+			Headroom:   s.Headroom(),
+		}
+		if s.LastError != nil {
+			status.LastError = s.LastError.Error()
+		}
+		out.Servers = append(out.Servers, status)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
+
+func (e *exporter) serveMetrics(w http.ResponseWriter, req *http.Request) {
+	health := e.latest()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	fmt.Fprintln(w, "# TYPE erlc_server_up gauge")
+	for _, s := range health.Servers {
+		up := 0
+		if s.Reachable {
+			up = 1
+		}
+		fmt.Fprintf(w, "erlc_server_up{server=%q} %d\n", s.Name, up)
+	}
+	fmt.Fprintln(w, "# TYPE erlc_server_players gauge")
+	for _, s := range health.Servers {
+		fmt.Fprintf(w, "erlc_server_players{server=%q} %d\n", s.Name, s.Players)
+	}
+	fmt.Fprintln(w, "# TYPE erlc_server_max_players gauge")
+	for _, s := range health.Servers {
+		fmt.Fprintf(w, "erlc_server_max_players{server=%q} %d\n", s.Name, s.MaxPlayers)
+	}
+	fmt.Fprintln(w, "# TYPE erlc_server_latency_seconds gauge")
+	for _, s := range health.Servers {
+		fmt.Fprintf(w, "erlc_server_latency_seconds{server=%q} %g\n", s.Name, s.Latency.Seconds())
+	}
+	fmt.Fprintln(w, "# TYPE erlc_rate_limit_headroom gauge")
+	for _, s := range health.Servers {
+		fmt.Fprintf(w, "erlc_rate_limit_headroom{server=%q} %g\n", s.Name, s.Headroom())
+	}
+
+	fmt.Fprintln(w, "# TYPE erlc_client_requests_total counter")
+	fmt.Fprintln(w, "# TYPE erlc_client_errors_total counter")
+	for _, name := range e.manager.Names() {
+		c, ok := e.manager.Get(name)
+		if !ok {
+			continue
+		}
+		m := c.Metrics()
+		fmt.Fprintf(w, "erlc_client_requests_total{server=%q} %d\n", name, m.TotalRequests)
+		fmt.Fprintf(w, "erlc_client_errors_total{server=%q} %d\n", name, m.TotalErrors)
+	}
+}
@@ -0,0 +1,117 @@
+package erlcgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GoroutineInfo describes a background goroutine started by the library.
+type GoroutineInfo struct {
+	// Name says what the goroutine does, such as "queue worker" or
+	// "subscription poller".
+	Name    string
+	Started time.Time
+}
+
+type trackedGoroutine struct {
+	owner interface{}
+	info  GoroutineInfo
+}
+
+// goroutineRegistry records the goroutines started by the library, keyed by
+// the object that owns them, so leaks can be found after Close.
+type goroutineRegistry struct {
+	mu     sync.Mutex
+	nextID uint64
+	live   map[uint64]trackedGoroutine
+}
+
+var goroutines goroutineRegistry
+
+// spawn runs fn on a new goroutine recorded under owner until fn returns.
+func spawn(owner interface{}, name string, fn func()) {
+	goroutines.mu.Lock()
+	goroutines.nextID++
+	id := goroutines.nextID
+	if goroutines.live == nil {
+		goroutines.live = make(map[uint64]trackedGoroutine)
+	}
+	goroutines.live[id] = trackedGoroutine{owner: owner, info: GoroutineInfo{Name: name, Started: time.Now()}}
+	goroutines.mu.Unlock()
+
+	go func() {
+		defer func() {
+			goroutines.mu.Lock()
+			delete(goroutines.live, id)
+			goroutines.mu.Unlock()
+		}()
+		fn()
+	}()
+}
+
+// ActiveGoroutines lists the background goroutines still running for the
+// client: subscription pollers and handler loops, log tailers, correlators,
+// the offline command runner, and the workers of a queue or cleanup loop of a
+// memory cache the client created itself. Shared queues, caches and decode
+// pools belong to their creator and are not included. The list is ordered
+// by start time.
+func (c *Client) ActiveGoroutines() []GoroutineInfo {
+	owners := map[interface{}]bool{c: true}
+	if c.queue != nil && c.ownsQueue {
+		owners[c.queue] = true
+	}
+	if c.cache != nil {
+		if mc, ok := c.cache.Cache.(*MemoryCache); ok {
+			owners[mc] = true
+		}
+	}
+
+	goroutines.mu.Lock()
+	var out []GoroutineInfo
+	for _, g := range goroutines.live {
+		if owners[g.owner] {
+			out = append(out, g.info)
+		}
+	}
+	goroutines.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Started.Before(out[j].Started) })
+	return out
+}
+
+// LeakCheck waits for the client's background goroutines to exit, returning
+// an error naming those still running when ctx is done. Call it after Close,
+// typically at the end of a test, to verify that everything was shut down.
+// Goroutines tied to a context, such as a subscription's, also need that
+// context canceled or the subscription closed.
+//
+// Example:
+//
+//	client.Close()
+//	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+//	defer cancel()
+//	if err := client.LeakCheck(ctx); err != nil {
+//	    t.Fatal(err)
+//	}
+func (c *Client) LeakCheck(ctx context.Context) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		active := c.ActiveGoroutines()
+		if len(active) == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			names := make([]string, len(active))
+			for i, g := range active {
+				names[i] = g.Name
+			}
+			return fmt.Errorf("erlc: %d goroutines still running: %s", len(active), strings.Join(names, ", "))
+		case <-ticker.C:
+		}
+	}
+}
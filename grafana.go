@@ -0,0 +1,248 @@
+package erlcgo
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Metric names served by GrafanaDatasource.
+const (
+	GrafanaMetricPlayers           = "players"
+	GrafanaMetricKillsPerMinute    = "kills_per_minute"
+	GrafanaMetricModCallsPerMinute = "modcalls_per_minute"
+)
+
+var grafanaMetrics = []string{GrafanaMetricPlayers, GrafanaMetricKillsPerMinute, GrafanaMetricModCallsPerMinute}
+
+// GrafanaConfig configures a GrafanaDatasource.
+type GrafanaConfig struct {
+	// PollInterval is how often the server is sampled. Defaults to 30 seconds.
+	PollInterval time.Duration
+
+	// Retention is how much history is kept in memory. Defaults to 24 hours.
+	Retention time.Duration
+}
+
+// GrafanaDatasource samples a server and serves the history in the format
+// expected by Grafana's JSON datasource plugin (/, /search, /metrics and
+// /query), so communities can build dashboards without custom code. History
+// is kept in memory only.
+//
+// Serve it on its own or through a Relay with RelayConfig.Grafana, which
+// mounts it under /grafana/.
+//
+// Example:
+//
+//	ds := erlcgo.NewGrafanaDatasource(client, erlcgo.GrafanaConfig{})
+//	go ds.Run(ctx)
+//	http.ListenAndServe(":3001", ds)
+type GrafanaDatasource struct {
+	client *Client
+	config GrafanaConfig
+
+	mu       sync.Mutex
+	players  []grafanaPoint
+	kills    map[int64]float64 // minute (Unix seconds) -> count
+	modCalls map[int64]float64
+	killTime int64
+	callTime int64
+	started  time.Time
+}
+
+type grafanaPoint struct {
+	at    time.Time
+	value float64
+}
+
+// NewGrafanaDatasource creates a datasource. Call Run to start sampling.
+func NewGrafanaDatasource(client *Client, config GrafanaConfig) *GrafanaDatasource {
+	if config.PollInterval <= 0 {
+		config.PollInterval = 30 * time.Second
+	}
+	if config.Retention <= 0 {
+		config.Retention = 24 * time.Hour
+	}
+	return &GrafanaDatasource{
+		client:   client,
+		config:   config,
+		kills:    make(map[int64]float64),
+		modCalls: make(map[int64]float64),
+	}
+}
+
+// Run samples the server until ctx is done. Failed polls leave a gap.
+func (g *GrafanaDatasource) Run(ctx context.Context) error {
+	ticker := time.NewTicker(g.config.PollInterval)
+	defer ticker.Stop()
+
+	opts := ServerQueryOptions{Players: true, KillLogs: true, ModCalls: true}
+	for {
+		if resp, err := g.client.GetServer(ctx, opts); err == nil {
+			g.record(resp, time.Now())
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (g *GrafanaDatasource) record(resp *ERLCServerResponse, now time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.started.IsZero() {
+		g.started = now
+	}
+	g.players = append(g.players, grafanaPoint{at: now, value: float64(len(resp.Players))})
+
+	newest := g.killTime
+	for _, k := range resp.KillLogs {
+		if ts := logTimestamp(k); ts > g.killTime {
+			g.kills[minuteOf(ts)]++
+			if ts > newest {
+				newest = ts
+			}
+		}
+	}
+	g.killTime = newest
+
+	newest = g.callTime
+	for _, m := range resp.ModCalls {
+		if ts := logTimestamp(m); ts > g.callTime {
+			g.modCalls[minuteOf(ts)]++
+			if ts > newest {
+				newest = ts
+			}
+		}
+	}
+	g.callTime = newest
+
+	cutoff := now.Add(-g.config.Retention)
+	i := sort.Search(len(g.players), func(i int) bool { return g.players[i].at.After(cutoff) })
+	g.players = append(g.players[:0], g.players[i:]...)
+	for minute := range g.kills {
+		if minute < cutoff.Unix() {
+			delete(g.kills, minute)
+		}
+	}
+	for minute := range g.modCalls {
+		if minute < cutoff.Unix() {
+			delete(g.modCalls, minute)
+		}
+	}
+}
+
+func minuteOf(ts int64) int64 {
+	return ts - ts%60
+}
+
+type grafanaQuery struct {
+	Range struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	} `json:"range"`
+	Targets []struct {
+		Target string `json:"target"`
+	} `json:"targets"`
+}
+
+type grafanaSeries struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+func (g *GrafanaDatasource) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	switch req.URL.Path {
+	case "", "/":
+		w.WriteHeader(http.StatusOK)
+	case "/search":
+		writeGrafanaJSON(w, grafanaMetrics)
+	case "/metrics":
+		type metric struct {
+			Label string `json:"label"`
+			Value string `json:"value"`
+		}
+		metrics := make([]metric, 0, len(grafanaMetrics))
+		for _, m := range grafanaMetrics {
+			metrics = append(metrics, metric{Label: m, Value: m})
+		}
+		writeGrafanaJSON(w, metrics)
+	case "/query":
+		g.serveQuery(w, req)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func (g *GrafanaDatasource) serveQuery(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(req.Body, 64<<10))
+	if err != nil {
+		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+	var q grafanaQuery
+	if err := json.Unmarshal(body, &q); err != nil {
+		http.Error(w, "invalid query", http.StatusBadRequest)
+		return
+	}
+	if q.Range.To.IsZero() {
+		q.Range.To = time.Now()
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	series := make([]grafanaSeries, 0, len(q.Targets))
+	for _, t := range q.Targets {
+		s := grafanaSeries{Target: t.Target, Datapoints: make([][2]float64, 0)}
+		switch t.Target {
+		case GrafanaMetricPlayers:
+			for _, p := range g.players {
+				if !p.at.Before(q.Range.From) && !p.at.After(q.Range.To) {
+					s.Datapoints = append(s.Datapoints, [2]float64{p.value, float64(p.at.UnixMilli())})
+				}
+			}
+		case GrafanaMetricKillsPerMinute:
+			s.Datapoints = g.perMinute(g.kills, q.Range.From, q.Range.To)
+		case GrafanaMetricModCallsPerMinute:
+			s.Datapoints = g.perMinute(g.modCalls, q.Range.From, q.Range.To)
+		default:
+			continue
+		}
+		series = append(series, s)
+	}
+	writeGrafanaJSON(w, series)
+}
+
+// perMinute returns one point per minute in the range, filling minutes with
+// no events with zero, but only since sampling started so that the time
+// before the datasource existed is not reported as quiet.
+func (g *GrafanaDatasource) perMinute(counts map[int64]float64, from, to time.Time) [][2]float64 {
+	points := make([][2]float64, 0)
+	if g.started.IsZero() {
+		return points
+	}
+	if from.Before(g.started) {
+		from = g.started
+	}
+	for minute := minuteOf(from.Unix()); minute <= to.Unix(); minute += 60 {
+		points = append(points, [2]float64{counts[minute], float64(minute * 1000)})
+	}
+	return points
+}
+
+func writeGrafanaJSON(w http.ResponseWriter, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeRelayBody(w, http.StatusOK, body)
+}
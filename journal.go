@@ -0,0 +1,226 @@
+package erlcgo
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// JournalEntry is a mutating request recorded before it was sent.
+type JournalEntry struct {
+	ID        string    `json:"id"`
+	Command   string    `json:"command"`
+	CreatedAt time.Time `json:"createdAt"`
+	Tenant    string    `json:"tenant,omitempty"`
+	Tags      []string  `json:"tags,omitempty"` // see WithCommandTags
+}
+
+// Journal is a write-ahead log for commands. Entries are appended before a
+// command is sent and completed once the API has answered, so commands that
+// were in flight during a crash can be found and replayed afterwards.
+// Implementations must be safe for concurrent use.
+type Journal interface {
+	// Append records a command that is about to be sent.
+	Append(entry JournalEntry) error
+
+	// Complete marks a previously appended entry as finished.
+	Complete(id string) error
+
+	// Pending returns entries that were appended but never completed,
+	// oldest first.
+	Pending() ([]JournalEntry, error)
+}
+
+// FileJournal is a Journal backed by an append-only JSON lines file.
+type FileJournal struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+type journalRecord struct {
+	Op string `json:"op"` // "begin" or "complete"
+	JournalEntry
+}
+
+// NewFileJournal opens (or creates) a journal file at path.
+//
+// Example:
+//
+//	journal, err := erlcgo.NewFileJournal("commands.journal")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer journal.Close()
+//	client := erlcgo.NewClient("your-server-key", erlcgo.WithJournal(journal))
+func NewFileJournal(path string) (*FileJournal, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	return &FileJournal{path: path, file: f}, nil
+}
+
+func (j *FileJournal) Append(entry JournalEntry) error {
+	return j.write(journalRecord{Op: "begin", JournalEntry: entry})
+}
+
+func (j *FileJournal) Complete(id string) error {
+	return j.write(journalRecord{Op: "complete", JournalEntry: JournalEntry{ID: id}})
+}
+
+func (j *FileJournal) write(rec journalRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+func (j *FileJournal) Pending() ([]JournalEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	f, err := os.Open(j.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var order []string
+	open := make(map[string]JournalEntry)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec journalRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			// A torn final line from a crash mid-write is expected; skip it.
+			continue
+		}
+		switch rec.Op {
+		case "begin":
+			if _, ok := open[rec.ID]; !ok {
+				order = append(order, rec.ID)
+			}
+			open[rec.ID] = rec.JournalEntry
+		case "complete":
+			delete(open, rec.ID)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	pending := make([]JournalEntry, 0, len(open))
+	for _, id := range order {
+		if entry, ok := open[id]; ok {
+			pending = append(pending, entry)
+			delete(open, id)
+		}
+	}
+	return pending, nil
+}
+
+// Close closes the underlying journal file.
+func (j *FileJournal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
+
+// WithJournal enables write-ahead journaling of ExecuteCommand calls.
+func WithJournal(j Journal) ClientOption {
+	return func(c *Client) {
+		c.journal = j
+	}
+}
+
+// PendingCommands returns journaled commands that were never confirmed as
+// answered by the API, typically because the process crashed mid-request.
+func (c *Client) PendingCommands() ([]JournalEntry, error) {
+	if c.journal == nil {
+		return nil, errors.New("journal is not configured")
+	}
+	return c.journal.Pending()
+}
+
+// ReplayPending re-sends every pending journaled command in order and marks
+// each one complete once the API answers. Replays pass through the command
+// middleware and the same policy, validation, restricted-command and target
+// checks as new commands; a command they refuse is marked complete, as it would never be sent, and
+// reported in the returned error. In dry-run mode commands are recorded and
+// left pending. It stops at the first command that fails without an API
+// response and returns that error.
+func (c *Client) ReplayPending(ctx context.Context) error {
+	pending, err := c.PendingCommands()
+	if err != nil {
+		return err
+	}
+	var refused []error
+	for _, entry := range pending {
+		entryCtx := WithCommandTags(ctx, entry.Tags...)
+		if entry.Tenant != "" {
+			entryCtx = WithTenant(entryCtx, entry.Tenant)
+		}
+		replay := &journalReplay{entry: entry}
+		err := c.executeCommand(context.WithValue(entryCtx, journalReplayKey{}, replay), entry.Command, nil)
+		switch {
+		case err == nil:
+		case !replay.sent:
+			if jErr := c.journal.Complete(entry.ID); jErr != nil {
+				return fmt.Errorf("failed to complete journal entry: %w", jErr)
+			}
+			refused = append(refused, fmt.Errorf("journaled command %q refused: %w", entry.Command, err))
+		default:
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				return err
+			}
+		}
+	}
+	return errors.Join(refused...)
+}
+
+type journalReplayKey struct{}
+
+// journalReplay carries a pending entry through executeCommand, so the
+// replay completes that entry instead of journaling a new one. sent records
+// whether the command got past the checks.
+type journalReplay struct {
+	entry JournalEntry
+	sent  bool
+}
+
+// executeJournaled sends a journaled command and completes its entry when the
+// API has definitely received it, even if its response could not be decoded.
+// Transport failures leave the entry pending, because the command may or may
+// not have reached the server.
+func (c *Client) executeJournaled(ctx context.Context, entry JournalEntry, v interface{}) error {
+	err := c.sendCommand(ctx, entry.Command, v)
+	var apiErr *APIError
+	if err == nil || errors.As(err, &apiErr) || StageOf(err) == StageDecode {
+		if jErr := c.journal.Complete(entry.ID); jErr != nil && err == nil {
+			return fmt.Errorf("failed to complete journal entry: %w", jErr)
+		}
+	}
+	return err
+}
+
+func newRandomID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,149 @@
+package erlcgo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestFileJournalPendingAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "commands.journal")
+	j, err := NewFileJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		if err := j.Append(JournalEntry{ID: id, Command: ":h " + id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := j.Complete("b"); err != nil {
+		t.Fatal(err)
+	}
+	j.Close()
+
+	// A crash mid-write leaves a torn last line.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"op":"complete","id":"a`)
+	f.Close()
+
+	reopened, err := NewFileJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	pending, err := reopened.Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 2 || pending[0].ID != "a" || pending[1].ID != "c" {
+		t.Errorf("pending %+v, want a then c", pending)
+	}
+}
+
+// commandRecorder is a PRC server that records the commands it receives and
+// drops the connection instead of answering while crash is set.
+type commandRecorder struct {
+	mu       sync.Mutex
+	commands []string
+	crash    bool
+	status   int
+}
+
+func (r *commandRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var body struct {
+		Command string `json:"command"`
+	}
+	json.NewDecoder(req.Body).Decode(&body)
+	r.mu.Lock()
+	crash, status := r.crash, r.status
+	if !crash {
+		r.commands = append(r.commands, body.Command)
+	}
+	r.mu.Unlock()
+
+	if crash {
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if status != 0 {
+		w.WriteHeader(status)
+		w.Write([]byte(`{"code":3001,"message":"Invalid command"}`))
+		return
+	}
+	w.Write([]byte(`{"message":"Success"}`))
+}
+
+func (r *commandRecorder) set(status int, crash bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status, r.crash = status, crash
+}
+
+func (r *commandRecorder) received() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.commands...)
+}
+
+func TestReplayPendingAfterCrash(t *testing.T) {
+	rec := &commandRecorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+	path := filepath.Join(t.TempDir(), "commands.journal")
+
+	// Before the crash: one command is answered, one is rejected by the API
+	// and one never gets a response.
+	journal, err := NewFileJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewClient("key", WithBaseURL(srv.URL), WithJournal(journal))
+	if err := c.ExecuteCommand(context.Background(), ":h answered"); err != nil {
+		t.Fatal(err)
+	}
+	rec.set(http.StatusBadRequest, false)
+	if err := c.ExecuteCommand(context.Background(), ":h rejected"); err == nil {
+		t.Fatal("rejected command succeeded")
+	}
+	rec.set(0, true)
+	if err := c.ExecuteCommand(context.Background(), ":h lost"); err == nil {
+		t.Fatal("command without a response succeeded")
+	}
+	c.Close()
+	journal.Close()
+
+	// After a restart only the unanswered command is replayed, once.
+	rec.set(0, false)
+	journal, err = NewFileJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer journal.Close()
+	c = NewClient("key", WithBaseURL(srv.URL), WithJournal(journal))
+	defer c.Close()
+	for i := 0; i < 2; i++ {
+		if err := c.ReplayPending(context.Background()); err != nil {
+			t.Fatalf("replay %d: %v", i, err)
+		}
+	}
+
+	want := []string{":h answered", ":h rejected", ":h lost"}
+	if got := rec.received(); !reflect.DeepEqual(got, want) {
+		t.Errorf("server received %q, want %q", got, want)
+	}
+	if pending, _ := c.PendingCommands(); len(pending) != 0 {
+		t.Errorf("still pending after replay: %+v", pending)
+	}
+}
@@ -0,0 +1,180 @@
+package erlcgo
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PlayerSession is what a KillCorrelator knows about a player's current stay
+// in the server.
+type PlayerSession struct {
+	Player     string
+	Team       string
+	Callsign   string
+	Permission string
+
+	// JoinedAt is taken from the join log when available, otherwise it is
+	// when the player was first seen in the player list.
+	JoinedAt time.Time
+}
+
+// EnrichedKill is a kill log entry joined with the sessions of both players
+// at the time of the kill. Killer or Victim is nil if the player was not
+// being tracked, for example because they left before the next poll.
+type EnrichedKill struct {
+	ERLCKillLog
+	Killer *PlayerSession
+	Victim *PlayerSession
+}
+
+// SameTeam reports whether both players are known and were on the same team.
+func (k EnrichedKill) SameTeam() bool {
+	return k.Killer != nil && k.Victim != nil && k.Killer.Team == k.Victim.Team
+}
+
+// KillCorrelator tracks player sessions from successive server snapshots and
+// enriches new kills with them, so RDM tooling gets the team and session of
+// each player without tracking state itself.
+//
+// Example:
+//
+//	kc := erlcgo.NewKillCorrelator()
+//	for kill := range kc.Run(ctx, client, 2*time.Second) {
+//	    if kill.SameTeam() {
+//	        fmt.Printf("team kill: %s -> %s\n", kill.ERLCKillLog.Killer, kill.ERLCKillLog.Killed)
+//	    }
+//	}
+type KillCorrelator struct {
+	mu       sync.Mutex
+	sessions map[string]*PlayerSession
+	killTime int64
+	seeded   bool
+}
+
+// NewKillCorrelator creates an empty correlator.
+func NewKillCorrelator() *KillCorrelator {
+	return &KillCorrelator{sessions: make(map[string]*PlayerSession)}
+}
+
+// Observe updates tracked sessions from a snapshot fetched with Players,
+// JoinLogs and KillLogs, and returns the kills not seen before, oldest first.
+// Kills are matched against sessions as they were before this snapshot, which
+// is the closest known state to when the kill happened. The first snapshot
+// only seeds state and returns no kills.
+func (kc *KillCorrelator) Observe(resp *ERLCServerResponse) []EnrichedKill {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	joins := make(map[string]int64)
+	for _, j := range resp.JoinLogs {
+		if ts := logTimestamp(j); j.Join && ts > joins[j.Player] {
+			joins[j.Player] = ts
+		}
+	}
+
+	kills := make([]EnrichedKill, 0)
+	newest := kc.killTime
+	for _, k := range resp.KillLogs {
+		ts := logTimestamp(k)
+		if ts <= kc.killTime {
+			continue
+		}
+		if ts > newest {
+			newest = ts
+		}
+		if !kc.seeded {
+			continue
+		}
+		kills = append(kills, EnrichedKill{
+			ERLCKillLog: k,
+			Killer:      kc.session(k.Killer, resp.Players, joins),
+			Victim:      kc.session(k.Killed, resp.Players, joins),
+		})
+	}
+	kc.killTime = newest
+	kc.seeded = true
+
+	online := make(map[string]*PlayerSession, len(resp.Players))
+	for _, p := range resp.Players {
+		s, ok := kc.sessions[p.Player]
+		if !ok {
+			s = &PlayerSession{Player: p.Player, JoinedAt: time.Now()}
+			if ts, ok := joins[p.Player]; ok {
+				s.JoinedAt = ParseTimestamp(ts)
+			}
+		}
+		s.Team = p.Team
+		s.Callsign = p.Callsign
+		s.Permission = p.Permission
+		online[p.Player] = s
+	}
+	kc.sessions = online
+
+	sort.SliceStable(kills, func(i, j int) bool { return logTimestamp(kills[i].ERLCKillLog) < logTimestamp(kills[j].ERLCKillLog) })
+	return kills
+}
+
+// Session returns the tracked session for a player.
+func (kc *KillCorrelator) Session(player string) (PlayerSession, bool) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	s, ok := kc.sessions[player]
+	if !ok {
+		return PlayerSession{}, false
+	}
+	return *s, true
+}
+
+// session returns a copy of a player's session, preferring the state from
+// before the current snapshot and falling back to the snapshot itself for
+// players who joined in between.
+func (kc *KillCorrelator) session(player string, players []ERLCServerPlayer, joins map[string]int64) *PlayerSession {
+	if s, ok := kc.sessions[player]; ok {
+		cp := *s
+		return &cp
+	}
+	for _, p := range players {
+		if p.Player == player {
+			s := &PlayerSession{Player: p.Player, Team: p.Team, Callsign: p.Callsign, Permission: p.Permission, JoinedAt: time.Now()}
+			if ts, ok := joins[player]; ok {
+				s.JoinedAt = ParseTimestamp(ts)
+			}
+			return s
+		}
+	}
+	return nil
+}
+
+// Run polls the server every interval and delivers enriched kills until ctx
+// is done. Poll errors are skipped; the next poll picks up where it left off.
+func (kc *KillCorrelator) Run(ctx context.Context, client *Client, interval time.Duration) <-chan EnrichedKill {
+	ctx = pollContext(ctx)
+	out := make(chan EnrichedKill, 100)
+	spawn(client, "kill correlator", func() {
+		defer close(out)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		opts := ServerQueryOptions{Players: true, JoinLogs: true, KillLogs: true}
+		for {
+			if resp, err := client.GetServer(ctx, opts); err == nil {
+				for _, k := range kc.Observe(resp) {
+					select {
+					case out <- k:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+	return out
+}
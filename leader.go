@@ -0,0 +1,239 @@
+package erlcgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LeaderLock coordinates redundant pollers so only one instance polls a
+// server at a time. The holder of a lease must renew it by calling TryAcquire
+// again before it expires; other instances stay on standby and take over once
+// the lease lapses. Implementations must be safe for concurrent use.
+type LeaderLock interface {
+	// TryAcquire acquires or renews the lease on key for holder and reports
+	// whether holder is now the leader.
+	TryAcquire(ctx context.Context, key, holder string, ttl time.Duration) (bool, error)
+
+	// Release gives up the lease if it is held by holder.
+	Release(ctx context.Context, key, holder string) error
+}
+
+// RedisLeaderLock is a LeaderLock backed by Redis keys with expirations.
+type RedisLeaderLock struct {
+	redisConn
+	// Prefix is prepended to every key, e.g. "erlcgo:leader:".
+	Prefix string
+}
+
+// NewRedisLeaderLock returns a lock using the Redis server at addr.
+// Password, DB and DialTimeout may be set on the returned lock before use.
+func NewRedisLeaderLock(addr string) *RedisLeaderLock {
+	return &RedisLeaderLock{redisConn: redisConn{Addr: addr}, Prefix: "erlcgo:leader:"}
+}
+
+// Acquire the key if free, or extend it if we already hold it.
+const redisAcquireScript = `
+local v = redis.call("GET", KEYS[1])
+if v == false then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+	return 1
+elseif v == ARGV[1] then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	return 1
+end
+return 0`
+
+const redisReleaseScript = `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`
+
+func (l *RedisLeaderLock) TryAcquire(ctx context.Context, key, holder string, ttl time.Duration) (bool, error) {
+	reply, err := l.do(ctx, "EVAL", redisAcquireScript, "1", l.Prefix+key, holder, itoa64(ttl.Milliseconds()))
+	if err != nil {
+		return false, err
+	}
+	n, _ := reply.(int64)
+	return n == 1, nil
+}
+
+func (l *RedisLeaderLock) Release(ctx context.Context, key, holder string) error {
+	_, err := l.do(ctx, "EVAL", redisReleaseScript, "1", l.Prefix+key, holder)
+	return err
+}
+
+// FileLeaderLock is a LeaderLock backed by lease files in a directory. It is
+// intended for instances on the same host or sharing a local filesystem;
+// network filesystems may not provide the exclusive-create guarantee it relies on.
+type FileLeaderLock struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileLeaderLock returns a lock that keeps lease files in dir.
+func NewFileLeaderLock(dir string) *FileLeaderLock {
+	return &FileLeaderLock{dir: dir}
+}
+
+type fileLease struct {
+	Holder    string    `json:"holder"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+func (l *FileLeaderLock) TryAcquire(ctx context.Context, key, holder string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	unlock, err := l.guard(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	path := l.path(key)
+	if lease, err := readLease(path); err == nil {
+		if lease.Holder != holder && time.Now().Before(lease.ExpiresAt) {
+			return false, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return false, err
+	}
+
+	data, err := json.Marshal(fileLease{Holder: holder, ExpiresAt: time.Now().Add(ttl)})
+	if err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (l *FileLeaderLock) Release(ctx context.Context, key, holder string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	unlock, err := l.guard(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	lease, err := readLease(l.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if lease.Holder != holder {
+		return nil
+	}
+	return os.Remove(l.path(key))
+}
+
+func (l *FileLeaderLock) path(key string) string {
+	return filepath.Join(l.dir, url.PathEscape(key)+".lease")
+}
+
+// guard serialises lease updates across processes with an exclusively created
+// marker file. Markers older than a few seconds are assumed to belong to a
+// crashed process and are removed.
+func (l *FileLeaderLock) guard(ctx context.Context, key string) (func(), error) {
+	marker := l.path(key) + ".lock"
+	for {
+		f, err := os.OpenFile(marker, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(marker) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if info, statErr := os.Stat(marker); statErr == nil && time.Since(info.ModTime()) > time.Second*5 {
+			os.Remove(marker)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Millisecond * 20):
+		}
+	}
+}
+
+func readLease(path string) (fileLease, error) {
+	var lease fileLease
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return lease, err
+	}
+	err = json.Unmarshal(data, &lease)
+	return lease, err
+}
+
+// leaderElection tracks a subscription's leadership state.
+type leaderElection struct {
+	lock    LeaderLock
+	key     string
+	holder  string
+	lease   time.Duration
+	leading bool
+}
+
+// leaderKey names the lease for a subscription: its server and either name or
+// its sorted event types.
+func leaderKey(serverKey, name string, types []EventType) string {
+	if name == "" {
+		names := make([]string, len(types))
+		for i, t := range types {
+			names[i] = string(t)
+		}
+		slices.Sort(names)
+		name = strings.Join(slices.Compact(names), "+")
+	}
+	return serverID(serverKey) + "." + name
+}
+
+// leaderLease returns the lease duration for a subscription, defaulting to
+// three poll intervals so a single slow poll does not cost leadership.
+func leaderLease(config *EventConfig) time.Duration {
+	if config.LeaderLease > 0 {
+		return config.LeaderLease
+	}
+	lease := config.PollInterval * 3
+	if lease < time.Second*5 {
+		lease = time.Second * 5
+	}
+	return lease
+}
+
+// step acquires or renews the lease and reports whether this instance leads,
+// and whether it has just taken over. Errors count as loss of leadership so
+// two instances never poll at once because of a flaky lock backend.
+func (e *leaderElection) step(ctx context.Context) (leading, tookOver bool, err error) {
+	ok, err := e.lock.TryAcquire(ctx, e.key, e.holder, e.lease)
+	if err != nil {
+		ok = false
+	}
+	tookOver = ok && !e.leading
+	e.leading = ok
+	return ok, tookOver, err
+}
+
+func (e *leaderElection) release() {
+	if e.leading {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		e.lock.Release(ctx, e.key, e.holder)
+	}
+}
@@ -0,0 +1,85 @@
+package erlcgo
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLeaderLocks(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		lock func(t *testing.T) LeaderLock
+	}{
+		{"redis", func(t *testing.T) LeaderLock {
+			l := NewRedisLeaderLock(newFakeRedis(t, "").addr())
+			t.Cleanup(func() { l.Close() })
+			return l
+		}},
+		{"file", func(t *testing.T) LeaderLock { return NewFileLeaderLock(t.TempDir()) }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			lock := tc.lock(t)
+			ctx := context.Background()
+			try := func(holder string, ttl time.Duration) bool {
+				t.Helper()
+				ok, err := lock.TryAcquire(ctx, "server.kills", holder, ttl)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return ok
+			}
+
+			if !try("a", time.Minute) {
+				t.Fatal("a could not take a free lock")
+			}
+			if try("b", time.Minute) {
+				t.Error("b took a lock a holds")
+			}
+			if !try("a", 50*time.Millisecond) {
+				t.Error("a could not renew its own lease")
+			}
+			// Releasing someone else's lease does nothing.
+			if err := lock.Release(ctx, "server.kills", "b"); err != nil {
+				t.Fatal(err)
+			}
+			if try("b", time.Minute) {
+				t.Error("b took the lock after releasing a lease it does not hold")
+			}
+
+			// Once a's renewed lease lapses, b takes over.
+			time.Sleep(80 * time.Millisecond)
+			if !try("b", time.Minute) {
+				t.Fatal("b could not take over an expired lease")
+			}
+			if try("a", time.Minute) {
+				t.Error("a took the lock back from b")
+			}
+
+			if err := lock.Release(ctx, "server.kills", "b"); err != nil {
+				t.Fatal(err)
+			}
+			if !try("a", time.Minute) {
+				t.Error("a could not take the lock after b released it")
+			}
+			// Other keys are independent.
+			if ok, err := lock.TryAcquire(ctx, "server.joins", "b", time.Minute); !ok || err != nil {
+				t.Errorf("b on another key = %v, %v; want the lock", ok, err)
+			}
+		})
+	}
+}
+
+func TestLeaderKey(t *testing.T) {
+	a := leaderKey("key", "", []EventType{EventTypeKills, EventTypeJoins, EventTypeKills})
+	b := leaderKey("key", "", []EventType{EventTypeJoins, EventTypeKills})
+	if a != b {
+		t.Errorf("keys differ by event type order: %q and %q", a, b)
+	}
+	if other := leaderKey("key", "", []EventType{EventTypeJoins}); other == a {
+		t.Errorf("different event types share the key %q", a)
+	}
+	if named := leaderKey("key", "poller", []EventType{EventTypeJoins}); named == leaderKey("other", "poller", nil) {
+		t.Errorf("different servers share the key %q", named)
+	}
+}
@@ -0,0 +1,131 @@
+package erlcgo
+
+import (
+	"sync"
+	"time"
+)
+
+// LifecycleEventType identifies an internal client lifecycle event.
+type LifecycleEventType string
+
+const (
+	LifecycleRequestStarted       LifecycleEventType = "request_started"
+	LifecycleRequestFinished      LifecycleEventType = "request_finished"
+	LifecycleRetry                LifecycleEventType = "retry"
+	LifecycleRateLimited          LifecycleEventType = "rate_limited"
+	LifecycleCacheHit             LifecycleEventType = "cache_hit"
+	LifecycleCacheMiss            LifecycleEventType = "cache_miss"
+	LifecycleCacheError           LifecycleEventType = "cache_error"
+	LifecycleQueueEnqueued        LifecycleEventType = "queue_enqueued"
+	LifecycleQueueDequeued        LifecycleEventType = "queue_dequeued"
+	LifecycleSubscriptionDegraded LifecycleEventType = "subscription_degraded"
+	LifecycleDeprecation          LifecycleEventType = "deprecation"
+	LifecyclePollThrottled        LifecycleEventType = "poll_throttled"
+	// LifecycleNoDeadline is published the first time a route is called
+	// with a context that has no deadline, unless
+	// WithDefaultTimeoutPerRequest is set. Such requests can wait in the
+	// queue indefinitely.
+	LifecycleNoDeadline LifecycleEventType = "no_deadline"
+)
+
+// LifecycleEvent describes something that happened inside the client.
+// Fields that do not apply to the event type are left zero.
+type LifecycleEvent struct {
+	Type       LifecycleEventType
+	Time       time.Time
+	Route      string
+	StatusCode int
+	Duration   time.Duration
+	Attempt    int
+	Err        error
+	Tenant     string // see WithTenant
+}
+
+// lifecycleBufferSize is the channel buffer for each Events() subscriber.
+// Events are dropped for subscribers that fall this far behind.
+const lifecycleBufferSize = 64
+
+// eventBus fans lifecycle events out to subscribers without ever blocking
+// the request path. The zero value is ready to use.
+type eventBus struct {
+	mu     sync.RWMutex
+	subs   map[int]chan LifecycleEvent
+	nextID int
+	closed bool
+}
+
+func (b *eventBus) subscribe() (<-chan LifecycleEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan LifecycleEvent, lifecycleBufferSize)
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	if b.subs == nil {
+		b.subs = make(map[int]chan LifecycleEvent)
+	}
+	id := b.nextID
+	b.nextID++
+	b.subs[id] = ch
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if sub, ok := b.subs[id]; ok {
+				delete(b.subs, id)
+				close(sub)
+			}
+		})
+	}
+}
+
+func (b *eventBus) publish(e LifecycleEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if len(b.subs) == 0 {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	for _, ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+func (b *eventBus) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for id, ch := range b.subs {
+		delete(b.subs, id)
+		close(ch)
+	}
+}
+
+// Events subscribes to the client's internal lifecycle events: requests,
+// retries, rate limits, cache hits and misses, queue activity and degraded
+// subscriptions. The returned function unsubscribes and closes the channel.
+// Events are delivered on a best-effort basis; a subscriber that does not keep
+// up will miss events rather than slow down requests.
+//
+// Example:
+//
+//	events, unsubscribe := client.Events()
+//	defer unsubscribe()
+//	for e := range events {
+//	    log.Printf("%s %s %v", e.Type, e.Route, e.Err)
+//	}
+func (c *Client) Events() (<-chan LifecycleEvent, func()) {
+	return c.bus.subscribe()
+}
@@ -0,0 +1,71 @@
+package erlcgo
+
+// dedupWindow remembers the last size entry IDs it was given.
+type dedupWindow struct {
+	size  int
+	order []string
+	seen  map[string]struct{}
+}
+
+func newDedupWindow(size int) *dedupWindow {
+	return &dedupWindow{size: size, seen: make(map[string]struct{}, size)}
+}
+
+// add records id and reports whether it was new.
+func (w *dedupWindow) add(id string) bool {
+	if _, ok := w.seen[id]; ok {
+		return false
+	}
+	if len(w.order) >= w.size {
+		delete(w.seen, w.order[0])
+		w.order = w.order[1:]
+	}
+	w.order = append(w.order, id)
+	w.seen[id] = struct{}{}
+	return true
+}
+
+// dedupFor returns the dedup window for a log, or nil if dedup is off.
+func (s *lastState) dedupFor(eventType EventType, size int) *dedupWindow {
+	if size <= 0 {
+		return nil
+	}
+	if s.dedup == nil {
+		s.dedup = make(map[EventType]*dedupWindow)
+	}
+	w, ok := s.dedup[eventType]
+	if !ok {
+		w = newDedupWindow(size)
+		s.dedup[eventType] = w
+	}
+	return w
+}
+
+// seedDedup marks the log entries at or before each type's watermark as
+// seen, oldest first so the newest stay in the window. Entries after a
+// watermark restored from a checkpoint are left for the next poll to deliver.
+func (s *lastState) seedDedup(resp *ERLCServerResponse, opts ServerQueryOptions, size int) {
+	if size <= 0 {
+		return
+	}
+	if opts.CommandLogs {
+		seedWindow(s.dedupFor(EventTypeCommands, size), resp.CommandLogs, s.commandTime)
+	}
+	if opts.ModCalls {
+		seedWindow(s.dedupFor(EventTypeModCalls, size), resp.ModCalls, s.modCallTime)
+	}
+	if opts.KillLogs {
+		seedWindow(s.dedupFor(EventTypeKills, size), resp.KillLogs, s.killTime)
+	}
+	if opts.JoinLogs {
+		seedWindow(s.dedupFor(EventTypeJoins, size), resp.JoinLogs, s.joinTime)
+	}
+}
+
+func seedWindow[T interface{ ID() string }](w *dedupWindow, logs []T, watermark int64) {
+	for i := len(logs) - 1; i >= 0; i-- {
+		if logTimestamp(logs[i]) <= watermark {
+			w.add(logs[i].ID())
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package erlcgo
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// LogFilter narrows log entries by time, player and count. The zero value
+// matches everything. It is accepted by the Client and Repository log
+// getters and can be applied to already fetched logs with its methods.
+type LogFilter struct {
+	// Since drops entries at or before this time. Zero means no lower bound.
+	Since time.Time
+
+	// Until drops entries after this time. Zero means no upper bound.
+	Until time.Time
+
+	// Player keeps only entries involving this player, given as "Name:ID",
+	// a name (case-insensitive) or a user ID. For kills either side
+	// matches; for mod calls the caller or the moderator.
+	Player string
+
+	// MaxResults keeps only the newest MaxResults entries. Zero means no
+	// limit.
+	MaxResults int
+
+	// Cursor, when set, drops the entries already returned for it and is
+	// moved past the entries returned, so polling with the same cursor
+	// returns each entry once. Entries left out by MaxResults are skipped
+	// for good. A cursor must not be shared by concurrent calls.
+	Cursor *LogCursor
+}
+
+// LogCursor marks how far a caller has read a log. Several entries can share
+// a second, so besides the newest timestamp returned it records the IDs of the
+// entries returned at that timestamp; entries logged later in the same second
+// are still returned on the next call. The zero value starts from the
+// beginning of the log.
+//
+// Example:
+//
+//	var cursor erlcgo.LogCursor
+//	for range ticker.C {
+//	    logs, err := client.GetCommandLogs(ctx, erlcgo.LogFilter{Cursor: &cursor})
+//	    if err != nil {
+//	        continue
+//	    }
+//	    for _, l := range logs {
+//	        fmt.Println(l.Player, l.Command)
+//	    }
+//	}
+type LogCursor struct {
+	// Timestamp is the newest entry timestamp returned, in Unix seconds.
+	Timestamp int64
+
+	// Seen holds the IDs of the entries returned at Timestamp.
+	Seen []string
+}
+
+// clone returns a copy of c that can be advanced without changing c.
+func (c LogCursor) clone() LogCursor {
+	c.Seen = append([]string(nil), c.Seen...)
+	return c
+}
+
+// returned reports whether the entry with the normalized timestamp ts and id
+// was already returned for c.
+func (c *LogCursor) returned(ts int64, id string) bool {
+	if ts != c.Timestamp {
+		return ts < c.Timestamp
+	}
+	for _, seen := range c.Seen {
+		if seen == id {
+			return true
+		}
+	}
+	return false
+}
+
+// advance moves c past the entry with the normalized timestamp ts and id.
+func (c *LogCursor) advance(ts int64, id string) {
+	switch {
+	case ts > c.Timestamp:
+		c.Timestamp = ts
+		c.Seen = []string{id}
+	case ts == c.Timestamp:
+		c.Seen = append(c.Seen, id)
+	}
+}
+
+// CommandLogs returns the command log entries matching f, in their original
+// order.
+//
+// Example:
+//
+//	f := erlcgo.LogFilter{Since: time.Now().Add(-time.Hour), Player: "Player1"}
+//	recent := f.CommandLogs(resp.CommandLogs)
+func (f LogFilter) CommandLogs(logs []ERLCCommandLog) []ERLCCommandLog {
+	return applyLogFilter(logs, f, commandLogPlayers)
+}
+
+// KillLogs returns the kill log entries matching f. See CommandLogs.
+func (f LogFilter) KillLogs(logs []ERLCKillLog) []ERLCKillLog {
+	return applyLogFilter(logs, f, killLogPlayers)
+}
+
+// JoinLogs returns the join log entries matching f. See CommandLogs.
+func (f LogFilter) JoinLogs(logs []ERLCJoinLog) []ERLCJoinLog {
+	return applyLogFilter(logs, f, joinLogPlayers)
+}
+
+// ModCalls returns the mod call entries matching f. See CommandLogs.
+func (f LogFilter) ModCalls(logs []ERLCModCallLog) []ERLCModCallLog {
+	return applyLogFilter(logs, f, modCallPlayers)
+}
+
+// GetCommandLogs fetches the command log and returns the entries matching
+// filter. PRC has no server-side log filters, so the whole log is fetched.
+//
+// Example:
+//
+//	logs, err := client.GetCommandLogs(ctx, erlcgo.LogFilter{
+//	    Since:      time.Now().Add(-15 * time.Minute),
+//	    MaxResults: 50,
+//	})
+func (c *Client) GetCommandLogs(ctx context.Context, filter LogFilter) ([]ERLCCommandLog, error) {
+	resp, err := c.GetServer(ctx, ServerQueryOptions{CommandLogs: true})
+	if err != nil {
+		return nil, err
+	}
+	return filter.CommandLogs(resp.CommandLogs), nil
+}
+
+// GetKillLogs fetches the kill log and returns the entries matching filter.
+// See GetCommandLogs.
+func (c *Client) GetKillLogs(ctx context.Context, filter LogFilter) ([]ERLCKillLog, error) {
+	resp, err := c.GetServer(ctx, ServerQueryOptions{KillLogs: true})
+	if err != nil {
+		return nil, err
+	}
+	return filter.KillLogs(resp.KillLogs), nil
+}
+
+// GetJoinLogs fetches the join log and returns the entries matching filter.
+// See GetCommandLogs.
+func (c *Client) GetJoinLogs(ctx context.Context, filter LogFilter) ([]ERLCJoinLog, error) {
+	resp, err := c.GetServer(ctx, ServerQueryOptions{JoinLogs: true})
+	if err != nil {
+		return nil, err
+	}
+	return filter.JoinLogs(resp.JoinLogs), nil
+}
+
+// GetModCalls fetches the mod call log and returns the entries matching
+// filter. See GetCommandLogs.
+func (c *Client) GetModCalls(ctx context.Context, filter LogFilter) ([]ERLCModCallLog, error) {
+	resp, err := c.GetServer(ctx, ServerQueryOptions{ModCalls: true})
+	if err != nil {
+		return nil, err
+	}
+	return filter.ModCalls(resp.ModCalls), nil
+}
+
+func commandLogPlayers(l ERLCCommandLog) []string { return []string{l.Player} }
+func killLogPlayers(l ERLCKillLog) []string       { return []string{l.Killer, l.Killed} }
+func joinLogPlayers(l ERLCJoinLog) []string       { return []string{l.Player} }
+func modCallPlayers(l ERLCModCallLog) []string    { return []string{l.Caller, l.Moderator} }
+
+// matches reports whether an entry with the timestamp ts involving players
+// passes the time and player filters of f.
+func (f LogFilter) matches(ts int64, players []string) bool {
+	ts = NormalizeTimestamp(ts)
+	if !f.Since.IsZero() && ts <= f.Since.Unix() {
+		return false
+	}
+	if !f.Until.IsZero() && ts > f.Until.Unix() {
+		return false
+	}
+	return f.Player == "" || involvesPlayer(players, f.Player)
+}
+
+// applyLogFilter filters logs by f, preserving their order, and moves its
+// cursor past the entries returned.
+func applyLogFilter[T interface{ ID() string }](logs []T, f LogFilter, players func(T) []string) []T {
+	out := make([]T, 0, len(logs))
+	for _, l := range logs {
+		ts := logTimestamp(l)
+		if !f.matches(ts, players(l)) {
+			continue
+		}
+		if f.Cursor != nil && f.Cursor.returned(ts, l.ID()) {
+			continue
+		}
+		out = append(out, l)
+	}
+	if f.MaxResults > 0 && len(out) > f.MaxResults {
+		out = newest(out, f.MaxResults)
+	}
+	if f.Cursor != nil {
+		for _, l := range out {
+			f.Cursor.advance(logTimestamp(l), l.ID())
+		}
+	}
+	return out
+}
+
+// newest returns the n newest entries of logs, in their original order.
+func newest[T any](logs []T, n int) []T {
+	idx := make([]int, len(logs))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return logTimestamp(logs[idx[a]]) > logTimestamp(logs[idx[b]]) })
+	keep := make([]bool, len(logs))
+	for _, i := range idx[:n] {
+		keep[i] = true
+	}
+	limited := make([]T, 0, n)
+	for i, l := range logs {
+		if keep[i] {
+			limited = append(limited, l)
+		}
+	}
+	return limited
+}
+
+func involvesPlayer(players []string, target string) bool {
+	for _, p := range players {
+		if p != "" && (p == target || playerNameMatches(p, target)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package erlcgo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestLogFilterCursorKeepsEntriesSharingTheMark(t *testing.T) {
+	var mu sync.Mutex
+	var logs []ERLCCommandLog
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ERLCServerResponse{CommandLogs: logs})
+	}))
+	defer srv.Close()
+	c := NewClient("key", WithBaseURL(srv.URL))
+	defer c.Close()
+
+	var cursor LogCursor
+	poll := func(add ...ERLCCommandLog) []ERLCCommandLog {
+		t.Helper()
+		mu.Lock()
+		logs = append(add, logs...)
+		mu.Unlock()
+		got, err := c.GetCommandLogs(context.Background(), LogFilter{Cursor: &cursor})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	got := poll(
+		ERLCCommandLog{Player: "A:1", Timestamp: 100, Command: ":h one"},
+		ERLCCommandLog{Player: "A:1", Timestamp: 99, Command: ":h zero"},
+	)
+	if len(got) != 2 || cursor.Timestamp != 100 {
+		t.Fatalf("first poll: %+v, cursor %+v", got, cursor)
+	}
+
+	// A second command logged in the same second after the first poll.
+	got = poll(ERLCCommandLog{Player: "B:2", Timestamp: 100, Command: ":h two"})
+	if len(got) != 1 || got[0].Command != ":h two" {
+		t.Fatalf("entry sharing the mark: got %+v, want only :h two", got)
+	}
+	if len(cursor.Seen) != 2 {
+		t.Errorf("cursor %+v, want both entries at 100 seen", cursor)
+	}
+
+	if got = poll(); len(got) != 0 {
+		t.Errorf("poll without new entries returned %+v", got)
+	}
+}
+
+func TestLogFilterCursorWithFilters(t *testing.T) {
+	logs := []ERLCKillLog{
+		{Killer: "A:1", Killed: "B:2", Timestamp: 103},
+		{Killer: "C:3", Killed: "D:4", Timestamp: 102},
+		{Killer: "A:1", Killed: "D:4", Timestamp: 101},
+		{Killer: "A:1", Killed: "C:3", Timestamp: 100},
+	}
+	var cursor LogCursor
+	f := LogFilter{Player: "A", MaxResults: 2, Cursor: &cursor}
+
+	got := f.KillLogs(logs[1:])
+	if len(got) != 2 || got[0].Timestamp != 101 || got[1].Timestamp != 100 {
+		t.Fatalf("first call: %+v, want A's kills at 101 and 100", got)
+	}
+	got = f.KillLogs(logs)
+	if len(got) != 1 || got[0].Timestamp != 103 {
+		t.Fatalf("second call: %+v, want only A's kill at 103", got)
+	}
+	if cursor.Timestamp != 103 {
+		t.Errorf("cursor at %d, want 103", cursor.Timestamp)
+	}
+}
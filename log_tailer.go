@@ -0,0 +1,325 @@
+package erlcgo
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LogKind selects the log a LogTailer follows.
+type LogKind int
+
+const (
+	LogKindCommands LogKind = iota
+	LogKindKills
+	LogKindModCalls
+	LogKindJoins
+)
+
+// eventType maps a log kind to the subscription event type, which is also
+// used to key its checkpoints.
+func (k LogKind) eventType() EventType {
+	switch k {
+	case LogKindKills:
+		return EventTypeKills
+	case LogKindModCalls:
+		return EventTypeModCalls
+	case LogKindJoins:
+		return EventTypeJoins
+	}
+	return EventTypeCommands
+}
+
+// LogEntry is a single log line delivered by a LogTailer. Data holds an
+// ERLCCommandLog, ERLCKillLog, ERLCModCallLog or ERLCJoinLog depending on Kind.
+type LogEntry struct {
+	Kind LogKind
+
+	// Timestamp is when the entry was logged, in Unix seconds whatever unit
+	// PRC sent.
+	Timestamp int64
+
+	Data interface{}
+}
+
+// ID returns the content hash of the log line in Data, as its ID method
+// does, or an empty string for data without one.
+func (e LogEntry) ID() string {
+	if d, ok := e.Data.(interface{ ID() string }); ok {
+		return d.ID()
+	}
+	return ""
+}
+
+// players returns the players involved in the log line in Data.
+func (e LogEntry) players() []string {
+	switch d := e.Data.(type) {
+	case ERLCCommandLog:
+		return commandLogPlayers(d)
+	case ERLCKillLog:
+		return killLogPlayers(d)
+	case ERLCJoinLog:
+		return joinLogPlayers(d)
+	case ERLCModCallLog:
+		return modCallPlayers(d)
+	}
+	return nil
+}
+
+// LogTailerOptions configures a LogTailer. The zero value is usable.
+type LogTailerOptions struct {
+	// PollInterval is the time between polls. Defaults to two seconds.
+	PollInterval time.Duration
+
+	// BufferSize is the capacity of the Entries channel. Defaults to 100.
+	BufferSize int
+
+	// MaxBackoff caps the delay between polls after consecutive errors.
+	// Defaults to one minute.
+	MaxBackoff time.Duration
+
+	// FromStart delivers entries already in the log when the tailer starts.
+	// By default only entries that appear afterwards are delivered.
+	// Ignored when a checkpoint is found.
+	FromStart bool
+
+	// Filter narrows the entries delivered by time and player. Its
+	// MaxResults and Cursor are ignored; the tailer keeps its own position.
+	Filter LogFilter
+
+	// CheckpointStore, when set, persists the tailer's position so it resumes
+	// after a restart without redelivering entries.
+	CheckpointStore CheckpointStore
+
+	// ErrorHandler is called with poll and checkpoint errors.
+	ErrorHandler func(error)
+}
+
+// LogTailer follows a single PRC log and delivers each new entry on its own,
+// oldest first. It is a lighter alternative to Subscribe for bots that only
+// care about one log.
+//
+// Example:
+//
+//	tailer := erlcgo.NewLogTailer(client, erlcgo.LogKindKills, erlcgo.LogTailerOptions{})
+//	defer tailer.Close()
+//	for entry := range tailer.Entries {
+//	    kill := entry.Data.(erlcgo.ERLCKillLog)
+//	    fmt.Printf("%s killed %s\n", kill.Killer, kill.Killed)
+//	}
+type LogTailer struct {
+	Entries <-chan LogEntry
+
+	client  *Client
+	kind    LogKind
+	opts    LogTailerOptions
+	entries chan LogEntry
+	done    chan struct{}
+	once    sync.Once
+
+	// mark is the newest timestamp delivered; seen holds the entries at that
+	// timestamp, since several log lines can share one second.
+	mark int64
+	seen map[string]struct{}
+}
+
+// NewLogTailer starts tailing the given log. The tailer runs until Close is
+// called, after which Entries is closed.
+func NewLogTailer(client *Client, kind LogKind, opts LogTailerOptions) *LogTailer {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 2 * time.Second
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 100
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = time.Minute
+	}
+
+	entries := make(chan LogEntry, opts.BufferSize)
+	t := &LogTailer{
+		Entries: entries,
+		client:  client,
+		kind:    kind,
+		opts:    opts,
+		entries: entries,
+		done:    make(chan struct{}),
+		seen:    make(map[string]struct{}),
+	}
+	spawn(client, "log tailer", t.run)
+	return t
+}
+
+// Close stops the tailer. It is safe to call more than once.
+func (t *LogTailer) Close() {
+	t.once.Do(func() { close(t.done) })
+}
+
+func (t *LogTailer) run() {
+	defer close(t.entries)
+
+	ctx, cancel := context.WithCancel(pollContext(context.Background()))
+	defer cancel()
+	spawn(t.client, "log tailer stop", func() {
+		<-t.done
+		cancel()
+	})
+
+	// Tailers keep their own checkpoints: they also record the entries seen at
+	// the newest timestamp, which subscriptions do not.
+	key := checkpointKey(t.client.apiKey, t.kind.eventType()) + ":tail"
+	skipExisting := !t.opts.FromStart
+	if t.opts.CheckpointStore != nil {
+		cp, ok, err := t.opts.CheckpointStore.Get(ctx, key)
+		if err != nil {
+			t.report(err)
+		} else if ok {
+			if cp, err = MigrateCheckpoint(cp); err != nil {
+				// Refuse to run rather than overwrite state from another release.
+				t.report(err)
+				return
+			}
+			t.mark = NormalizeTimestamp(cp.Timestamp)
+			for _, k := range cp.Keys {
+				t.seen[k] = struct{}{}
+			}
+			skipExisting = false
+		}
+	}
+
+	failures := 0
+	for {
+		wait := t.opts.PollInterval
+		entries, err := t.fetch(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			t.report(err)
+			failures++
+			wait = t.backoff(err, failures)
+		} else {
+			failures = 0
+			fresh := t.advance(entries)
+			if skipExisting {
+				fresh = nil
+				skipExisting = false
+			}
+			for _, e := range fresh {
+				select {
+				case t.entries <- e:
+				case <-t.done:
+					return
+				}
+			}
+			if len(fresh) > 0 && t.opts.CheckpointStore != nil {
+				if err := t.opts.CheckpointStore.Set(ctx, key, Checkpoint{Version: SchemaVersion, Timestamp: t.mark, Keys: sortedKeys(t.seen)}); err != nil {
+					t.report(err)
+				}
+			}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-t.done:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// backoff returns the delay before the next poll after consecutive failures,
+// honoring any reset time PRC reported for a rate limit. Other delays are
+// jittered so tailers that failed together do not retry together.
+func (t *LogTailer) backoff(err error, failures int) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if d := retryAfterOf(apiErr); d > 0 {
+			return d
+		}
+	}
+	wait := t.opts.PollInterval
+	for i := 1; i < failures && wait < t.opts.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > t.opts.MaxBackoff {
+		wait = t.opts.MaxBackoff
+	}
+	return t.client.jitter(wait)
+}
+
+// advance returns the entries not yet delivered that match the tailer's
+// filter, oldest first, and moves the tailer's position past all new entries.
+func (t *LogTailer) advance(entries []LogEntry) []LogEntry {
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Timestamp < entries[j].Timestamp })
+
+	fresh := make([]LogEntry, 0)
+	for _, e := range entries {
+		if e.Timestamp < t.mark {
+			continue
+		}
+		id := e.ID()
+		if e.Timestamp == t.mark {
+			if _, ok := t.seen[id]; ok {
+				continue
+			}
+		} else {
+			t.mark = e.Timestamp
+			t.seen = make(map[string]struct{})
+		}
+		t.seen[id] = struct{}{}
+		if t.opts.Filter.matches(e.Timestamp, e.players()) {
+			fresh = append(fresh, e)
+		}
+	}
+	return fresh
+}
+
+func (t *LogTailer) fetch(ctx context.Context) ([]LogEntry, error) {
+	var opts ServerQueryOptions
+	switch t.kind {
+	case LogKindCommands:
+		opts.CommandLogs = true
+	case LogKindKills:
+		opts.KillLogs = true
+	case LogKindModCalls:
+		opts.ModCalls = true
+	case LogKindJoins:
+		opts.JoinLogs = true
+	}
+
+	resp, err := t.client.GetServer(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []LogEntry
+	switch t.kind {
+	case LogKindCommands:
+		for _, l := range resp.CommandLogs {
+			entries = append(entries, LogEntry{Kind: t.kind, Timestamp: logTimestamp(l), Data: l})
+		}
+	case LogKindKills:
+		for _, l := range resp.KillLogs {
+			entries = append(entries, LogEntry{Kind: t.kind, Timestamp: logTimestamp(l), Data: l})
+		}
+	case LogKindModCalls:
+		for _, l := range resp.ModCalls {
+			entries = append(entries, LogEntry{Kind: t.kind, Timestamp: logTimestamp(l), Data: l})
+		}
+	case LogKindJoins:
+		for _, l := range resp.JoinLogs {
+			entries = append(entries, LogEntry{Kind: t.kind, Timestamp: logTimestamp(l), Data: l})
+		}
+	}
+	return entries, nil
+}
+
+func (t *LogTailer) report(err error) {
+	if t.opts.ErrorHandler != nil {
+		t.opts.ErrorHandler(err)
+	}
+}
@@ -0,0 +1,39 @@
+package erlcgo
+
+import "testing"
+
+func killEntry(killer string, ts int64) LogEntry {
+	return LogEntry{Kind: LogKindKills, Timestamp: ts, Data: ERLCKillLog{Killer: killer, Killed: "Victim:1", Timestamp: ts}}
+}
+
+func TestLogTailerAdvanceKeysByID(t *testing.T) {
+	tl := &LogTailer{seen: make(map[string]struct{})}
+
+	first := tl.advance([]LogEntry{killEntry("A:1", 100), killEntry("B:2", 100)})
+	if len(first) != 2 {
+		t.Fatalf("first poll delivered %d entries, want 2", len(first))
+	}
+	for _, e := range first {
+		if _, ok := tl.seen[e.ID()]; !ok {
+			t.Errorf("entry %v not recorded by its ID", e.Data)
+		}
+	}
+
+	// The next poll repeats both entries and adds one in the same second.
+	next := tl.advance([]LogEntry{killEntry("C:3", 100), killEntry("B:2", 100), killEntry("A:1", 100)})
+	if len(next) != 1 || next[0].Data.(ERLCKillLog).Killer != "C:3" {
+		t.Errorf("second poll delivered %v, want only C:3", next)
+	}
+}
+
+func TestLogTailerAdvanceFilter(t *testing.T) {
+	tl := &LogTailer{seen: make(map[string]struct{}), opts: LogTailerOptions{Filter: LogFilter{Player: "B"}}}
+
+	fresh := tl.advance([]LogEntry{killEntry("A:1", 100), killEntry("B:2", 101)})
+	if len(fresh) != 1 || fresh[0].Data.(ERLCKillLog).Killer != "B:2" {
+		t.Errorf("delivered %v, want only B:2", fresh)
+	}
+	if tl.mark != 101 {
+		t.Errorf("mark %d, want 101; filtered entries still move the position", tl.mark)
+	}
+}
@@ -0,0 +1,74 @@
+package erlcgo
+
+import (
+	"context"
+	"sort"
+)
+
+// GetCommandLogsSince returns command log entries not yet returned for
+// cursor, oldest first, along with the cursor to pass on the next call. It is
+// GetCommandLogs with LogFilter{Cursor: &cursor}, for callers that keep the
+// cursor as a value; the method keeps no state and is safe to call
+// concurrently. On error the cursor is returned unchanged.
+//
+// Example:
+//
+//	var cursor erlcgo.LogCursor
+//	for range ticker.C {
+//	    logs, next, err := client.GetCommandLogsSince(ctx, cursor)
+//	    if err != nil {
+//	        continue
+//	    }
+//	    cursor = next
+//	    for _, l := range logs {
+//	        fmt.Println(l.Player, l.Command)
+//	    }
+//	}
+func (c *Client) GetCommandLogsSince(ctx context.Context, cursor LogCursor) ([]ERLCCommandLog, LogCursor, error) {
+	next := cursor.clone()
+	logs, err := c.GetCommandLogs(ctx, LogFilter{Cursor: &next})
+	if err != nil {
+		return nil, cursor, err
+	}
+	return oldestFirst(logs), next, nil
+}
+
+// GetKillLogsSince returns kill log entries not yet returned for cursor and
+// the next cursor. See GetCommandLogsSince.
+func (c *Client) GetKillLogsSince(ctx context.Context, cursor LogCursor) ([]ERLCKillLog, LogCursor, error) {
+	next := cursor.clone()
+	logs, err := c.GetKillLogs(ctx, LogFilter{Cursor: &next})
+	if err != nil {
+		return nil, cursor, err
+	}
+	return oldestFirst(logs), next, nil
+}
+
+// GetModCallsSince returns mod call entries not yet returned for cursor and
+// the next cursor. See GetCommandLogsSince.
+func (c *Client) GetModCallsSince(ctx context.Context, cursor LogCursor) ([]ERLCModCallLog, LogCursor, error) {
+	next := cursor.clone()
+	logs, err := c.GetModCalls(ctx, LogFilter{Cursor: &next})
+	if err != nil {
+		return nil, cursor, err
+	}
+	return oldestFirst(logs), next, nil
+}
+
+// GetJoinLogsSince returns join and leave entries not yet returned for
+// cursor and the next cursor. See GetCommandLogsSince.
+func (c *Client) GetJoinLogsSince(ctx context.Context, cursor LogCursor) ([]ERLCJoinLog, LogCursor, error) {
+	next := cursor.clone()
+	logs, err := c.GetJoinLogs(ctx, LogFilter{Cursor: &next})
+	if err != nil {
+		return nil, cursor, err
+	}
+	return oldestFirst(logs), next, nil
+}
+
+// oldestFirst sorts logs by timestamp, keeping the order of entries that
+// share a second.
+func oldestFirst[T any](logs []T) []T {
+	sort.SliceStable(logs, func(i, j int) bool { return logTimestamp(logs[i]) < logTimestamp(logs[j]) })
+	return logs
+}
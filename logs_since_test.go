@@ -0,0 +1,61 @@
+package erlcgo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestLogsSinceKeepsEntriesSharingTheMark(t *testing.T) {
+	var mu sync.Mutex
+	var logs []ERLCCommandLog
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ERLCServerResponse{CommandLogs: logs})
+	}))
+	defer srv.Close()
+	c := NewClient("key", WithBaseURL(srv.URL))
+	defer c.Close()
+
+	poll := func(cursor LogCursor, add ...ERLCCommandLog) ([]ERLCCommandLog, LogCursor) {
+		t.Helper()
+		mu.Lock()
+		logs = append(add, logs...)
+		mu.Unlock()
+		got, next, err := c.GetCommandLogsSince(context.Background(), cursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got, next
+	}
+
+	got, cursor := poll(LogCursor{},
+		ERLCCommandLog{Player: "A:1", Timestamp: 100, Command: ":h one"},
+		ERLCCommandLog{Player: "A:1", Timestamp: 99, Command: ":h zero"},
+	)
+	if len(got) != 2 || got[0].Command != ":h zero" || cursor.Timestamp != 100 {
+		t.Fatalf("first poll: %+v, cursor %+v", got, cursor)
+	}
+
+	// A second command logged in the same second after the first poll.
+	before := cursor
+	got, cursor = poll(cursor, ERLCCommandLog{Player: "B:2", Timestamp: 100, Command: ":h two"})
+	if len(got) != 1 || got[0].Command != ":h two" {
+		t.Fatalf("entry sharing the mark: got %+v, want only :h two", got)
+	}
+	if len(cursor.Seen) != 2 {
+		t.Errorf("cursor %+v, want both entries at 100 seen", cursor)
+	}
+	if len(before.Seen) != 1 {
+		t.Errorf("the cursor passed in was changed to %+v", before)
+	}
+
+	if got, _ = poll(cursor); len(got) != 0 {
+		t.Errorf("poll without new entries returned %+v", got)
+	}
+}
@@ -0,0 +1,235 @@
+package erlcgo
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// ClientManager manages clients for many servers that share defaults, such
+// as one hosting panel or bot serving a fleet of communities.
+//
+// Example:
+//
+//	m := erlcgo.NewClientManager(
+//	    []erlcgo.ClientOption{erlcgo.WithQueue(sharedQueue)},
+//	    erlcgo.WithDecodeWorkers(4),
+//	)
+//	defer m.Close()
+//	m.Add("main", mainKey)
+//	m.Add("training", trainingKey)
+//
+//	snap := m.Snapshot(ctx, erlcgo.ServerQueryOptions{Players: true})
+//	for name, server := range snap.Servers {
+//	    fmt.Printf("%s: %d players\n", name, len(server.Players))
+//	}
+type ClientManager struct {
+	defaults   []ClientOption
+	decodePool *DecodePool
+
+	mu      sync.RWMutex
+	clients map[string]*Client
+
+	errMu  sync.Mutex
+	errors map[string]managerError
+
+	ring   *hashRing
+	shards []*managerShard
+
+	// players holds the last known player count per server, used to
+	// prioritize busy servers when planning snapshots.
+	playersMu sync.Mutex
+	players   map[string]int
+}
+
+// ManagerOption configures a ClientManager.
+type ManagerOption func(*ClientManager)
+
+// WithDecodeWorkers decodes every managed client's responses on a shared pool
+// of n workers, so snapshotting a large fleet cannot saturate the CPU.
+// Zero uses GOMAXPROCS.
+func WithDecodeWorkers(n int) ManagerOption {
+	return func(m *ClientManager) {
+		m.decodePool = NewDecodePool(n)
+	}
+}
+
+// NewClientManager creates a manager whose clients are created with defaults.
+func NewClientManager(defaults []ClientOption, opts ...ManagerOption) *ClientManager {
+	m := &ClientManager{
+		defaults: defaults,
+		clients:  make(map[string]*Client),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.shards == nil {
+		WithShards(1)(m)
+	}
+	return m
+}
+
+// Add creates a client for a server under name, replacing and closing any
+// existing client with that name. Options are applied after the defaults,
+// so per-server settings such as ServerOverrides.Option take precedence.
+func (m *ClientManager) Add(name, apiKey string, opts ...ClientOption) *Client {
+	all := make([]ClientOption, 0, len(m.defaults)+len(opts)+1)
+	all = append(all, m.defaults...)
+	if m.decodePool != nil {
+		all = append(all, WithDecodePool(m.decodePool))
+	}
+	all = append(all, opts...)
+	c := NewClient(apiKey, all...)
+
+	m.mu.Lock()
+	old := m.clients[name]
+	m.clients[name] = c
+	m.mu.Unlock()
+
+	if old != nil {
+		m.unshard(name, old)
+		old.Close()
+	}
+	shard := m.shardFor(apiKey)
+	shard.mu.Lock()
+	shard.names[name] = c
+	shard.mu.Unlock()
+	return c
+}
+
+// AddServer registers a server at runtime for panels that identify servers
+// by key. The server is named by a short hash of its key, returned as name,
+// so the secret never appears in Names, Health, snapshots or Poll handlers.
+func (m *ClientManager) AddServer(key string, opts ...ClientOption) (name string, c *Client) {
+	name = serverID(key)
+	return name, m.Add(name, key, opts...)
+}
+
+// RemoveServer tears down a server added with AddServer. See Remove.
+func (m *ClientManager) RemoveServer(key string) {
+	m.Remove(serverID(key))
+}
+
+// Get returns the client registered under name.
+func (m *ClientManager) Get(name string) (*Client, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	c, ok := m.clients[name]
+	return c, ok
+}
+
+// Remove closes and forgets the client registered under name. Closing the
+// client stops its subscriptions and releases any of its requests still
+// waiting in a shared queue, so other servers are unaffected.
+func (m *ClientManager) Remove(name string) {
+	m.mu.Lock()
+	c := m.clients[name]
+	delete(m.clients, name)
+	m.mu.Unlock()
+
+	m.errMu.Lock()
+	delete(m.errors, name)
+	m.errMu.Unlock()
+
+	m.playersMu.Lock()
+	delete(m.players, name)
+	m.playersMu.Unlock()
+
+	if c != nil {
+		m.unshard(name, c)
+		c.Close()
+	}
+}
+
+// unshard removes a server from its shard if it is still registered there.
+func (m *ClientManager) unshard(name string, c *Client) {
+	shard := m.shardFor(c.apiKey)
+	shard.mu.Lock()
+	if shard.names[name] == c {
+		delete(shard.names, name)
+	}
+	shard.mu.Unlock()
+}
+
+// Names returns the names of all managed servers, sorted.
+func (m *ClientManager) Names() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	names := make([]string, 0, len(m.clients))
+	for name := range m.clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ManagerSnapshot is the result of ClientManager.Snapshot. Each server
+// appears in exactly one of Servers and Errors.
+type ManagerSnapshot struct {
+	Servers map[string]*ERLCServerResponse
+	Errors  map[string]error
+}
+
+// Snapshot fetches every managed server concurrently. Servers sharing a cache
+// are looked up with one GetMulti, and the responses fetched for the misses
+// are written back with one SetMulti.
+func (m *ClientManager) Snapshot(ctx context.Context, opts ServerQueryOptions) ManagerSnapshot {
+	m.mu.RLock()
+	clients := make(map[string]*Client, len(m.clients))
+	for name, c := range m.clients {
+		clients[name] = c
+	}
+	m.mu.RUnlock()
+
+	batch, cached := newServerBatch(ctx, clients, opts)
+	snap := ManagerSnapshot{
+		Servers: make(map[string]*ERLCServerResponse),
+		Errors:  make(map[string]error),
+	}
+	for name, resp := range cached {
+		m.recordPlayers(name, resp.CurrentPlayers)
+		snap.Servers[name] = resp
+		delete(clients, name)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, c := range clients {
+		wg.Add(1)
+		go func(name string, c *Client) {
+			defer wg.Done()
+			resp, err := batch.fetch(ctx, c)
+			if err != nil {
+				m.recordError(name, err)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				snap.Errors[name] = err
+				return
+			}
+			m.recordPlayers(name, resp.CurrentPlayers)
+			snap.Servers[name] = resp
+		}(name, c)
+	}
+	wg.Wait()
+	batch.flush(ctx)
+	return snap
+}
+
+// Close closes every managed client and the shared decode pool.
+func (m *ClientManager) Close() {
+	m.mu.Lock()
+	clients := m.clients
+	m.clients = make(map[string]*Client)
+	m.mu.Unlock()
+
+	for name, c := range clients {
+		m.unshard(name, c)
+		c.Close()
+	}
+	if m.decodePool != nil {
+		m.decodePool.Close()
+	}
+}
@@ -0,0 +1,129 @@
+package erlcgo
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ServerHealth is the status of one managed server.
+type ServerHealth struct {
+	Name      string
+	Reachable bool
+	Latency   time.Duration
+
+	// Players and MaxPlayers are zero when the server is unreachable.
+	Players    int
+	MaxPlayers int
+
+	// LastError is the most recent error seen for the server by Health or
+	// Snapshot, and LastErrorAt when it happened. They are kept after the
+	// server recovers so panels can show recent trouble.
+	LastError   error
+	LastErrorAt time.Time
+
+	// RateLimit is the tightest known rate limit bucket for the server's
+	// reads, if any response has reported one.
+	RateLimit      RateLimit
+	RateLimitKnown bool
+}
+
+// Headroom returns the fraction of the rate limit still available, from 0 to
+// 1, or 1 when no limit is known.
+func (h ServerHealth) Headroom() float64 {
+	if !h.RateLimitKnown || h.RateLimit.Limit <= 0 {
+		return 1
+	}
+	return float64(h.RateLimit.Remaining) / float64(h.RateLimit.Limit)
+}
+
+// FleetHealth is the result of ClientManager.Health.
+type FleetHealth struct {
+	Servers     []ServerHealth // sorted by name
+	Reachable   int
+	Unreachable int
+	Players     int
+	CheckedAt   time.Time
+}
+
+// Health probes every managed server concurrently and reports its status in
+// one structure, suitable for backing a hosting panel status view.
+//
+// Example:
+//
+//	health := m.Health(ctx)
+//	for _, s := range health.Servers {
+//	    fmt.Printf("%-12s up=%v players=%d headroom=%.0f%%\n", s.Name, s.Reachable, s.Players, s.Headroom()*100)
+//	}
+func (m *ClientManager) Health(ctx context.Context) FleetHealth {
+	m.mu.RLock()
+	clients := make(map[string]*Client, len(m.clients))
+	for name, c := range m.clients {
+		clients[name] = c
+	}
+	m.mu.RUnlock()
+
+	health := FleetHealth{CheckedAt: time.Now()}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, c := range clients {
+		wg.Add(1)
+		go func(name string, c *Client) {
+			defer wg.Done()
+
+			start := time.Now()
+			resp, err := c.GetServer(ctx)
+			h := ServerHealth{Name: name, Latency: time.Since(start)}
+			if err != nil {
+				m.recordError(name, err)
+			} else {
+				h.Reachable = true
+				h.Players = resp.CurrentPlayers
+				h.MaxPlayers = resp.MaxPlayers
+				m.recordPlayers(name, resp.CurrentPlayers)
+			}
+			h.LastError, h.LastErrorAt = m.lastError(name)
+			if c.rateLimiter != nil {
+				h.RateLimit, h.RateLimitKnown = c.rateLimitBudget("global")
+			}
+
+			mu.Lock()
+			health.Servers = append(health.Servers, h)
+			mu.Unlock()
+		}(name, c)
+	}
+	wg.Wait()
+
+	sort.Slice(health.Servers, func(i, j int) bool { return health.Servers[i].Name < health.Servers[j].Name })
+	for _, h := range health.Servers {
+		if h.Reachable {
+			health.Reachable++
+			health.Players += h.Players
+		} else {
+			health.Unreachable++
+		}
+	}
+	return health
+}
+
+type managerError struct {
+	err error
+	at  time.Time
+}
+
+func (m *ClientManager) recordError(name string, err error) {
+	m.errMu.Lock()
+	defer m.errMu.Unlock()
+	if m.errors == nil {
+		m.errors = make(map[string]managerError)
+	}
+	m.errors[name] = managerError{err: err, at: time.Now()}
+}
+
+func (m *ClientManager) lastError(name string) (error, time.Time) {
+	m.errMu.Lock()
+	defer m.errMu.Unlock()
+	e := m.errors[name]
+	return e.err, e.at
+}
@@ -0,0 +1,142 @@
+package erlcgo
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FleetPlan is the order and pacing ClientManager.SequencedSnapshot will use.
+type FleetPlan struct {
+	// Order lists servers in fetch order: servers last seen with players
+	// first, busiest first, then empty and never-seen servers.
+	Order []string
+	// Batch is the pacing computed from the shared rate limit budget.
+	Batch BatchPlan
+}
+
+// SnapshotProgress reports how far a SequencedSnapshot has got.
+type SnapshotProgress struct {
+	Done    int
+	Total   int
+	Server  string // the server just fetched
+	Err     error  // its error, if any
+	Elapsed time.Duration
+	// ETA is the estimated time remaining, from the plan at first and from
+	// the observed rate once servers have been fetched.
+	ETA time.Duration
+}
+
+// PlanSnapshot computes the order and pacing of a SequencedSnapshot without
+// fetching anything. When the defaults include WithGlobalAPIKey and a shared
+// WithRateLimiter, pacing follows the global key's account-wide budget, which
+// every server spends from. Otherwise it follows the first server's budget.
+func (m *ClientManager) PlanSnapshot() FleetPlan {
+	names := m.Names()
+
+	m.playersMu.Lock()
+	players := make(map[string]int, len(names))
+	for _, name := range names {
+		if n, ok := m.players[name]; ok {
+			players[name] = n
+		} else {
+			players[name] = -1
+		}
+	}
+	m.playersMu.Unlock()
+
+	sort.SliceStable(names, func(i, j int) bool { return players[names[i]] > players[names[j]] })
+
+	plan := FleetPlan{Order: names}
+	if len(names) > 0 {
+		if c, ok := m.Get(names[0]); ok {
+			plan.Batch = (&Batcher{Client: c, fleet: true}).Plan(len(names))
+		}
+	}
+	return plan
+}
+
+// SequencedSnapshot fetches every server one at a time in PlanSnapshot order,
+// paced against the shared rate limit budget, so a large fleet drains the
+// budget predictably and active servers are refreshed first. Unlike
+// Snapshot, which fires every request at once, it suits global keys shared
+// by hundreds of servers. If ctx is canceled the servers fetched so far are
+// returned along with the error.
+//
+// Example:
+//
+//	snap, err := m.SequencedSnapshot(ctx, erlcgo.ServerQueryOptions{Players: true}, func(p erlcgo.SnapshotProgress) {
+//	    log.Printf("%d/%d, about %s left", p.Done, p.Total, p.ETA.Round(time.Second))
+//	})
+func (m *ClientManager) SequencedSnapshot(ctx context.Context, opts ServerQueryOptions, progress func(SnapshotProgress)) (ManagerSnapshot, error) {
+	plan := m.PlanSnapshot()
+	snap := ManagerSnapshot{
+		Servers: make(map[string]*ERLCServerResponse),
+		Errors:  make(map[string]error),
+	}
+	if len(plan.Order) == 0 {
+		return snap, nil
+	}
+
+	var mu sync.Mutex
+	ops := make([]Operation, len(plan.Order))
+	for i, name := range plan.Order {
+		name := name
+		ops[i] = func(ctx context.Context) error {
+			c, ok := m.Get(name)
+			if !ok {
+				return nil // removed since planning
+			}
+			resp, err := c.GetServer(ctx, opts)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				m.recordError(name, err)
+				snap.Errors[name] = err
+				return err
+			}
+			m.recordPlayers(name, resp.CurrentPlayers)
+			snap.Servers[name] = resp
+			return nil
+		}
+	}
+
+	first, _ := m.Get(plan.Order[0])
+	start := time.Now()
+	b := &Batcher{Client: first, fleet: true}
+	b.OnProgress = func(done, total int, lastErr error) {
+		if progress == nil {
+			return
+		}
+		elapsed := time.Since(start)
+		eta := plan.Batch.Estimated - elapsed
+		if done > 0 {
+			eta = elapsed / time.Duration(done) * time.Duration(total-done)
+		}
+		if eta < 0 {
+			eta = 0
+		}
+		progress(SnapshotProgress{
+			Done:    done,
+			Total:   total,
+			Server:  plan.Order[done-1],
+			Err:     lastErr,
+			Elapsed: elapsed,
+			ETA:     eta,
+		})
+	}
+
+	_, err := b.Run(ctx, ops)
+	return snap, err
+}
+
+// recordPlayers remembers a server's player count for snapshot planning.
+func (m *ClientManager) recordPlayers(name string, n int) {
+	m.playersMu.Lock()
+	defer m.playersMu.Unlock()
+	if m.players == nil {
+		m.players = make(map[string]int)
+	}
+	m.players[name] = n
+}
@@ -0,0 +1,93 @@
+package erlcgo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestPlanSnapshotOrder(t *testing.T) {
+	m := NewClientManager(nil)
+	defer m.Close()
+	for _, name := range []string{"empty", "new", "quiet", "busy"} {
+		m.Add(name, "key-"+name)
+	}
+	m.recordPlayers("busy", 30)
+	m.recordPlayers("quiet", 2)
+	m.recordPlayers("empty", 0)
+
+	want := []string{"busy", "quiet", "empty", "new"}
+	if got := m.PlanSnapshot().Order; !reflect.DeepEqual(got, want) {
+		t.Errorf("order %v, want %v", got, want)
+	}
+}
+
+func TestPlanSnapshotPacesFromGlobalCeiling(t *testing.T) {
+	limiter := NewRateLimiter()
+	m := NewClientManager([]ClientOption{WithGlobalAPIKey("global"), WithRateLimiter(limiter)})
+	defer m.Close()
+	for _, name := range []string{"a", "b", "c", "d"} {
+		m.Add(name, "key-"+name)
+	}
+	// The first server's own budget is spent for ten seconds, but the fleet
+	// is paced by the account-wide one: one request now, then two a second.
+	limiter.UpdateFromHeaders(partitionKey("global", "key-a", "global"), 2, 0, time.Now().Add(10*time.Second))
+	limiter.UpdateFromHeaders(ceilingKey("global", "global"), 2, 1, time.Now().Add(time.Second))
+
+	plan := m.PlanSnapshot().Batch
+	if len(plan.Chunks) != 3 {
+		t.Fatalf("chunks %+v, want 3", plan.Chunks)
+	}
+	if plan.Estimated < 1500*time.Millisecond || plan.Estimated > 2*time.Second {
+		t.Errorf("estimated %v, want about 2s from the ceiling", plan.Estimated)
+	}
+}
+
+func TestSequencedSnapshotProgress(t *testing.T) {
+	players := map[string]int{"key-a": 0, "key-b": 5, "key-c": 12}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		key := r.Header.Get("Server-Key")
+		w.Write([]byte(`{"Name":"` + key + `","CurrentPlayers":` + strconv.Itoa(players[key]) + `}`))
+	}))
+	defer srv.Close()
+
+	limiter := NewRateLimiter()
+	limiter.UpdateFromHeaders(ceilingKey("global", "global"), 10, 10, time.Now().Add(time.Second))
+	m := NewClientManager([]ClientOption{WithBaseURL(srv.URL), WithGlobalAPIKey("global"), WithRateLimiter(limiter)})
+	defer m.Close()
+	for _, name := range []string{"a", "b", "c"} {
+		m.Add(name, "key-"+name)
+	}
+
+	var order []string
+	var last SnapshotProgress
+	snap, err := m.SequencedSnapshot(context.Background(), ServerQueryOptions{}, func(p SnapshotProgress) {
+		order = append(order, p.Server)
+		last = p
+	})
+	if err != nil || len(snap.Servers) != 3 {
+		t.Fatalf("snapshot %+v, %v", snap, err)
+	}
+	// Nothing is known yet, so the first run keeps name order.
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("first run order %v, want %v", order, want)
+	}
+	if last.Done != 3 || last.Total != 3 || last.ETA != 0 {
+		t.Errorf("last progress %+v, want 3/3 with no time left", last)
+	}
+
+	order = nil
+	if _, err := m.SequencedSnapshot(context.Background(), ServerQueryOptions{}, func(p SnapshotProgress) {
+		order = append(order, p.Server)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"c", "b", "a"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("second run order %v, want busiest first %v", order, want)
+	}
+}
@@ -0,0 +1,170 @@
+package erlcgo
+
+import (
+	"context"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// shardVirtualNodes is how many points each shard gets on the hash ring.
+// More points spread servers more evenly.
+const shardVirtualNodes = 64
+
+// WithShards splits the manager's servers across n poll scheduler shards by
+// consistent hashing of their server keys. Each shard polls its servers from
+// a single goroutine with its own lock, so goroutine counts and contention
+// stay bounded however many servers are added. Changing n moves only about
+// 1/n of servers between shards. Defaults to one shard.
+func WithShards(n int) ManagerOption {
+	return func(m *ClientManager) {
+		if n < 1 {
+			n = 1
+		}
+		m.ring = newHashRing(n)
+		m.shards = make([]*managerShard, n)
+		for i := range m.shards {
+			m.shards[i] = &managerShard{names: make(map[string]*Client)}
+		}
+	}
+}
+
+type managerShard struct {
+	mu    sync.Mutex
+	names map[string]*Client
+}
+
+func (s *managerShard) snapshot() map[string]*Client {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clients := make(map[string]*Client, len(s.names))
+	for name, c := range s.names {
+		clients[name] = c
+	}
+	return clients
+}
+
+// hashRing maps keys to shards with consistent hashing.
+type hashRing struct {
+	points []uint32
+	owners map[uint32]int
+}
+
+func newHashRing(shards int) *hashRing {
+	r := &hashRing{owners: make(map[uint32]int, shards*shardVirtualNodes)}
+	for shard := 0; shard < shards; shard++ {
+		for v := 0; v < shardVirtualNodes; v++ {
+			p := hash32(strconv.Itoa(shard) + "#" + strconv.Itoa(v))
+			r.points = append(r.points, p)
+			r.owners[p] = shard
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i] < r.points[j] })
+	return r
+}
+
+func (r *hashRing) shard(key string) int {
+	h := hash32(key)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= h })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[r.points[i]]
+}
+
+func hash32(s string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return h.Sum32()
+}
+
+// shardFor returns the shard for a server key.
+func (m *ClientManager) shardFor(apiKey string) *managerShard {
+	return m.shards[m.ring.shard(apiKey)]
+}
+
+// ShardSizes returns how many servers each shard holds.
+func (m *ClientManager) ShardSizes() []int {
+	sizes := make([]int, len(m.shards))
+	for i, s := range m.shards {
+		s.mu.Lock()
+		sizes[i] = len(s.names)
+		s.mu.Unlock()
+	}
+	return sizes
+}
+
+// PollConfig configures ClientManager.Poll.
+type PollConfig struct {
+	// Interval is the time between polls of each server. Defaults to five seconds.
+	Interval time.Duration
+
+	// Options selects the data fetched for each server.
+	Options ServerQueryOptions
+
+	// Handler receives each server's result. It runs on the shard's
+	// goroutine, so a slow handler delays the rest of that shard.
+	Handler func(name string, resp *ERLCServerResponse, err error)
+}
+
+// Poll polls every managed server on its shard's goroutine until ctx is done.
+// Servers added or removed while polling are picked up on the next round.
+// Each round reads and writes the cache in batches, as Snapshot does.
+//
+// Example:
+//
+//	m := erlcgo.NewClientManager(defaults, erlcgo.WithShards(8))
+//	go m.Poll(ctx, erlcgo.PollConfig{
+//	    Options: erlcgo.ServerQueryOptions{Players: true},
+//	    Handler: func(name string, resp *erlcgo.ERLCServerResponse, err error) {
+//	        // ...
+//	    },
+//	})
+func (m *ClientManager) Poll(ctx context.Context, config PollConfig) error {
+	ctx = pollContext(ctx)
+	if config.Interval <= 0 {
+		config.Interval = 5 * time.Second
+	}
+
+	var wg sync.WaitGroup
+	for _, shard := range m.shards {
+		wg.Add(1)
+		go func(shard *managerShard) {
+			defer wg.Done()
+			ticker := time.NewTicker(config.Interval)
+			defer ticker.Stop()
+			for {
+				clients := shard.snapshot()
+				batch, cached := newServerBatch(ctx, clients, config.Options)
+				for name, c := range clients {
+					if ctx.Err() != nil {
+						return
+					}
+					resp, ok := cached[name]
+					var err error
+					if !ok {
+						resp, err = batch.fetch(ctx, c)
+					}
+					if err != nil {
+						m.recordError(name, err)
+					} else {
+						m.recordPlayers(name, resp.CurrentPlayers)
+					}
+					if config.Handler != nil {
+						config.Handler(name, resp, err)
+					}
+				}
+				batch.flush(ctx)
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(shard)
+	}
+	wg.Wait()
+	return ctx.Err()
+}
@@ -0,0 +1,263 @@
+package erlcgo
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrModCallNotFound is returned when assigning a call that is not open.
+var ErrModCallNotFound = errors.New("erlc: mod call not found or no longer open")
+
+// ModCallStatus is where a tracked mod call is in its lifecycle.
+type ModCallStatus string
+
+const (
+	ModCallOpen      ModCallStatus = "open"      // waiting for a moderator
+	ModCallResolved  ModCallStatus = "resolved"  // PRC shows a moderator answered it
+	ModCallAbandoned ModCallStatus = "abandoned" // the caller left before anyone answered
+)
+
+// ModCall is a mod call tracked by a ModCallWorkflow.
+type ModCall struct {
+	// ID identifies the call. It stays the same when a moderator answers,
+	// unlike ERLCModCallLog.ID.
+	ID        string
+	Caller    string
+	Timestamp int64
+	Status    ModCallStatus
+
+	// Assignee and AssignedAt record the bot-side claim made with Assign.
+	Assignee   string
+	AssignedAt time.Time
+
+	// Moderator is the moderator PRC reports once the call is answered.
+	Moderator string
+
+	// Reminders is how many reminders have been sent for the call.
+	Reminders    int
+	lastReminder time.Time
+}
+
+// CalledAt returns when the call was made.
+func (m ModCall) CalledAt() time.Time {
+	return ParseTimestamp(m.Timestamp)
+}
+
+// ModCallWorkflowConfig configures a ModCallWorkflow. Callbacks run on the
+// workflow's goroutine and should not block.
+type ModCallWorkflowConfig struct {
+	// PollInterval is the time between polls. Defaults to five seconds.
+	PollInterval time.Duration
+
+	// RemindAfter is how long a call may sit unassigned before OnReminder is
+	// called, and how often it is repeated after that. Zero disables reminders.
+	RemindAfter time.Duration
+
+	OnOpen      func(ModCall)
+	OnReminder  func(ModCall)
+	OnResolved  func(ModCall)
+	OnAbandoned func(ModCall)
+
+	// ErrorHandler is called with poll errors.
+	ErrorHandler func(error)
+}
+
+// ModCallWorkflow tracks mod calls for staff dispatch bots. A call is open
+// while its caller is in the server and no moderator has answered it. Staff
+// can claim open calls with Assign; unclaimed calls trigger reminders, and a
+// call is resolved once PRC reports a moderator for it.
+//
+// Example:
+//
+//	wf := erlcgo.NewModCallWorkflow(client, erlcgo.ModCallWorkflowConfig{
+//	    RemindAfter: 3 * time.Minute,
+//	    OnOpen:      func(m erlcgo.ModCall) { announce(m) },
+//	    OnReminder:  func(m erlcgo.ModCall) { ping(m) },
+//	})
+//	go wf.Run(ctx)
+//
+//	// Later, from a button handler:
+//	err := wf.Assign(callID, "ModName")
+type ModCallWorkflow struct {
+	client *Client
+	config ModCallWorkflowConfig
+
+	mu   sync.Mutex
+	open map[string]*ModCall
+	// closed remembers calls that were resolved or abandoned while they are
+	// still in the log, so they are not reopened.
+	closed map[string]struct{}
+}
+
+// NewModCallWorkflow creates a workflow. Call Run or Poll to start tracking.
+func NewModCallWorkflow(client *Client, config ModCallWorkflowConfig) *ModCallWorkflow {
+	if config.PollInterval <= 0 {
+		config.PollInterval = 5 * time.Second
+	}
+	return &ModCallWorkflow{
+		client: client,
+		config: config,
+		open:   make(map[string]*ModCall),
+		closed: make(map[string]struct{}),
+	}
+}
+
+// Run polls until ctx is done.
+func (w *ModCallWorkflow) Run(ctx context.Context) error {
+	ctx = pollContext(ctx)
+	ticker := time.NewTicker(w.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.Poll(ctx); err != nil && w.config.ErrorHandler != nil {
+			w.config.ErrorHandler(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll fetches mod calls and players once and updates the tracked calls.
+func (w *ModCallWorkflow) Poll(ctx context.Context) error {
+	resp, err := w.client.GetServer(ctx, ServerQueryOptions{ModCalls: true, Players: true})
+	if err != nil {
+		return err
+	}
+	w.update(resp.ModCalls, resp.Players, time.Now())
+	return nil
+}
+
+// Open returns the open calls, oldest first.
+func (w *ModCallWorkflow) Open() []ModCall {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	calls := make([]ModCall, 0, len(w.open))
+	for _, m := range w.open {
+		calls = append(calls, *m)
+	}
+	sort.Slice(calls, func(i, j int) bool {
+		return NormalizeTimestamp(calls[i].Timestamp) < NormalizeTimestamp(calls[j].Timestamp)
+	})
+	return calls
+}
+
+// Assign records that staff has claimed an open call, which stops reminders.
+func (w *ModCallWorkflow) Assign(id, staff string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	m, ok := w.open[id]
+	if !ok {
+		return ErrModCallNotFound
+	}
+	m.Assignee = staff
+	m.AssignedAt = time.Now()
+	return nil
+}
+
+// Unassign releases a claim so the call is reminded about again.
+func (w *ModCallWorkflow) Unassign(id string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	m, ok := w.open[id]
+	if !ok {
+		return ErrModCallNotFound
+	}
+	m.Assignee = ""
+	m.AssignedAt = time.Time{}
+	m.lastReminder = time.Now()
+	return nil
+}
+
+func (w *ModCallWorkflow) update(logs []ERLCModCallLog, players []ERLCServerPlayer, now time.Time) {
+	present := newPlayerSetFromSlice(players)
+	var opened, reminded, resolved, abandoned []ModCall
+
+	w.mu.Lock()
+	inLog := make(map[string]struct{}, len(logs))
+	for _, l := range logs {
+		id := modCallID(l)
+		inLog[id] = struct{}{}
+		if _, done := w.closed[id]; done {
+			continue
+		}
+
+		m, tracked := w.open[id]
+		switch {
+		case l.Moderator != "" && tracked:
+			m.Moderator = l.Moderator
+			m.Status = ModCallResolved
+			resolved = append(resolved, *m)
+			w.close(id)
+		case l.Moderator != "":
+			// Answered before we saw it open.
+			w.closed[id] = struct{}{}
+		case !tracked:
+			if _, ok := present[l.Caller]; !ok {
+				w.closed[id] = struct{}{}
+				continue
+			}
+			m = &ModCall{ID: id, Caller: l.Caller, Timestamp: l.Timestamp, Status: ModCallOpen, lastReminder: ParseTimestamp(l.Timestamp)}
+			w.open[id] = m
+			opened = append(opened, *m)
+		}
+	}
+
+	for id, m := range w.open {
+		if _, ok := present[m.Caller]; !ok {
+			m.Status = ModCallAbandoned
+			abandoned = append(abandoned, *m)
+			w.close(id)
+			continue
+		}
+		if w.config.RemindAfter > 0 && m.Assignee == "" && now.Sub(m.lastReminder) >= w.config.RemindAfter {
+			m.Reminders++
+			m.lastReminder = now
+			reminded = append(reminded, *m)
+		}
+	}
+
+	for id := range w.closed {
+		if _, ok := inLog[id]; !ok {
+			delete(w.closed, id)
+		}
+	}
+	w.mu.Unlock()
+
+	notify(w.config.OnOpen, opened)
+	notify(w.config.OnReminder, reminded)
+	notify(w.config.OnResolved, resolved)
+	notify(w.config.OnAbandoned, abandoned)
+}
+
+func (w *ModCallWorkflow) close(id string) {
+	delete(w.open, id)
+	w.closed[id] = struct{}{}
+}
+
+func notify(fn func(ModCall), calls []ModCall) {
+	if fn == nil {
+		return
+	}
+	sort.Slice(calls, func(i, j int) bool {
+		return NormalizeTimestamp(calls[i].Timestamp) < NormalizeTimestamp(calls[j].Timestamp)
+	})
+	for _, m := range calls {
+		fn(m)
+	}
+}
+
+// modCallID identifies a call by who made it and when, ignoring the
+// moderator so the ID survives the call being answered.
+func modCallID(l ERLCModCallLog) string {
+	return hashFields("modcall", l.Caller, strconv.FormatInt(l.Timestamp, 10))
+}
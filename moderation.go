@@ -0,0 +1,180 @@
+package erlcgo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bmrgcorp/erlcgo/commands"
+)
+
+// ModerationAction is the kind of action in a ModerationRecord.
+type ModerationAction string
+
+const (
+	ModerationKick ModerationAction = "kick"
+	ModerationBan  ModerationAction = "ban"
+)
+
+// defaultNotifyDelay is how long KickPlayer and BanPlayer wait after telling
+// the player why, so the message is on screen before they are removed.
+const defaultNotifyDelay = 2 * time.Second
+
+// ModerationRequest describes a kick or ban for KickPlayer and BanPlayer.
+type ModerationRequest struct {
+	Player string
+	Reason string
+
+	// NotifyPlayer sends the reason to the player by PM before acting.
+	NotifyPlayer bool
+
+	// NotifyDelay is the time between the PM and the kick or ban. Defaults
+	// to two seconds.
+	NotifyDelay time.Duration
+
+	// AnnounceToServer posts a server message naming the player and reason
+	// after the action succeeds.
+	AnnounceToServer bool
+}
+
+// KickRequest describes a kick for KickPlayer.
+type KickRequest = ModerationRequest
+
+// BanRequest describes a ban for BanPlayer.
+type BanRequest = ModerationRequest
+
+// ModerationRecord is the audit record of a kick or ban. It is returned by
+// KickPlayer and BanPlayer and passed to the WithModerationAudit hook.
+type ModerationRecord struct {
+	Action ModerationAction
+	Player string
+	Reason string
+	Tenant string // see WithTenant
+	Time   time.Time
+
+	// Commands lists the commands sent, in order.
+	Commands []string
+
+	// Err is the error from the kick or ban itself; nil means it succeeded.
+	Err error
+
+	// DryRun is set when the client or context was in dry-run mode, so the
+	// commands were recorded rather than sent and nobody was removed.
+	DryRun bool
+
+	// NotifyErr and AnnounceErr are errors from the PM and the server
+	// message. They do not stop the action.
+	NotifyErr   error
+	AnnounceErr error
+}
+
+// ModerationAuditHook receives a record of every KickPlayer and BanPlayer call.
+type ModerationAuditHook func(ModerationRecord)
+
+// WithModerationAudit registers a hook called with the record of every
+// KickPlayer and BanPlayer call, successful or not, for example to write a
+// moderation log channel or database.
+//
+// Example:
+//
+//	client := NewClient("your-server-key",
+//	    WithModerationAudit(func(r ModerationRecord) {
+//	        log.Printf("%s %s (%s): %v", r.Action, r.Player, r.Reason, r.Err)
+//	    }),
+//	)
+func WithModerationAudit(h ModerationAuditHook) ClientOption {
+	return func(c *Client) {
+		c.moderationAudit = h
+	}
+}
+
+// KickPlayer kicks a player with a reason, optionally telling the player why
+// first and announcing the kick to the server afterwards. The returned
+// record lists what was sent; the error is that of the kick.
+//
+// Example:
+//
+//	_, err := client.KickPlayer(ctx, erlcgo.KickRequest{
+//	    Player:           "Player1",
+//	    Reason:           "Exploiting",
+//	    NotifyPlayer:     true,
+//	    AnnounceToServer: true,
+//	})
+func (c *Client) KickPlayer(ctx context.Context, req KickRequest) (ModerationRecord, error) {
+	return c.moderate(ctx, ModerationKick, req, commands.Kick(req.Player, req.Reason))
+}
+
+// BanPlayer bans a player, optionally telling the player why first and
+// announcing the ban to the server afterwards. The ban command takes no
+// reason, so the reason only reaches the PM, the announcement and the
+// record.
+//
+// Example:
+//
+//	rec, err := client.BanPlayer(ctx, erlcgo.BanRequest{Player: "Player1", Reason: "Cheating", NotifyPlayer: true})
+func (c *Client) BanPlayer(ctx context.Context, req BanRequest) (ModerationRecord, error) {
+	return c.moderate(ctx, ModerationBan, req, commands.Ban(req.Player))
+}
+
+func (c *Client) moderate(ctx context.Context, action ModerationAction, req ModerationRequest, cmd commands.Command) (ModerationRecord, error) {
+	rec := ModerationRecord{
+		Action: action,
+		Player: req.Player,
+		Reason: req.Reason,
+		Tenant: c.tenantOf(ctx),
+		Time:   time.Now(),
+		DryRun: c.dryRunning(ctx),
+	}
+	done := func() (ModerationRecord, error) {
+		if c.moderationAudit != nil {
+			c.moderationAudit(rec)
+		}
+		return rec, rec.Err
+	}
+	send := func(cmd commands.Command) error {
+		if err := validateBuilt(cmd); err != nil {
+			return err
+		}
+		rec.Commands = append(rec.Commands, cmd.String())
+		return c.Execute(ctx, cmd)
+	}
+
+	past := map[ModerationAction]string{ModerationKick: "kicked", ModerationBan: "banned"}[action]
+	if err := validateBuilt(cmd); err != nil {
+		rec.Err = err
+		return done()
+	}
+
+	if req.NotifyPlayer && req.Reason != "" {
+		rec.NotifyErr = send(commands.PM(req.Player, fmt.Sprintf("You are being %s: %s", past, req.Reason)))
+		if rec.NotifyErr == nil {
+			delay := req.NotifyDelay
+			if delay <= 0 {
+				delay = defaultNotifyDelay
+			}
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				rec.Err = ctx.Err()
+				return done()
+			case <-timer.C:
+			}
+		}
+	}
+
+	if rec.Err = send(cmd); rec.Err != nil {
+		return done()
+	}
+
+	if req.AnnounceToServer {
+		// The command validated, so the name sanitizes.
+		name, _ := commands.SanitizePlayerName(req.Player)
+		msg := fmt.Sprintf("%s was %s", name, past)
+		if req.Reason != "" {
+			msg += ": " + req.Reason
+		}
+		rec.AnnounceErr = send(commands.Message(msg))
+	}
+	return done()
+}
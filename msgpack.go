@@ -0,0 +1,666 @@
+package erlcgo
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MsgpackSerializer stores cache values as MessagePack, which is more compact
+// than JSON and, unlike gob, readable from other languages sharing a Redis
+// cache. Structs are encoded as maps keyed by their JSON field names, and
+// time.Time values use the MessagePack timestamp extension. The codec is
+// built in, so the module keeps no dependencies.
+type MsgpackSerializer struct{}
+
+func (MsgpackSerializer) Marshal(v interface{}) ([]byte, error) {
+	var e msgpackEncoder
+	if err := e.encode(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+func (MsgpackSerializer) Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("msgpack: cannot unmarshal into %T", v)
+	}
+	d := msgpackDecoder{data: data}
+	if err := d.decode(rv.Elem()); err != nil {
+		return err
+	}
+	if d.pos != len(d.data) {
+		return errors.New("msgpack: trailing data")
+	}
+	return nil
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+
+	errMsgpackShort = errors.New("msgpack: unexpected end of data")
+)
+
+// msgpackTimestamp is the extension type of MessagePack timestamps, -1.
+const msgpackTimestamp byte = 0xff
+
+// msgpackField is an encoded struct field.
+type msgpackField struct {
+	name  string
+	index int
+}
+
+var msgpackFieldCache sync.Map // reflect.Type -> []msgpackField
+
+// msgpackFields returns the exported fields of a struct type with the names
+// encoding/json would use, skipping fields tagged "-".
+func msgpackFields(t reflect.Type) []msgpackField {
+	if fields, ok := msgpackFieldCache.Load(t); ok {
+		return fields.([]msgpackField)
+	}
+	var fields []msgpackField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			tag, _, _ = strings.Cut(tag, ",")
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		fields = append(fields, msgpackField{name: name, index: i})
+	}
+	msgpackFieldCache.Store(t, fields)
+	return fields
+}
+
+type msgpackEncoder struct {
+	buf []byte
+}
+
+func (e *msgpackEncoder) encode(v reflect.Value) error {
+	if !v.IsValid() {
+		e.buf = append(e.buf, 0xc0)
+		return nil
+	}
+	if v.Type() == timeType {
+		e.writeTime(v.Interface().(time.Time))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			e.buf = append(e.buf, 0xc3)
+		} else {
+			e.buf = append(e.buf, 0xc2)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.writeInt(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.writeUint(v.Uint())
+	case reflect.Float32:
+		e.buf = append(e.buf, 0xca)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, math.Float32bits(float32(v.Float())))
+	case reflect.Float64:
+		e.buf = append(e.buf, 0xcb)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, math.Float64bits(v.Float()))
+	case reflect.String:
+		e.writeString(v.String())
+	case reflect.Slice:
+		if v.IsNil() {
+			e.buf = append(e.buf, 0xc0)
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			e.writeBin(v.Bytes())
+			return nil
+		}
+		return e.writeArray(v)
+	case reflect.Array:
+		return e.writeArray(v)
+	case reflect.Map:
+		if v.IsNil() {
+			e.buf = append(e.buf, 0xc0)
+			return nil
+		}
+		e.writeHeader(v.Len(), 0x80, 16, 0xde, 0xdf)
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := e.encode(iter.Key()); err != nil {
+				return err
+			}
+			if err := e.encode(iter.Value()); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		fields := msgpackFields(v.Type())
+		e.writeHeader(len(fields), 0x80, 16, 0xde, 0xdf)
+		for _, f := range fields {
+			e.writeString(f.name)
+			if err := e.encode(v.Field(f.index)); err != nil {
+				return err
+			}
+		}
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			e.buf = append(e.buf, 0xc0)
+			return nil
+		}
+		return e.encode(v.Elem())
+	default:
+		return fmt.Errorf("msgpack: unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func (e *msgpackEncoder) writeInt(i int64) {
+	switch {
+	case i >= 0:
+		e.writeUint(uint64(i))
+	case i >= -32:
+		e.buf = append(e.buf, byte(int8(i)))
+	case i >= math.MinInt8:
+		e.buf = append(e.buf, 0xd0, byte(int8(i)))
+	case i >= math.MinInt16:
+		e.buf = append(e.buf, 0xd1)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(int16(i)))
+	case i >= math.MinInt32:
+		e.buf = append(e.buf, 0xd2)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(int32(i)))
+	default:
+		e.buf = append(e.buf, 0xd3)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(i))
+	}
+}
+
+func (e *msgpackEncoder) writeUint(u uint64) {
+	switch {
+	case u <= 0x7f:
+		e.buf = append(e.buf, byte(u))
+	case u <= math.MaxUint8:
+		e.buf = append(e.buf, 0xcc, byte(u))
+	case u <= math.MaxUint16:
+		e.buf = append(e.buf, 0xcd)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(u))
+	case u <= math.MaxUint32:
+		e.buf = append(e.buf, 0xce)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(u))
+	default:
+		e.buf = append(e.buf, 0xcf)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, u)
+	}
+}
+
+// writeHeader writes a length header: the fix format for lengths below
+// fixMax, then the 16-bit and 32-bit formats.
+func (e *msgpackEncoder) writeHeader(n int, fix byte, fixMax int, f16, f32 byte) {
+	switch {
+	case n < fixMax:
+		e.buf = append(e.buf, fix|byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, f16)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, f32)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	}
+}
+
+func (e *msgpackEncoder) writeString(s string) {
+	if n := len(s); n >= 32 && n <= math.MaxUint8 {
+		e.buf = append(e.buf, 0xd9, byte(n))
+	} else {
+		e.writeHeader(n, 0xa0, 32, 0xda, 0xdb)
+	}
+	e.buf = append(e.buf, s...)
+}
+
+func (e *msgpackEncoder) writeBin(b []byte) {
+	switch n := len(b); {
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xc5)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, 0xc6)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	}
+	e.buf = append(e.buf, b...)
+}
+
+func (e *msgpackEncoder) writeArray(v reflect.Value) error {
+	e.writeHeader(v.Len(), 0x90, 16, 0xdc, 0xdd)
+	for i := 0; i < v.Len(); i++ {
+		if err := e.encode(v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTime writes t as a 96-bit timestamp, which holds any time.Time.
+func (e *msgpackEncoder) writeTime(t time.Time) {
+	e.buf = append(e.buf, 0xc7, 12, msgpackTimestamp)
+	e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(t.Nanosecond()))
+	e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(t.Unix()))
+}
+
+type msgpackDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *msgpackDecoder) read(n int) ([]byte, error) {
+	if n < 0 || len(d.data)-d.pos < n {
+		return nil, errMsgpackShort
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *msgpackDecoder) readByte() (byte, error) {
+	b, err := d.read(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+// readLen reads a big-endian length of size bytes.
+func (d *msgpackDecoder) readLen(size int) (int, error) {
+	b, err := d.read(size)
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return int(b[0]), nil
+	case 2:
+		return int(binary.BigEndian.Uint16(b)), nil
+	}
+	n := binary.BigEndian.Uint32(b)
+	// No valid value is larger than the data it is read from.
+	if uint64(n) > uint64(len(d.data)) {
+		return 0, errMsgpackShort
+	}
+	return int(n), nil
+}
+
+// decode reads the next value into v, which must be settable.
+func (d *msgpackDecoder) decode(v reflect.Value) error {
+	if d.pos >= len(d.data) {
+		return errMsgpackShort
+	}
+	if d.data[d.pos] == 0xc0 {
+		d.pos++
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	switch {
+	case v.Kind() == reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.decode(v.Elem())
+	case v.Kind() == reflect.Interface && v.NumMethod() == 0:
+		x, err := d.decodeAny()
+		if err != nil {
+			return err
+		}
+		if x == nil {
+			v.Set(reflect.Zero(v.Type()))
+		} else {
+			v.Set(reflect.ValueOf(x))
+		}
+		return nil
+	case v.Type() == timeType, v.Kind() == reflect.Bool, v.Kind() == reflect.String,
+		v.Kind() >= reflect.Int && v.Kind() <= reflect.Float64:
+		x, err := d.decodeAny()
+		if err != nil {
+			return err
+		}
+		return setScalar(v, x)
+	}
+
+	b, _ := d.readByte()
+	switch {
+	case b >= 0x90 && b <= 0x9f, b == 0xdc, b == 0xdd:
+		n, err := d.arrayLen(b)
+		if err != nil {
+			return err
+		}
+		return d.decodeArray(v, n)
+	case b >= 0x80 && b <= 0x8f, b == 0xde, b == 0xdf:
+		n, err := d.mapLen(b)
+		if err != nil {
+			return err
+		}
+		return d.decodeMap(v, n)
+	case b == 0xc4, b == 0xc5, b == 0xc6, b >= 0xa0 && b <= 0xbf, b == 0xd9, b == 0xda, b == 0xdb:
+		d.pos--
+		x, err := d.decodeAny()
+		if err != nil {
+			return err
+		}
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			switch x := x.(type) {
+			case []byte:
+				v.SetBytes(append([]byte(nil), x...))
+				return nil
+			case string:
+				v.SetBytes([]byte(x))
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("msgpack: cannot decode %#x into %s", b, v.Type())
+}
+
+func (d *msgpackDecoder) decodeArray(v reflect.Value, n int) error {
+	switch v.Kind() {
+	case reflect.Slice:
+		s := reflect.MakeSlice(v.Type(), n, n)
+		for i := 0; i < n; i++ {
+			if err := d.decode(s.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	case reflect.Array:
+		if n != v.Len() {
+			return fmt.Errorf("msgpack: cannot decode %d elements into %s", n, v.Type())
+		}
+		for i := 0; i < n; i++ {
+			if err := d.decode(v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("msgpack: cannot decode an array into %s", v.Type())
+}
+
+func (d *msgpackDecoder) decodeMap(v reflect.Value, n int) error {
+	switch v.Kind() {
+	case reflect.Map:
+		m := reflect.MakeMapWithSize(v.Type(), n)
+		for i := 0; i < n; i++ {
+			key := reflect.New(v.Type().Key()).Elem()
+			if err := d.decode(key); err != nil {
+				return err
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := d.decode(elem); err != nil {
+				return err
+			}
+			m.SetMapIndex(key, elem)
+		}
+		v.Set(m)
+		return nil
+	case reflect.Struct:
+		fields := msgpackFields(v.Type())
+		for i := 0; i < n; i++ {
+			var name string
+			if err := d.decode(reflect.ValueOf(&name).Elem()); err != nil {
+				return err
+			}
+			found := false
+			for _, f := range fields {
+				if f.name == name {
+					if err := d.decode(v.Field(f.index)); err != nil {
+						return err
+					}
+					found = true
+					break
+				}
+			}
+			if !found {
+				// Skip fields the struct does not have.
+				if _, err := d.decodeAny(); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("msgpack: cannot decode a map into %s", v.Type())
+}
+
+func (d *msgpackDecoder) arrayLen(b byte) (int, error) {
+	switch b {
+	case 0xdc:
+		return d.readLen(2)
+	case 0xdd:
+		return d.readLen(4)
+	}
+	return int(b & 0x0f), nil
+}
+
+func (d *msgpackDecoder) mapLen(b byte) (int, error) {
+	switch b {
+	case 0xde:
+		return d.readLen(2)
+	case 0xdf:
+		return d.readLen(4)
+	}
+	return int(b & 0x0f), nil
+}
+
+// decodeAny reads the next value as generic data: nil, bool, int64, uint64
+// (only above math.MaxInt64), float64, string, []byte, time.Time,
+// []interface{} or map[string]interface{}.
+func (d *msgpackDecoder) decodeAny() (interface{}, error) {
+	b, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case b <= 0x7f:
+		return int64(b), nil
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	case b >= 0xa0 && b <= 0xbf:
+		return d.readString(int(b & 0x1f))
+	case b >= 0x90 && b <= 0x9f, b == 0xdc, b == 0xdd:
+		n, err := d.arrayLen(b)
+		if err != nil {
+			return nil, err
+		}
+		a := make([]interface{}, n)
+		for i := range a {
+			if a[i], err = d.decodeAny(); err != nil {
+				return nil, err
+			}
+		}
+		return a, nil
+	case b >= 0x80 && b <= 0x8f, b == 0xde, b == 0xdf:
+		n, err := d.mapLen(b)
+		if err != nil {
+			return nil, err
+		}
+		m := make(map[string]interface{}, n)
+		for i := 0; i < n; i++ {
+			k, err := d.decodeAny()
+			if err != nil {
+				return nil, err
+			}
+			if m[fmt.Sprint(k)], err = d.decodeAny(); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	}
+
+	switch b {
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		p, err := d.read(1 << (b - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		u := readUintN(p)
+		if u > math.MaxInt64 {
+			return u, nil
+		}
+		return int64(u), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		p, err := d.read(1 << (b - 0xd0))
+		if err != nil {
+			return nil, err
+		}
+		u := readUintN(p)
+		shift := 64 - 8*uint(len(p))
+		return int64(u<<shift) >> shift, nil
+	case 0xca:
+		p, err := d.read(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(p))), nil
+	case 0xcb:
+		p, err := d.read(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(p)), nil
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.readLen(1 << (b - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.readString(n)
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.readLen(1 << (b - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		p, err := d.read(n)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), p...), nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.readExt(1 << (b - 0xd4))
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.readLen(1 << (b - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return d.readExt(n)
+	}
+	return nil, fmt.Errorf("msgpack: invalid type byte %#x", b)
+}
+
+func (d *msgpackDecoder) readString(n int) (string, error) {
+	p, err := d.read(n)
+	if err != nil {
+		return "", err
+	}
+	return string(p), nil
+}
+
+// readExt reads the type and n data bytes of an extension value. Only
+// timestamps are understood.
+func (d *msgpackDecoder) readExt(n int) (interface{}, error) {
+	typ, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+	p, err := d.read(n)
+	if err != nil {
+		return nil, err
+	}
+	if typ != msgpackTimestamp {
+		return nil, fmt.Errorf("msgpack: unsupported extension type %d", int8(typ))
+	}
+	var sec, nsec int64
+	switch n {
+	case 4:
+		sec = int64(binary.BigEndian.Uint32(p))
+	case 8:
+		v := binary.BigEndian.Uint64(p)
+		nsec, sec = int64(v>>34), int64(v&(1<<34-1))
+	case 12:
+		nsec, sec = int64(binary.BigEndian.Uint32(p)), int64(binary.BigEndian.Uint64(p[4:]))
+	default:
+		return nil, fmt.Errorf("msgpack: invalid timestamp length %d", n)
+	}
+	if sec == (time.Time{}).Unix() && nsec == 0 {
+		return time.Time{}, nil
+	}
+	return time.Unix(sec, nsec).UTC(), nil
+}
+
+func readUintN(p []byte) uint64 {
+	var u uint64
+	for _, b := range p {
+		u = u<<8 | uint64(b)
+	}
+	return u
+}
+
+// setScalar stores the generic value x in v, converting between numeric
+// types when the value fits.
+func setScalar(v reflect.Value, x interface{}) error {
+	switch x := x.(type) {
+	case bool:
+		if v.Kind() == reflect.Bool {
+			v.SetBool(x)
+			return nil
+		}
+	case string:
+		if v.Kind() == reflect.String {
+			v.SetString(x)
+			return nil
+		}
+	case time.Time:
+		if v.Type() == timeType {
+			v.Set(reflect.ValueOf(x))
+			return nil
+		}
+	case int64:
+		switch {
+		case v.Kind() >= reflect.Int && v.Kind() <= reflect.Int64 && !v.OverflowInt(x):
+			v.SetInt(x)
+			return nil
+		case v.Kind() >= reflect.Uint && v.Kind() <= reflect.Uintptr && x >= 0 && !v.OverflowUint(uint64(x)):
+			v.SetUint(uint64(x))
+			return nil
+		case v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64:
+			v.SetFloat(float64(x))
+			return nil
+		}
+	case uint64:
+		if v.Kind() >= reflect.Uint && v.Kind() <= reflect.Uintptr && !v.OverflowUint(x) {
+			v.SetUint(x)
+			return nil
+		}
+	case float64:
+		if v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64 {
+			v.SetFloat(x)
+			return nil
+		}
+	}
+	return fmt.Errorf("msgpack: cannot decode %T into %s", x, v.Type())
+}
@@ -0,0 +1,149 @@
+package erlcgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func msgpackSnapshot() ERLCServerResponse {
+	return ERLCServerResponse{
+		Name:           "Server",
+		OwnerId:        1,
+		CurrentPlayers: 2,
+		MaxPlayers:     40,
+		Players:        []ERLCServerPlayer{{Player: "A:1", Permission: "Server Owner", Team: "Police", Location: ERLCLocation{LocationX: -12.5, PostalCode: "204"}}},
+		Staff:          &ERLCStaff{Admins: map[string]string{"1": "A"}, Mods: map[string]string{}},
+		Queue:          []int64{math.MaxInt64, -1, 0},
+		Vehicles:       []ERLCVehicle{{Name: "Falcon", Owner: "A"}, {Name: "Bullhorn"}},
+		KillLogs:       []ERLCKillLog{{Killer: "A:1", Killed: "B:2", Timestamp: 1704614400}},
+	}
+}
+
+func TestMsgpackRoundTrip(t *testing.T) {
+	want := msgpackSnapshot()
+	data, err := MsgpackSerializer{}.Marshal(&want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got ERLCServerResponse
+	if err := (MsgpackSerializer{}).Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip:\n got %+v\nwant %+v", got, want)
+	}
+
+	js, _ := json.Marshal(want)
+	if len(data) >= len(js) {
+		t.Errorf("msgpack is %d bytes, JSON %d; want it smaller", len(data), len(js))
+	}
+}
+
+func TestMsgpackTime(t *testing.T) {
+	type timed struct {
+		At     time.Time  `json:"at"`
+		Before *time.Time `json:"before"`
+		Unset  time.Time
+	}
+	before := time.Date(1969, 7, 20, 20, 17, 40, 0, time.UTC)
+	want := timed{At: time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC), Before: &before}
+	data, err := MsgpackSerializer{}.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got timed
+	if err := (MsgpackSerializer{}).Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.At.Equal(want.At) || !got.Before.Equal(before) || !got.Unset.Equal(want.Unset) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestMsgpackInterop(t *testing.T) {
+	// {"compact":true,"schema":0}, the example from msgpack.org.
+	spec := []byte{0x82, 0xa7, 'c', 'o', 'm', 'p', 'a', 'c', 't', 0xc3, 0xa6, 's', 'c', 'h', 'e', 'm', 'a', 0x00}
+	var v struct {
+		Compact bool `json:"compact"`
+		Schema  int  `json:"schema"`
+	}
+	if err := (MsgpackSerializer{}).Unmarshal(spec, &v); err != nil {
+		t.Fatal(err)
+	}
+	if !v.Compact || v.Schema != 0 {
+		t.Errorf("decoded %+v", v)
+	}
+	data, err := MsgpackSerializer{}.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, spec) {
+		t.Errorf("encoded % x, want % x", data, spec)
+	}
+
+	var generic interface{}
+	if err := (MsgpackSerializer{}).Unmarshal(spec, &generic); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]interface{}{"compact": true, "schema": int64(0)}; !reflect.DeepEqual(generic, want) {
+		t.Errorf("generic decode %v, want %v", generic, want)
+	}
+}
+
+func TestMsgpackCache(t *testing.T) {
+	var hits int32
+	want := msgpackSnapshot()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(want)
+	}))
+	defer srv.Close()
+
+	mem := NewMemoryCache()
+	c := NewClient("key", WithBaseURL(srv.URL), WithCache(&CacheConfig{Enabled: true, TTL: time.Minute, Cache: mem, Serializer: MsgpackSerializer{}}))
+	defer c.Close()
+
+	for i := 0; i < 2; i++ {
+		got, err := c.GetServer(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Name != want.Name || len(got.Vehicles) != 2 || got.Vehicles[0].Owner != "A" {
+			t.Errorf("call %d: got %+v", i, got)
+		}
+	}
+	if n := atomic.LoadInt32(&hits); n != 1 {
+		t.Errorf("server got %d requests, want 1 with the second served from the cache", n)
+	}
+}
+
+func FuzzMsgpackUnmarshal(f *testing.F) {
+	snap := msgpackSnapshot()
+	data, _ := MsgpackSerializer{}.Marshal(&snap)
+	f.Add(data)
+	f.Add([]byte{0xdd, 0xff, 0xff, 0xff, 0xff})
+	f.Add([]byte{0xc7, 12, 0xff, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var resp ERLCServerResponse
+		if err := (MsgpackSerializer{}).Unmarshal(data, &resp); err != nil {
+			return
+		}
+		again, err := MsgpackSerializer{}.Marshal(&resp)
+		if err != nil {
+			t.Fatalf("re-encoding a decoded value: %v", err)
+		}
+		var round ERLCServerResponse
+		if err := (MsgpackSerializer{}).Unmarshal(again, &round); err != nil {
+			t.Fatalf("decoding a re-encoded value: %v", err)
+		}
+	})
+}
@@ -0,0 +1,259 @@
+package erlcgo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCommandDeferred is returned by ExecuteCommand under a deferring
+// OfflineCommandPolicy when the server was empty and the command was kept to
+// be sent once a player joins.
+var ErrCommandDeferred = errors.New("erlc: command deferred until a player joins")
+
+// ErrCommandExpired is passed to OfflineCommandPolicy.OnFlushed for a
+// deferred command that was not sent within MaxWait.
+var ErrCommandExpired = errors.New("erlc: deferred command expired")
+
+// OfflineCommandPolicy controls what ExecuteCommand does when a command
+// fails with code 3002 because the server has no players.
+type OfflineCommandPolicy struct {
+	// PollInterval is how often the player count is checked while commands
+	// are waiting. Defaults to 15 seconds.
+	PollInterval time.Duration
+
+	// MaxWait is how long a command may wait for a player. Zero means until
+	// the client is closed, or, for waiting calls, the caller's context ends.
+	MaxWait time.Duration
+
+	// Defer makes ExecuteCommand return ErrCommandDeferred straight away and
+	// send the command in the background. Otherwise ExecuteCommand blocks
+	// until the command is sent, expires or ctx is done.
+	Defer bool
+
+	// OnFlushed is called with the outcome of each deferred command.
+	OnFlushed func(command string, err error)
+}
+
+// WithOfflineCommandPolicy makes ExecuteCommand hold commands rejected
+// because the server is empty and send them, in order, once a player joins.
+//
+// Example:
+//
+//	client := NewClient("your-server-key",
+//	    WithOfflineCommandPolicy(OfflineCommandPolicy{
+//	        Defer:   true,
+//	        MaxWait: time.Hour,
+//	        OnFlushed: func(cmd string, err error) {
+//	            log.Printf("deferred %q: %v", cmd, err)
+//	        },
+//	    }),
+//	)
+func WithOfflineCommandPolicy(policy OfflineCommandPolicy) ClientOption {
+	return func(c *Client) {
+		if policy.PollInterval <= 0 {
+			policy.PollInterval = 15 * time.Second
+		}
+		c.offline = &offlineCommands{client: c, policy: policy}
+	}
+}
+
+func isServerOffline(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == ErrorCodeServerOffline
+}
+
+type heldCommand struct {
+	// ctx keeps the caller's values, such as tenant and priority, without
+	// its cancellation, so deferred commands outlive the call.
+	ctx      context.Context
+	command  string
+	deadline time.Time
+	done     chan error // nil for deferred commands
+
+	// sending is set while flush is sending the command, and abandoned when
+	// the caller's context ends meanwhile. Both are guarded by
+	// offlineCommands.mu.
+	sending   bool
+	abandoned error
+}
+
+// offlineCommands holds commands until the server has players and flushes
+// them from a single background goroutine.
+type offlineCommands struct {
+	client *Client
+	policy OfflineCommandPolicy
+
+	mu      sync.Mutex
+	held    []*heldCommand
+	running bool
+}
+
+// hold queues a command rejected with 3002 and, unless the policy defers,
+// waits for its outcome.
+func (o *offlineCommands) hold(ctx context.Context, command string) error {
+	h := &heldCommand{ctx: context.WithoutCancel(ctx), command: command}
+	if o.policy.MaxWait > 0 {
+		h.deadline = time.Now().Add(o.policy.MaxWait)
+	}
+	if !o.policy.Defer {
+		h.done = make(chan error, 1)
+	}
+
+	o.mu.Lock()
+	o.held = append(o.held, h)
+	if !o.running {
+		o.running = true
+		spawn(o.client, "offline command runner", o.run)
+	}
+	o.mu.Unlock()
+
+	if h.done == nil {
+		return ErrCommandDeferred
+	}
+	select {
+	case err := <-h.done:
+		return err
+	case <-ctx.Done():
+		if o.abandon(h, ctx.Err()) {
+			return ctx.Err()
+		}
+		// The command is already being sent, so report what happened to it
+		// rather than claim it was not run.
+		return <-h.done
+	}
+}
+
+// abandon removes h for a caller whose context ended and reports whether it
+// was removed. A command flush is sending is left to finish, and is finished
+// with err if the server turns out to be empty again.
+func (o *offlineCommands) abandon(h *heldCommand, err error) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if h.sending {
+		h.abandoned = err
+		return false
+	}
+	for i, held := range o.held {
+		if held == h {
+			o.held = append(o.held[:i], o.held[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+func (o *offlineCommands) drop(h *heldCommand) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i, held := range o.held {
+		if held == h {
+			o.held = append(o.held[:i], o.held[i+1:]...)
+			return
+		}
+	}
+}
+
+func (o *offlineCommands) run() {
+	ticker := time.NewTicker(o.policy.PollInterval)
+	defer ticker.Stop()
+
+	ctx := o.client.lifetime
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx = pollContext(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			o.finishAll(ErrClientClosed)
+			return
+		case <-ticker.C:
+		}
+
+		o.expire(time.Now())
+		resp, err := o.client.GetServer(ctx, ServerQueryOptions{})
+		if err == nil && resp.CurrentPlayers > 0 {
+			o.flush()
+		}
+
+		o.mu.Lock()
+		if len(o.held) == 0 {
+			o.running = false
+			o.mu.Unlock()
+			return
+		}
+		o.mu.Unlock()
+	}
+}
+
+// flush sends held commands in order, stopping if the server empties again.
+func (o *offlineCommands) flush() {
+	for {
+		o.mu.Lock()
+		if len(o.held) == 0 {
+			o.mu.Unlock()
+			return
+		}
+		h := o.held[0]
+		h.sending = true
+		o.mu.Unlock()
+
+		err := o.client.executeCommand(h.ctx, h.command, nil)
+		if isServerOffline(err) {
+			o.mu.Lock()
+			h.sending = false
+			abandoned := h.abandoned
+			o.mu.Unlock()
+			if abandoned != nil {
+				o.drop(h)
+				o.finish(h, abandoned)
+			}
+			return
+		}
+		o.drop(h)
+		o.finish(h, err)
+	}
+}
+
+func (o *offlineCommands) expire(now time.Time) {
+	o.mu.Lock()
+	var expired []*heldCommand
+	kept := o.held[:0]
+	for _, h := range o.held {
+		if !h.deadline.IsZero() && now.After(h.deadline) {
+			expired = append(expired, h)
+		} else {
+			kept = append(kept, h)
+		}
+	}
+	o.held = kept
+	o.mu.Unlock()
+
+	for _, h := range expired {
+		o.finish(h, ErrCommandExpired)
+	}
+}
+
+func (o *offlineCommands) finishAll(err error) {
+	o.mu.Lock()
+	held := o.held
+	o.held = nil
+	o.running = false
+	o.mu.Unlock()
+
+	for _, h := range held {
+		o.finish(h, err)
+	}
+}
+
+func (o *offlineCommands) finish(h *heldCommand, err error) {
+	if h.done != nil {
+		h.done <- err
+		return
+	}
+	if o.policy.OnFlushed != nil {
+		o.policy.OnFlushed(h.command, err)
+	}
+}
@@ -0,0 +1,94 @@
+package erlcgo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestOfflineCommandCanceledWhileSending cancels a waiting caller while its
+// held command is being flushed. The caller must learn what happened to the
+// command instead of being told it was not run.
+func TestOfflineCommandCanceledWhileSending(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		// flushed is the upstream answer to the flushed command.
+		flushed int
+		want    error
+	}{
+		{name: "sent", flushed: http.StatusOK},
+		{name: "offline again", flushed: http.StatusUnprocessableEntity, want: context.Canceled},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var commands int32
+			sending := make(chan struct{})
+			release := make(chan struct{})
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if r.URL.Path != "/v2/server/command" {
+					w.Write([]byte(`{"CurrentPlayers":1}`))
+					return
+				}
+				if atomic.AddInt32(&commands, 1) == 1 {
+					w.WriteHeader(http.StatusUnprocessableEntity)
+					w.Write([]byte(`{"code":3002,"message":"Server offline"}`))
+					return
+				}
+				close(sending)
+				<-release
+				if tc.flushed != http.StatusOK {
+					w.WriteHeader(tc.flushed)
+					w.Write([]byte(`{"code":3002,"message":"Server offline"}`))
+					return
+				}
+				w.Write([]byte(`{"message":"Success"}`))
+			}))
+			defer srv.Close()
+
+			c := NewClient("key", WithBaseURL(srv.URL),
+				WithOfflineCommandPolicy(OfflineCommandPolicy{PollInterval: 10 * time.Millisecond}))
+			defer c.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			result := make(chan error, 1)
+			go func() { result <- c.ExecuteCommand(ctx, ":h hello") }()
+
+			<-sending
+			cancel()
+			// Let the caller see the cancellation before the send completes.
+			for !abandoned(c.offline) {
+				time.Sleep(time.Millisecond)
+			}
+			close(release)
+
+			select {
+			case err := <-result:
+				if !errors.Is(err, tc.want) {
+					t.Errorf("got %v, want %v", err, tc.want)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("caller never returned")
+			}
+			if n := atomic.LoadInt32(&commands); n != 2 {
+				t.Errorf("server got %d commands, want 2", n)
+			}
+			c.offline.mu.Lock()
+			held := len(c.offline.held)
+			c.offline.mu.Unlock()
+			if held != 0 {
+				t.Errorf("%d commands still held, want none", held)
+			}
+		})
+	}
+}
+
+func abandoned(o *offlineCommands) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.held) > 0 && o.held[0].abandoned != nil
+}
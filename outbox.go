@@ -0,0 +1,252 @@
+package erlcgo
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Sink is a destination for subscription events, such as a Discord channel,
+// a message queue or a webhook. WebhookRelay is a Sink.
+type Sink interface {
+	Send(ctx context.Context, event Event) error
+}
+
+// OutboxStore persists events that have not yet been delivered.
+// Implementations must be safe for concurrent use.
+type OutboxStore interface {
+	// Append stores an event for delivery.
+	Append(ctx context.Context, event Event) error
+
+	// Pending returns stored events in the order they were appended.
+	Pending(ctx context.Context) ([]Event, error)
+
+	// Remove deletes the oldest stored event with the ID, which is the one
+	// just delivered. Event IDs can repeat, since log event IDs are derived
+	// from their content, so later events with the same ID must be kept.
+	Remove(ctx context.Context, id string) error
+}
+
+// OutboxConfig configures an Outbox.
+type OutboxConfig struct {
+	// MinBackoff is the delay after the first failed delivery. Defaults to one second.
+	MinBackoff time.Duration
+
+	// MaxBackoff caps the delay between attempts. Defaults to five minutes.
+	MaxBackoff time.Duration
+
+	// ErrorHandler is called with delivery and store errors.
+	ErrorHandler func(error)
+}
+
+// Outbox wraps a Sink so that events are persisted before delivery and
+// retried with backoff until the sink accepts them. Events survive both sink
+// outages and process restarts; delivery is at least once, in order.
+//
+// Events reloaded from the store after a restart carry their Data as decoded
+// JSON (maps and slices) rather than the original typed slices.
+//
+// Example:
+//
+//	hook := &erlcgo.WebhookRelay{URL: url, Secrets: []string{secret}}
+//	outbox := erlcgo.NewOutbox(hook, erlcgo.NewFileOutboxStore("outbox.json"), erlcgo.OutboxConfig{})
+//	go outbox.Run(ctx)
+//
+//	for event := range sub.Events {
+//	    outbox.Send(ctx, event)
+//	}
+type Outbox struct {
+	sink   Sink
+	store  OutboxStore
+	config OutboxConfig
+	wake   chan struct{}
+}
+
+// NewOutbox creates an outbox delivering to sink. Call Run to start delivery.
+func NewOutbox(sink Sink, store OutboxStore, config OutboxConfig) *Outbox {
+	if config.MinBackoff <= 0 {
+		config.MinBackoff = time.Second
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = 5 * time.Minute
+	}
+	return &Outbox{
+		sink:   sink,
+		store:  store,
+		config: config,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// Send persists event for delivery. It returns once the event is stored, not
+// once it is delivered, so an Outbox is itself a Sink.
+func (o *Outbox) Send(ctx context.Context, event Event) error {
+	if event.ID == "" {
+		event.ID = newRandomID()
+	}
+	if event.Version == 0 {
+		event.Version = SchemaVersion
+	}
+	if err := o.store.Append(ctx, event); err != nil {
+		return err
+	}
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Run delivers stored events until ctx is done.
+func (o *Outbox) Run(ctx context.Context) error {
+	backoff := time.Duration(0)
+	for {
+		delivered, err := o.flush(ctx)
+		if err != nil {
+			o.report(err)
+			if backoff == 0 {
+				backoff = o.config.MinBackoff
+			} else if backoff *= 2; backoff > o.config.MaxBackoff {
+				backoff = o.config.MaxBackoff
+			}
+		} else if delivered {
+			backoff = 0
+		}
+
+		if backoff > 0 {
+			// New events wait behind the failing one, so only the timer matters.
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-o.wake:
+		}
+	}
+}
+
+// flush delivers pending events in order, stopping at the first failure.
+func (o *Outbox) flush(ctx context.Context) (bool, error) {
+	pending, err := o.store.Pending(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, event := range pending {
+		if err := o.sink.Send(ctx, event); err != nil {
+			return false, err
+		}
+		if err := o.store.Remove(ctx, event.ID); err != nil {
+			return false, err
+		}
+	}
+	return len(pending) > 0, nil
+}
+
+func (o *Outbox) report(err error) {
+	if o.config.ErrorHandler != nil {
+		o.config.ErrorHandler(err)
+	}
+}
+
+// FileOutboxStore is an OutboxStore backed by a single JSON file, rewritten
+// atomically on every change. It suits the modest volumes of a single server.
+type FileOutboxStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileOutboxStore returns a store that keeps pending events in the file at path.
+func NewFileOutboxStore(path string) *FileOutboxStore {
+	return &FileOutboxStore{path: path}
+}
+
+func (s *FileOutboxStore) Append(ctx context.Context, event Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events, err := s.read()
+	if err != nil {
+		return err
+	}
+	return s.write(append(events, event))
+}
+
+func (s *FileOutboxStore) Pending(ctx context.Context) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read()
+}
+
+func (s *FileOutboxStore) Remove(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events, err := s.read()
+	if err != nil {
+		return err
+	}
+	for i, e := range events {
+		if e.ID == id {
+			return s.write(append(events[:i], events[i+1:]...))
+		}
+	}
+	return nil
+}
+
+func (s *FileOutboxStore) read() ([]Event, error) {
+	var events []Event
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) || (err == nil && len(data) == 0) {
+		return events, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, err
+	}
+	for i, e := range events {
+		if events[i], err = MigrateEvent(e); err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+func (s *FileOutboxStore) write(events []Event) error {
+	data, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	// Flush to disk before the rename, so a crash cannot leave an empty file
+	// in place of the pending events.
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
@@ -0,0 +1,105 @@
+package erlcgo
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakySink records delivered events by their Data and refuses every event
+// after accepting limit of them. A negative limit accepts everything.
+type flakySink struct {
+	mu        sync.Mutex
+	limit     int
+	delivered []interface{}
+}
+
+func (s *flakySink) Send(ctx context.Context, event Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.limit >= 0 && len(s.delivered) >= s.limit {
+		return errors.New("sink unavailable")
+	}
+	s.delivered = append(s.delivered, event.Data)
+	return nil
+}
+
+func (s *flakySink) got() []interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]interface{}(nil), s.delivered...)
+}
+
+// waitDelivered waits until sink has delivered n events.
+func waitDelivered(t *testing.T, sink *flakySink, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for len(sink.got()) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("delivered %v, want %d events", sink.got(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestOutboxOrderAcrossRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	config := OutboxConfig{MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+	events := []Event{
+		{ID: "1", Type: EventTypeKills, Data: "first"},
+		{ID: "2", Type: EventTypeKills, Data: "second"},
+		// Log event IDs come from their content, so they can repeat.
+		{ID: "dup", Type: EventTypeKills, Data: "third"},
+		{ID: "dup", Type: EventTypeKills, Data: "fourth"},
+		{ID: "5", Type: EventTypeKills, Data: "fifth"},
+	}
+
+	// The sink takes two events and then goes down until the process stops.
+	before := &flakySink{limit: 2}
+	outbox := NewOutbox(before, NewFileOutboxStore(path), config)
+	ctx, stop := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		outbox.Run(ctx)
+		close(done)
+	}()
+	for _, e := range events {
+		if err := outbox.Send(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitDelivered(t, before, 2)
+	time.Sleep(20 * time.Millisecond) // let it retry against the failing sink
+	stop()
+	<-done
+
+	// After a restart the remaining events are delivered from the file, in
+	// order and once each.
+	after := &flakySink{limit: -1}
+	store := NewFileOutboxStore(path)
+	outbox = NewOutbox(after, store, config)
+	ctx, stop = context.WithCancel(context.Background())
+	defer stop()
+	go outbox.Run(ctx)
+	waitDelivered(t, after, 3)
+
+	if got, want := before.got(), []interface{}{"first", "second"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("delivered before the restart %v, want %v", got, want)
+	}
+	if got, want := after.got(), []interface{}{"third", "fourth", "fifth"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("delivered after the restart %v, want %v", got, want)
+	}
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		pending, err := store.Pending(context.Background())
+		if err == nil && len(pending) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("pending %v, %v; want none", pending, err)
+		}
+	}
+}
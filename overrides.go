@@ -0,0 +1,102 @@
+package erlcgo
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrCommandsDisabled is returned by ExecuteCommand when the client's
+// CommandPolicy disables commands.
+var ErrCommandsDisabled = errors.New("erlc: commands are disabled for this server")
+
+// CommandPolicy restricts which commands a client may send.
+type CommandPolicy struct {
+	// Disabled refuses every command, for example on read-only dashboards.
+	Disabled bool
+
+	// Validate runs ValidateCommand before sending, as WithCommandValidation
+	// does. Leaving it false does not turn off validation enabled elsewhere.
+	Validate bool
+
+	// BlockedVerbs lists command verbs, without the colon, that are refused,
+	// such as "shutdown" or "pban".
+	BlockedVerbs []string
+}
+
+// WithCommandPolicy applies a CommandPolicy to ExecuteCommand.
+func WithCommandPolicy(p CommandPolicy) ClientOption {
+	return func(c *Client) {
+		c.commandPolicy = &p
+		// Only ever turn validation on, so a policy that leaves Validate
+		// unset keeps a shared WithCommandValidation(true).
+		if p.Validate {
+			c.validateCommands = true
+		}
+	}
+}
+
+// checkPolicy returns an error if the client's CommandPolicy refuses command.
+func (c *Client) checkPolicy(command string) error {
+	p := c.commandPolicy
+	if p == nil {
+		return nil
+	}
+	if p.Disabled {
+		return ErrCommandsDisabled
+	}
+	return blockedVerb(command, p.BlockedVerbs)
+}
+
+// ServerOverrides adjusts one server's client on top of the defaults shared
+// by a ClientManager. Zero fields keep the shared setting.
+type ServerOverrides struct {
+	// PollInterval sets the default subscription poll interval, used by
+	// Subscribe and by SubscribeWithConfig with a nil config. An explicit
+	// EventConfig keeps its own interval.
+	PollInterval time.Duration
+
+	// CacheTTL sets the response cache TTL. It only has an effect when the
+	// shared defaults enable caching.
+	CacheTTL time.Duration
+
+	// Commands replaces the command policy.
+	Commands *CommandPolicy
+
+	// Options are applied last, for anything not covered above.
+	Options []ClientOption
+}
+
+// Option returns a ClientOption applying the overrides.
+//
+// Example:
+//
+//	m.Add("flagship", flagshipKey, erlcgo.ServerOverrides{
+//	    PollInterval: 500 * time.Millisecond,
+//	    Commands:     &erlcgo.CommandPolicy{Validate: true, BlockedVerbs: []string{"shutdown"}},
+//	}.Option())
+//	m.Add("test", testKey, erlcgo.ServerOverrides{PollInterval: 10 * time.Second}.Option())
+func (o ServerOverrides) Option() ClientOption {
+	return func(c *Client) {
+		if o.PollInterval > 0 {
+			events := DefaultEventConfig()
+			if c.eventDefaults != nil {
+				copied := *c.eventDefaults
+				events = &copied
+			}
+			events.PollInterval = o.PollInterval
+			c.eventDefaults = events
+		}
+		if o.CacheTTL > 0 && c.cache != nil {
+			// The cache config may be shared with other servers' clients.
+			cache := *c.cache
+			cache.TTL = o.CacheTTL
+			c.cache = &cache
+		}
+		if o.Commands != nil {
+			WithCommandPolicy(*o.Commands)(c)
+		}
+		for _, opt := range o.Options {
+			opt(c)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package erlcgo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCommandPolicyKeepsSharedValidation(t *testing.T) {
+	c := NewClient("key", WithCommandValidation(true), ServerOverrides{
+		Commands: &CommandPolicy{BlockedVerbs: []string{"shutdown"}},
+	}.Option())
+	defer c.Close()
+	if !c.validateCommands {
+		t.Error("a policy without Validate turned off shared command validation")
+	}
+
+	c = NewClient("key", WithCommandPolicy(CommandPolicy{Validate: true}))
+	defer c.Close()
+	if !c.validateCommands {
+		t.Error("a policy with Validate did not enable command validation")
+	}
+}
+
+func TestPollIntervalOverrideAppliesToNilConfig(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"Name":"Test"}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient("key", WithBaseURL(srv.URL), ServerOverrides{PollInterval: 7 * time.Second}.Option())
+	defer c.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for name, subscribe := range map[string]func() (*Subscription, error){
+		"Subscribe":           func() (*Subscription, error) { return c.Subscribe(ctx, EventTypePlayers) },
+		"SubscribeWithConfig": func() (*Subscription, error) { return c.SubscribeWithConfig(ctx, nil, EventTypePlayers) },
+	} {
+		sub, err := subscribe()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if sub.config.PollInterval != 7*time.Second {
+			t.Errorf("%s: poll interval %v, want the 7s override", name, sub.config.PollInterval)
+		}
+		sub.Close()
+	}
+
+	config := DefaultEventConfig()
+	sub, err := c.SubscribeWithConfig(ctx, config, EventTypePlayers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	if sub.config.PollInterval != DefaultEventConfig().PollInterval {
+		t.Errorf("explicit config: poll interval %v, want its own %v", sub.config.PollInterval, DefaultEventConfig().PollInterval)
+	}
+}
@@ -0,0 +1,126 @@
+package erlcgo
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func FuzzParseRateLimitHeaders(f *testing.F) {
+	f.Add("global", "40", "39", "1700000000")
+	f.Add("command", "1", "0", "1700000000000")
+	f.Add("", "-1", "NaN", "NaN")
+	f.Add("x", "99999999999999999999", "Inf", "1e308")
+	f.Add("x", " 5 ", "10", "-Inf")
+	f.Add("x", "", "", "1.7e9")
+	f.Add("x", "0x10", "1e3", "253402300800")
+	f.Add("x", "5", "5", "Mon, 02 Jan 2006 15:04:05 GMT")
+	f.Fuzz(func(t *testing.T, bucket, limit, remaining, reset string) {
+		h := http.Header{}
+		h.Set("X-RateLimit-Bucket", bucket)
+		h.Set("X-RateLimit-Limit", limit)
+		h.Set("X-RateLimit-Remaining", remaining)
+		h.Set("X-RateLimit-Reset", reset)
+
+		rl := parseRateLimitHeaders(h)
+		if rl == nil {
+			if bucket != "" || limit != "" || remaining != "" || reset != "" {
+				t.Fatalf("nil info for headers %q %q %q %q", bucket, limit, remaining, reset)
+			}
+			return
+		}
+		if rl.Limit < 0 || rl.Remaining < 0 {
+			t.Fatalf("negative budget: limit %d, remaining %d", rl.Limit, rl.Remaining)
+		}
+		if rl.Limit > 0 && rl.Remaining > rl.Limit {
+			t.Fatalf("remaining %d exceeds limit %d", rl.Remaining, rl.Limit)
+		}
+		if !rl.ResetAt.IsZero() {
+			if !rl.ResetAt.After(time.Unix(0, 0)) {
+				t.Fatalf("reset %v is not after the epoch", rl.ResetAt)
+			}
+			if rl.ResetAt.After(time.Now().Add(maxResetAhead)) {
+				t.Fatalf("reset %v is more than %v ahead", rl.ResetAt, maxResetAhead)
+			}
+		}
+	})
+}
+
+func FuzzParseRetryAfter(f *testing.F) {
+	f.Add([]byte(`{"retry_after": 1.5, "bucket": "global", "global": true}`))
+	f.Add([]byte(`{"retry_after": "2"}`))
+	f.Add([]byte(`{"retry_after": "NaN"}`))
+	f.Add([]byte(`{"retry_after": "Inf"}`))
+	f.Add([]byte(`{"retry_after": 1e308}`))
+	f.Add([]byte(`{"retry_after": -5}`))
+	f.Add([]byte(`{"retry_after": 99999999999999999999999}`))
+	f.Add([]byte(`{"retry_after": null}`))
+	f.Add([]byte(`not json`))
+	f.Fuzz(func(t *testing.T, body []byte) {
+		checkRetryAfter(t, parseRetryAfter(body), true)
+		parseRateLimitBucket(body)
+		parseRateLimitGlobal(body)
+	})
+}
+
+func FuzzParseRetryAfterHeader(f *testing.F) {
+	f.Add("5")
+	f.Add("0.25")
+	f.Add("0")
+	f.Add("-1")
+	f.Add("NaN")
+	f.Add("+Inf")
+	f.Add("1e308")
+	f.Add("99999999999999999999999")
+	f.Add("Mon, 02 Jan 2006 15:04:05 GMT")
+	f.Add("Fri, 31 Dec 9999 23:59:59 GMT")
+	f.Add("Mon, 32 Foo 2006 25:61:61 GMT")
+	f.Add("Monday, 02-Jan-06 15:04:05 MST")
+	f.Fuzz(func(t *testing.T, value string) {
+		h := http.Header{}
+		h.Set("Retry-After", value)
+		checkRetryAfter(t, parseRetryAfterHeader(h), false)
+	})
+}
+
+// checkRetryAfter fails t if d is outside [0, maxRetryAfter], or zero when
+// positive is set.
+func checkRetryAfter(t *testing.T, d *time.Duration, positive bool) {
+	t.Helper()
+	if d == nil {
+		return
+	}
+	if *d < 0 || *d > maxRetryAfter {
+		t.Fatalf("retry after %v is outside [0, %v]", *d, maxRetryAfter)
+	}
+	if positive && *d == 0 {
+		t.Fatal("zero retry after from a body hint")
+	}
+}
+
+func FuzzParseErrorBody(f *testing.F) {
+	f.Add([]byte(`{"code": 4001, "message": "Rate limited"}`), "application/json")
+	f.Add([]byte(`{"code": "3002", "message": "Invalid server key", "commandId": "abc"}`), "application/json")
+	f.Add([]byte(`{"code": 1e400}`), "application/json")
+	f.Add([]byte(`{"code": 99999999999999999999, "message": 5}`), "")
+	f.Add([]byte(`{"code": "NaN"}`), "application/json")
+	f.Add([]byte(`<!DOCTYPE html><html><head><title>502 Bad Gateway</title></head></html>`), "text/html; charset=utf-8")
+	f.Add([]byte("\x00\x01\x02\xff"), "application/octet-stream")
+	f.Add([]byte(""), "")
+	f.Add([]byte(" \r\n\t"), "text/plain")
+	f.Fuzz(func(t *testing.T, body []byte, contentType string) {
+		apiErr := &APIError{StatusCode: http.StatusBadGateway}
+		parseErrorBody(apiErr, body, contentType)
+		if apiErr.Message == "" && len(body) > 0 {
+			// A message-less envelope with a known code is fine; anything
+			// else must be described.
+			if apiErr.Code == ErrorCodeUnknown {
+				t.Fatalf("no message for body %q", body)
+			}
+		}
+		// Truncated previews gain a short suffix and HTML titles a prefix.
+		if limit := maxMessagePreview + 64; len(apiErr.Message) > limit || len(apiErr.CommandID) > limit {
+			t.Fatalf("message of %d bytes or command ID of %d bytes exceeds %d", len(apiErr.Message), len(apiErr.CommandID), limit)
+		}
+	})
+}
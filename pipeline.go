@@ -0,0 +1,160 @@
+package erlcgo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Enricher adds information to an event, such as resolving Roblox user IDs
+// to display names.
+type Enricher interface {
+	Enrich(ctx context.Context, event Event) (Event, error)
+}
+
+// EnricherFunc adapts a function to an Enricher.
+type EnricherFunc func(ctx context.Context, event Event) (Event, error)
+
+func (f EnricherFunc) Enrich(ctx context.Context, event Event) (Event, error) {
+	return f(ctx, event)
+}
+
+// pipelineStage processes one event. Returning false drops the event.
+type pipelineStage func(ctx context.Context, event Event) (Event, bool, error)
+
+// Pipeline shapes an event stream through filter, map, enrich and throttle
+// stages before handing events to a Sink. Stages run in the order they were
+// added. A Pipeline is itself a Sink, so pipelines can feed an Outbox or
+// another pipeline.
+//
+// Example:
+//
+//	p := erlcgo.NewPipeline().
+//	    Filter(func(e erlcgo.Event) bool { return e.Type == erlcgo.EventTypeKills }).
+//	    Enrich(resolver).
+//	    Throttle(1).
+//	    To(discordSink)
+//	go p.Run(ctx, sub)
+type Pipeline struct {
+	stages  []pipelineStage
+	sink    Sink
+	onError func(Event, error)
+}
+
+// NewPipeline returns an empty pipeline.
+func NewPipeline() *Pipeline {
+	return &Pipeline{}
+}
+
+// Filter keeps only the events for which keep returns true.
+func (p *Pipeline) Filter(keep func(Event) bool) *Pipeline {
+	p.stages = append(p.stages, func(ctx context.Context, event Event) (Event, bool, error) {
+		return event, keep(event), nil
+	})
+	return p
+}
+
+// Map replaces each event with the result of fn.
+func (p *Pipeline) Map(fn func(Event) Event) *Pipeline {
+	p.stages = append(p.stages, func(ctx context.Context, event Event) (Event, bool, error) {
+		return fn(event), true, nil
+	})
+	return p
+}
+
+// Enrich passes each event through e. Events that fail to enrich are dropped
+// and reported to the error handler.
+func (p *Pipeline) Enrich(e Enricher) *Pipeline {
+	p.stages = append(p.stages, func(ctx context.Context, event Event) (Event, bool, error) {
+		enriched, err := e.Enrich(ctx, event)
+		if err != nil {
+			return event, false, err
+		}
+		return enriched, true, nil
+	})
+	return p
+}
+
+// Throttle limits events to perSecond, delaying rather than dropping the
+// excess. This keeps bursts within the rate limits of sinks such as Discord.
+func (p *Pipeline) Throttle(perSecond float64) *Pipeline {
+	if perSecond <= 0 {
+		return p
+	}
+	interval := time.Duration(float64(time.Second) / perSecond)
+	var mu sync.Mutex
+	var next time.Time
+	p.stages = append(p.stages, func(ctx context.Context, event Event) (Event, bool, error) {
+		mu.Lock()
+		now := time.Now()
+		if next.Before(now) {
+			next = now
+		}
+		wait := next.Sub(now)
+		next = next.Add(interval)
+		mu.Unlock()
+
+		if wait > 0 {
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+			select {
+			case <-ctx.Done():
+				return event, false, ctx.Err()
+			case <-timer.C:
+			}
+		}
+		return event, true, nil
+	})
+	return p
+}
+
+// OnError sets a handler for events that fail in a stage or in the sink.
+func (p *Pipeline) OnError(fn func(Event, error)) *Pipeline {
+	p.onError = fn
+	return p
+}
+
+// To sets the sink that receives events leaving the pipeline.
+func (p *Pipeline) To(sink Sink) *Pipeline {
+	p.sink = sink
+	return p
+}
+
+// Send runs event through every stage and delivers it to the sink. An event
+// dropped by a filter is not an error.
+func (p *Pipeline) Send(ctx context.Context, event Event) error {
+	if p.sink == nil {
+		return errors.New("pipeline has no sink")
+	}
+	for _, stage := range p.stages {
+		var keep bool
+		var err error
+		event, keep, err = stage(ctx, event)
+		if err != nil {
+			return err
+		}
+		if !keep {
+			return nil
+		}
+	}
+	return p.sink.Send(ctx, event)
+}
+
+// Run sends every event from sub through the pipeline until its channel
+// closes or ctx is done. Failures are passed to the OnError handler.
+func (p *Pipeline) Run(ctx context.Context, sub *Subscription) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-sub.Events:
+			if !ok {
+				return nil
+			}
+			if err := p.Send(ctx, event); err != nil && p.onError != nil {
+				p.onError(event, err)
+			}
+		}
+	}
+}
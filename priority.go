@@ -0,0 +1,96 @@
+package erlcgo
+
+import "context"
+
+// Priority controls the order in which queued requests are executed.
+// The zero value is PriorityNormal.
+type Priority int
+
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+// priorityLevels is the number of distinct priorities, and so of queue lanes.
+const priorityLevels = 3
+
+type priorityKey struct{}
+
+// WithPriority returns a context carrying the given request priority. Every
+// request made with the context, including those made by helpers on the
+// caller's behalf, is queued at that priority.
+//
+// Example:
+//
+//	ctx := erlcgo.WithPriority(context.Background(), erlcgo.PriorityHigh)
+//	err := client.ExecuteCommand(ctx, ":h Server restart in 1 minute")
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFrom returns the priority stored in ctx, or PriorityNormal if none is set.
+func PriorityFrom(ctx context.Context) Priority {
+	if ctx == nil {
+		return PriorityNormal
+	}
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return p
+	}
+	return PriorityNormal
+}
+
+// hasPriority reports whether ctx carries a priority set with WithPriority.
+func hasPriority(ctx context.Context) bool {
+	_, ok := ctx.Value(priorityKey{}).(Priority)
+	return ok
+}
+
+type pollerKey struct{}
+
+// pollContext marks background polling as low priority, so queued commands
+// and interactive reads go first, unless the caller chose a priority. It also
+// marks the polls as the library's own, which run without a deadline by
+// design and so are not reported with LifecycleNoDeadline.
+func pollContext(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, pollerKey{}, true)
+	if hasPriority(ctx) {
+		return ctx
+	}
+	return WithPriority(ctx, PriorityLow)
+}
+
+// isPoll reports whether ctx was marked by pollContext.
+func isPoll(ctx context.Context) bool {
+	poll, _ := ctx.Value(pollerKey{}).(bool)
+	return poll
+}
+
+// WithCommandPriority sets the queue priority of ExecuteCommand calls whose
+// context carries none. The default is PriorityHigh, so commands such as an
+// urgent :kick jump ahead of reads, and background polling by subscriptions
+// and other pollers runs at PriorityLow.
+//
+// Example:
+//
+//	client := NewClient("your-server-key",
+//	    WithRequestQueue(1, time.Second),
+//	    WithCommandPriority(PriorityNormal),
+//	)
+func WithCommandPriority(p Priority) ClientOption {
+	return func(c *Client) {
+		c.commandPriority = p
+	}
+}
+
+// lane returns the queue lane index for the priority, clamping unknown values.
+func (p Priority) lane() int {
+	switch {
+	case p <= PriorityLow:
+		return 0
+	case p >= PriorityHigh:
+		return priorityLevels - 1
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,75 @@
+package erlcgo
+
+import "time"
+
+// profile is a coherent set of client settings applied by the Profile* options.
+type profile struct {
+	timeout          time.Duration
+	queueWorkers     int
+	queueInterval    time.Duration
+	rateLimitRetries int
+	cacheTTL         time.Duration
+	pollInterval     time.Duration
+}
+
+// ProfileConservative favours staying well inside rate limits and serving
+// slightly older data over request volume. Suitable for small bots and shared
+// hosting. It sets a 15s timeout, a single queue worker at 1.5s spacing with
+// up to 3 automatic rate limit retries, a 5s cache and 5s poll interval.
+func ProfileConservative() ClientOption {
+	return profile{
+		timeout:          time.Second * 15,
+		queueWorkers:     1,
+		queueInterval:    time.Millisecond * 1500,
+		rateLimitRetries: 3,
+		cacheTTL:         time.Second * 5,
+		pollInterval:     time.Second * 5,
+	}.option()
+}
+
+// ProfileBalanced is a middle ground suitable for most bots. It sets a 10s
+// timeout, two queue workers at 1s spacing with up to 2 automatic rate limit
+// retries, a 2s cache and 2s poll interval.
+func ProfileBalanced() ClientOption {
+	return profile{
+		timeout:          time.Second * 10,
+		queueWorkers:     2,
+		queueInterval:    time.Second,
+		rateLimitRetries: 2,
+		cacheTTL:         time.Second * 2,
+		pollInterval:     time.Second * 2,
+	}.option()
+}
+
+// ProfileAggressive favours fresh data and throughput, relying on the rate
+// limiter to back off. Suitable for dashboards with a global API key. It sets
+// a 5s timeout, four queue workers at 250ms spacing with one automatic rate
+// limit retry, a 500ms cache and 1s poll interval.
+func ProfileAggressive() ClientOption {
+	return profile{
+		timeout:          time.Second * 5,
+		queueWorkers:     4,
+		queueInterval:    time.Millisecond * 250,
+		rateLimitRetries: 1,
+		cacheTTL:         time.Millisecond * 500,
+		pollInterval:     time.Second,
+	}.option()
+}
+
+// option applies the profile. Options passed after a profile override the
+// settings it chose.
+func (p profile) option() ClientOption {
+	return func(c *Client) {
+		WithTimeout(p.timeout)(c)
+		WithRequestQueue(p.queueWorkers, p.queueInterval)(c)
+		c.queue.SetRateLimitRetries(p.rateLimitRetries)
+
+		cache := DefaultCacheConfig()
+		cache.TTL = p.cacheTTL
+		WithCache(cache)(c)
+
+		events := DefaultEventConfig()
+		events.PollInterval = p.pollInterval
+		c.eventDefaults = events
+	}
+}
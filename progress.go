@@ -0,0 +1,43 @@
+package erlcgo
+
+import (
+	"context"
+	"errors"
+)
+
+// ProgressFunc is called by batch helpers after each unit of work. done counts
+// completed units out of total, and lastErr is the error of the unit that just
+// finished, if any.
+type ProgressFunc func(done, total int, lastErr error)
+
+// WarmCache fetches each of the given query option sets so later reads are
+// served from the cache. Requests are paced with a Batcher. If ctx is canceled
+// mid-flight, WarmCache stops and returns how many sets were fetched together
+// with the cancellation error; otherwise it returns the errors of any failed
+// fetches joined together.
+//
+// Example:
+//
+//	warmed, err := client.WarmCache(ctx, []erlcgo.ServerQueryOptions{
+//	    {Players: true},
+//	    {Players: true, Vehicles: true},
+//	}, func(done, total int, err error) {
+//	    log.Printf("warmed %d/%d", done, total)
+//	})
+func (c *Client) WarmCache(ctx context.Context, sets []ServerQueryOptions, progress ProgressFunc) (int, error) {
+	ops := make([]Operation, len(sets))
+	for i, opts := range sets {
+		opts := opts
+		ops[i] = func(ctx context.Context) error {
+			_, err := c.GetServer(ctx, opts)
+			return err
+		}
+	}
+
+	b := &Batcher{Client: c, OnProgress: progress}
+	result, err := b.Run(ctx, ops)
+	if err != nil {
+		return result.Done, err
+	}
+	return result.Done, errors.Join(result.Errors...)
+}
@@ -2,6 +2,7 @@ package erlcgo
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"time"
 )
@@ -9,17 +10,57 @@ import (
 // RequestQueue manages queued API requests to prevent rate limit issues.
 type RequestQueue struct {
 	mu       sync.Mutex
-	queue    chan *queuedRequest
+	lanes    [priorityLevels]chan *queuedRequest
 	workers  int
 	interval time.Duration
 	running  bool
 	stop     chan struct{}
+
+	pausedUntil time.Time
+	onPause     QueuePauseHook
+
+	// partitions holds pauses of single rate limit partitions, and held the
+	// requests set aside until they end, in order.
+	partitions map[string]time.Time
+	held       map[string][]*queuedRequest
+
+	rateLimitRetries int
+	failureRetries   int
 }
 
+// QueuePauseHook is called when the queue, or the requests for one server's
+// rate limit bucket within it, pause because the bucket was exhausted. until
+// is the time at which they resume.
+type QueuePauseHook func(until time.Time)
+
 type queuedRequest struct {
 	ctx      context.Context
 	execute  func() error
 	response chan error
+
+	// partitions are the rate limit partitions the request draws on.
+	partitions []string
+
+	// unwatch stops watching ctx while the request is held.
+	unwatch func() bool
+
+	// limited and failed count the runs that were rate limited and that
+	// failed in a way retrying could fix, and err is the error of the last
+	// run, returned if ctx ends while the request waits to be retried.
+	limited int
+	failed  int
+	err     error
+}
+
+// errQueueStopped answers requests still held when the queue stops.
+var errQueueStopped = errors.New("erlc: request queue stopped")
+
+type queuePartitionsKey struct{}
+
+// withQueuePartitions tags ctx with the rate limit partitions its request
+// draws on, so an exhausted partition pauses only its own requests.
+func withQueuePartitions(ctx context.Context, partitions ...string) context.Context {
+	return context.WithValue(ctx, queuePartitionsKey{}, partitions)
 }
 
 // NewRequestQueue creates a new request queue with the specified number of workers
@@ -32,12 +73,15 @@ func NewRequestQueue(workers int, interval time.Duration) *RequestQueue {
 		interval = time.Second // Default to 1 second between requests
 	}
 
-	return &RequestQueue{
-		queue:    make(chan *queuedRequest, 50), // Reduced buffer size
+	q := &RequestQueue{
 		workers:  workers,
 		interval: interval,
 		stop:     make(chan struct{}),
 	}
+	for i := range q.lanes {
+		q.lanes[i] = make(chan *queuedRequest, 50) // Reduced buffer size
+	}
+	return q
 }
 
 // Start begins processing queued requests
@@ -51,7 +95,7 @@ func (q *RequestQueue) Start() {
 	q.running = true
 
 	for i := 0; i < q.workers; i++ {
-		go q.worker()
+		spawn(q, "queue worker", q.worker)
 	}
 }
 
@@ -72,38 +116,334 @@ func (q *RequestQueue) worker() {
 	defer ticker.Stop()
 
 	for {
-		select {
-		case <-q.stop:
-			return
-		case req := <-q.queue:
+		if wait := q.pauseRemaining(); wait > 0 {
+			timer := time.NewTimer(wait)
 			select {
-			case <-req.ctx.Done():
-				req.response <- req.ctx.Err()
-			default:
-				req.response <- req.execute()
-				<-ticker.C
+			case <-q.stop:
+				timer.Stop()
+				return
+			case <-timer.C:
 			}
+			continue
+		}
+
+		req, ok := q.next()
+		if !ok {
+			return
+		}
+		if q.hold(req) {
+			continue
+		}
+		select {
+		case <-req.ctx.Done():
+			req.response <- req.canceled()
+		default:
+			q.run(req)
+			<-ticker.C
+		}
+	}
+}
+
+// next returns the next request to run, always preferring higher priority
+// lanes. It blocks until a request is available or the queue is stopped.
+func (q *RequestQueue) next() (*queuedRequest, bool) {
+	for i := len(q.lanes) - 1; i >= 0; i-- {
+		select {
+		case req := <-q.lanes[i]:
+			return req, true
+		default:
 		}
 	}
+
+	select {
+	case <-q.stop:
+		return nil, false
+	case req := <-q.lanes[PriorityHigh.lane()]:
+		return req, true
+	case req := <-q.lanes[PriorityNormal.lane()]:
+		return req, true
+	case req := <-q.lanes[PriorityLow.lane()]:
+		return req, true
+	}
 }
 
-// Enqueue adds a request to the queue and returns a channel for the response
+// run executes a request and answers it, unless it can be retried. A rate
+// limited request, while rate limit retries remain, pauses its partition, or
+// the whole queue for a request without one, and is set aside until the
+// advised delay has passed. A request that failed in a way retrying could
+// fix, while failure retries remain, is set aside for a backoff starting at
+// the queue interval. Either way the worker is left free for other requests.
+func (q *RequestQueue) run(req *queuedRequest) {
+	q.mu.Lock()
+	rateLimitRetries, failureRetries := q.rateLimitRetries, q.failureRetries
+	q.mu.Unlock()
+
+	req.err = req.execute()
+
+	var rlErr *ErrQueuedRateLimited
+	switch {
+	case errors.As(req.err, &rlErr) && req.limited < rateLimitRetries:
+		req.limited++
+		wait := rlErr.RetryAfter
+		if wait <= 0 {
+			wait = q.interval
+		}
+		if len(req.partitions) > 0 {
+			q.pausePartition(req.partitions[0], time.Now().Add(wait))
+		} else {
+			q.PauseUntil(time.Now().Add(wait))
+		}
+		q.delay(req, wait)
+	case rlErr == nil && req.failed < failureRetries && retryableFailure(req.err):
+		req.failed++
+		wait := q.interval
+		for i := 1; i < req.failed && wait < maxQueueBackoff; i++ {
+			wait *= 2
+		}
+		q.delay(req, min(wait, maxQueueBackoff))
+	default:
+		req.response <- req.err
+	}
+}
+
+// maxQueueBackoff caps the wait before a failed request is retried.
+const maxQueueBackoff = 30 * time.Second
+
+// delay returns req to its lane after wait. If its context ends first, the
+// request is answered with the error of its last run.
+func (q *RequestQueue) delay(req *queuedRequest, wait time.Duration) {
+	// mu keeps the callbacks out until both are set up; done records which
+	// of them took the request.
+	var mu sync.Mutex
+	var done bool
+	mu.Lock()
+	defer mu.Unlock()
+
+	var timer *time.Timer
+	unwatch := context.AfterFunc(req.ctx, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if !done {
+			done = true
+			timer.Stop()
+			req.response <- req.canceled()
+		}
+	})
+	timer = time.AfterFunc(wait, func() {
+		mu.Lock()
+		took := !done
+		done = true
+		mu.Unlock()
+		if took {
+			unwatch()
+			q.requeue(req)
+		}
+	})
+}
+
+// requeue puts a request that was set aside back in its lane.
+func (q *RequestQueue) requeue(req *queuedRequest) {
+	select {
+	case q.lanes[PriorityFrom(req.ctx).lane()] <- req:
+	case <-req.ctx.Done():
+		req.response <- req.canceled()
+	case <-q.stop:
+		req.response <- errQueueStopped
+	}
+}
+
+// canceled is the answer to a request whose context ended while it waited:
+// the error of its last run if it was run before, else the context's error.
+func (r *queuedRequest) canceled() error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.ctx.Err()
+}
+
+// Enqueue adds a request to the queue and returns a channel for the response.
+// The request is placed in the lane for the priority carried by ctx; see WithPriority.
 func (q *RequestQueue) Enqueue(ctx context.Context, execute func() error) error {
+	partitions, _ := ctx.Value(queuePartitionsKey{}).([]string)
 	req := &queuedRequest{
-		ctx:      ctx,
-		execute:  execute,
-		response: make(chan error, 1),
+		ctx:        ctx,
+		execute:    execute,
+		response:   make(chan error, 1),
+		partitions: partitions,
 	}
 
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
-	case q.queue <- req:
+	case q.lanes[PriorityFrom(ctx).lane()] <- req:
 		return <-req.response
 	}
 }
 
 // Depth returns the current number of requests waiting in the queue.
 func (q *RequestQueue) Depth() int {
-	return len(q.queue)
+	depth := 0
+	for _, lane := range q.lanes {
+		depth += len(lane)
+	}
+	return depth
+}
+
+// PauseUntil stops workers from picking up new requests until the given time.
+// Requests already being executed are not interrupted. Calling PauseUntil with a
+// time earlier than the current pause has no effect.
+func (q *RequestQueue) PauseUntil(until time.Time) {
+	q.mu.Lock()
+	if !until.After(q.pausedUntil) {
+		q.mu.Unlock()
+		return
+	}
+	q.pausedUntil = until
+	hook := q.onPause
+	q.mu.Unlock()
+
+	if hook != nil {
+		hook(until)
+	}
+}
+
+// pausePartition holds requests drawing on one rate limit partition until the
+// given time, leaving the rest of the queue running. Like PauseUntil, an
+// earlier time than the current pause has no effect.
+func (q *RequestQueue) pausePartition(partition string, until time.Time) {
+	if partition == "" {
+		return
+	}
+	q.mu.Lock()
+	if !until.After(q.partitions[partition]) {
+		q.mu.Unlock()
+		return
+	}
+	if q.partitions == nil {
+		q.partitions = make(map[string]time.Time)
+	}
+	q.partitions[partition] = until
+	hook := q.onPause
+	q.mu.Unlock()
+
+	if hook != nil {
+		hook(until)
+	}
+}
+
+// hold sets req aside if one of its partitions is paused, reporting whether
+// it did. Held requests go back to their lanes, in order, when the pause ends.
+func (q *RequestQueue) hold(req *queuedRequest) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, p := range req.partitions {
+		until, paused := q.partitions[p]
+		if !paused {
+			continue
+		}
+		wait := time.Until(until)
+		if wait <= 0 && len(q.held[p]) == 0 {
+			delete(q.partitions, p)
+			continue
+		}
+		if q.held == nil {
+			q.held = make(map[string][]*queuedRequest)
+		}
+		if len(q.held[p]) == 0 {
+			time.AfterFunc(max(wait, 0), func() { q.release(p) })
+		}
+		q.held[p] = append(q.held[p], req)
+		req.unwatch = context.AfterFunc(req.ctx, func() { q.drop(p, req) })
+		return true
+	}
+	return false
+}
+
+// drop answers a held request whose context ended before its partition's
+// pause did, so the caller is not kept waiting for the reset. A request
+// release has already taken is left to release.
+func (q *RequestQueue) drop(partition string, req *queuedRequest) {
+	q.mu.Lock()
+	held := q.held[partition]
+	for i, r := range held {
+		if r == req {
+			q.held[partition] = append(held[:i:i], held[i+1:]...)
+			q.mu.Unlock()
+			req.response <- req.canceled()
+			return
+		}
+	}
+	q.mu.Unlock()
+}
+
+// release returns the requests held for a partition to their lanes once its
+// pause has ended.
+func (q *RequestQueue) release(partition string) {
+	q.mu.Lock()
+	if wait := time.Until(q.partitions[partition]); wait > 0 {
+		// The pause was extended while we waited.
+		time.AfterFunc(wait, func() { q.release(partition) })
+		q.mu.Unlock()
+		return
+	}
+	held := q.held[partition]
+	delete(q.held, partition)
+	delete(q.partitions, partition)
+	q.mu.Unlock()
+
+	for _, req := range held {
+		req.unwatch()
+		q.requeue(req)
+	}
+}
+
+// Paused reports whether the queue is currently paused and until when.
+func (q *RequestQueue) Paused() (time.Time, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if time.Now().Before(q.pausedUntil) {
+		return q.pausedUntil, true
+	}
+	return time.Time{}, false
+}
+
+// OnPause registers a hook that is called whenever the queue pauses.
+func (q *RequestQueue) OnPause(h QueuePauseHook) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.onPause = h
+}
+
+func (q *RequestQueue) pauseRemaining() time.Duration {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return time.Until(q.pausedUntil)
+}
+
+// SetRateLimitRetries sets how many times a request rejected with HTTP 429 is
+// retried by the queue after the advised delay before the error is returned to
+// the caller. The default of 0 returns rate limit errors immediately.
+func (q *RequestQueue) SetRateLimitRetries(n int) {
+	if n < 0 {
+		n = 0
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.rateLimitRetries = n
+}
+
+// SetFailureRetries sets how many times a request that failed in a way
+// retrying could fix, such as a server error, a Cloudflare edge outage or a
+// dropped connection, is retried by the queue before the error is returned to
+// the caller and the request is dead-lettered. Retries wait the queue
+// interval, doubling each time up to 30 seconds. The default of 0 returns
+// failures immediately. Commands are retried too, so a command whose
+// response was lost may run twice.
+func (q *RequestQueue) SetFailureRetries(n int) {
+	if n < 0 {
+		n = 0
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.failureRetries = n
 }
@@ -0,0 +1,65 @@
+package erlcgo
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestQueueRateLimitRetryFreesWorker(t *testing.T) {
+	q := NewRequestQueue(1, time.Millisecond)
+	q.SetRateLimitRetries(1)
+	q.Start()
+	defer q.Stop()
+
+	var runs int32
+	limited := make(chan error, 1)
+	go func() {
+		limited <- q.Enqueue(withQueuePartitions(context.Background(), "a"), func() error {
+			if atomic.AddInt32(&runs, 1) == 1 {
+				return &ErrQueuedRateLimited{RetryAfter: 300 * time.Millisecond}
+			}
+			return nil
+		})
+	}()
+	for atomic.LoadInt32(&runs) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The only worker must not sit out partition a's retry delay.
+	start := time.Now()
+	if err := q.Enqueue(withQueuePartitions(context.Background(), "b"), func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if waited := time.Since(start); waited > 150*time.Millisecond {
+		t.Errorf("request for another partition waited %v behind the retry", waited)
+	}
+
+	if err := <-limited; err != nil {
+		t.Errorf("retried request: %v", err)
+	}
+	if n := atomic.LoadInt32(&runs); n != 2 {
+		t.Errorf("rate limited request ran %d times, want 2", n)
+	}
+}
+
+func TestQueueRateLimitRetryCanceled(t *testing.T) {
+	q := NewRequestQueue(1, time.Millisecond)
+	q.SetRateLimitRetries(1)
+	q.Start()
+	defer q.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	limit := &ErrQueuedRateLimited{RetryAfter: time.Minute}
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	err := q.Enqueue(ctx, func() error { return limit })
+	if !errors.Is(err, limit) {
+		t.Errorf("got %v, want the rate limit error of the last run", err)
+	}
+	if waited := time.Since(start); waited > time.Second {
+		t.Errorf("canceled caller waited %v for the retry", waited)
+	}
+}
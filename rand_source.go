@@ -0,0 +1,54 @@
+package erlcgo
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// jitterFraction is how far jitter may move a delay either way, relative to
+// the delay.
+const jitterFraction = 0.1
+
+// WithRandSource sets the source of randomness used for jitter on retry
+// backoff and poll throttling. Pass a seeded source to make that timing
+// reproducible in tests and bug reports. By default a randomly seeded source
+// is used.
+//
+// Example:
+//
+//	client := NewClient("your-server-key",
+//	    WithRandSource(rand.NewPCG(1, 2)),
+//	)
+func WithRandSource(src rand.Source) ClientOption {
+	return func(c *Client) {
+		c.random = &lockedRand{r: rand.New(src)}
+	}
+}
+
+// lockedRand makes a rand.Rand safe for concurrent use.
+type lockedRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+// float64 returns a number in [0, 1) from the source, or from the global
+// source if l is nil.
+func (l *lockedRand) float64() float64 {
+	if l == nil {
+		return rand.Float64()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Float64()
+}
+
+// jitter moves d randomly by up to jitterFraction either way, so clients
+// that failed or were throttled together do not retry in lockstep.
+func (c *Client) jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return d
+	}
+	spread := (c.random.float64()*2 - 1) * jitterFraction
+	return d + time.Duration(spread*float64(d))
+}
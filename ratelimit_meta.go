@@ -1,8 +1,10 @@
 package erlcgo
 
 import (
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -11,6 +13,10 @@ type RateLimitInfo struct {
 	Limit     int
 	Remaining int
 	ResetAt   time.Time 
+
+	// Malformed names the rate limit headers that were present but could
+	// not be parsed or were out of range. Their fields are left zero.
+	Malformed []string
 }
 
 type ResponseMeta struct {
@@ -38,23 +44,69 @@ func parseRateLimitHeaders(h http.Header) *RateLimitInfo {
 
 	rl := &RateLimitInfo{Bucket: bucket}
 
-	if v, err := strconv.Atoi(limitStr); err == nil {
-		rl.Limit = v
+	if limitStr != "" {
+		if v, err := strconv.Atoi(strings.TrimSpace(limitStr)); err == nil && v >= 0 {
+			rl.Limit = v
+		} else {
+			rl.Malformed = append(rl.Malformed, "X-RateLimit-Limit")
+		}
+	}
+	if remainingStr != "" {
+		if v, err := strconv.Atoi(strings.TrimSpace(remainingStr)); err == nil && v >= 0 {
+			rl.Remaining = v
+		} else {
+			rl.Malformed = append(rl.Malformed, "X-RateLimit-Remaining")
+		}
 	}
-	if v, err := strconv.Atoi(remainingStr); err == nil {
-		rl.Remaining = v
+	if rl.Limit > 0 && rl.Remaining > rl.Limit {
+		rl.Remaining = rl.Limit
 	}
 
-	// reset is epoch timestamp. Sometimes seconds; detect ms by magnitude.
 	if resetStr != "" {
-		if epoch, err := strconv.ParseInt(resetStr, 10, 64); err == nil {
-			if epoch > 1_000_000_000_000 {
-				rl.ResetAt = time.UnixMilli(epoch)
-			} else {
-				rl.ResetAt = time.Unix(epoch, 0)
-			}
+		if at, ok := parseResetEpoch(resetStr); ok {
+			rl.ResetAt = at
+		} else {
+			rl.Malformed = append(rl.Malformed, "X-RateLimit-Reset")
 		}
 	}
 
 	return rl
 }
+
+// maxResetAhead bounds how far in the future a rate limit reset may be.
+// Resets further out are treated as malformed rather than stalling requests.
+const maxResetAhead = 24 * time.Hour
+
+// parseResetEpoch parses an X-RateLimit-Reset value: a Unix timestamp in
+// seconds or milliseconds, possibly fractional. It reports false for values
+// that are not finite, not positive, or implausibly far in the future.
+func parseResetEpoch(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	var at time.Time
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if n <= 0 {
+			return time.Time{}, false
+		}
+		at = time.Unix(n, 0)
+		if n >= millisecondThreshold {
+			at = time.UnixMilli(n)
+		}
+	} else {
+		epoch, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(epoch) || math.IsInf(epoch, 0) || epoch <= 0 {
+			return time.Time{}, false
+		}
+		if epoch >= millisecondThreshold {
+			epoch /= 1000
+		}
+		if epoch > float64(time.Now().Add(maxResetAhead).Unix()) {
+			return time.Time{}, false
+		}
+		sec, frac := math.Modf(epoch)
+		at = time.Unix(int64(sec), int64(frac*float64(time.Second)))
+	}
+	if at.After(time.Now().Add(maxResetAhead)) {
+		return time.Time{}, false
+	}
+	return at, true
+}
@@ -39,3 +39,62 @@ func (rl *RateLimiter) ShouldWait(bucket string) (time.Duration, bool) {
 	}
 	return 0, false
 }
+
+// Snapshot returns a copy of the current state of a bucket.
+func (rl *RateLimiter) Snapshot(bucket string) (RateLimit, bool) {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+
+	limit, exists := rl.limits[bucket]
+	if !exists {
+		return RateLimit{}, false
+	}
+	return *limit, true
+}
+
+// ShouldWaitAny reports the longest wait across all the given buckets.
+// It is used to enforce a server's own partition and the shared global-key
+// ceiling in a single check.
+func (rl *RateLimiter) ShouldWaitAny(buckets ...string) (time.Duration, bool) {
+	var longest time.Duration
+	for _, bucket := range buckets {
+		if wait, ok := rl.ShouldWait(bucket); ok && wait > longest {
+			longest = wait
+		}
+	}
+	return longest, longest > 0
+}
+
+// partitionKey returns the limiter key for a bucket scoped to a server key.
+// When a global API key is in use the partition also includes it, so servers
+// sharing a limiter under different global keys never contend for the same budget.
+func partitionKey(globalAPIKey, serverKey, bucket string) string {
+	if serverKey == "" {
+		return bucket
+	}
+	if globalAPIKey == "" {
+		return serverKey + ":" + bucket
+	}
+	return globalAPIKey + "/" + serverKey + ":" + bucket
+}
+
+// ceilingKey returns the limiter key for the account-wide budget of a global API key.
+// It is shared by every server using that key. An empty string means no ceiling applies.
+func ceilingKey(globalAPIKey, bucket string) string {
+	if globalAPIKey == "" {
+		return ""
+	}
+	return globalAPIKey + ":" + bucket
+}
+
+// rateLimitBudget returns the tighter of the server partition and the global
+// key ceiling for a route bucket.
+func (c *Client) rateLimitBudget(routeBucket string) (RateLimit, bool) {
+	state, ok := c.rateLimiter.Snapshot(partitionKey(c.globalAPIKey, c.apiKey, routeBucket))
+	if ceiling := ceilingKey(c.globalAPIKey, routeBucket); ceiling != "" {
+		if cs, cok := c.rateLimiter.Snapshot(ceiling); cok && (!ok || cs.Remaining < state.Remaining) {
+			state, ok = cs, true
+		}
+	}
+	return state, ok
+}
@@ -0,0 +1,215 @@
+package erlcgo
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RedisCheckpointStore is a CheckpointStore backed by Redis. It speaks the
+// Redis protocol directly so erlcgo keeps zero external dependencies.
+type RedisCheckpointStore struct {
+	redisConn
+	// Prefix is prepended to every key, e.g. "erlcgo:checkpoint:".
+	Prefix string
+}
+
+// NewRedisCheckpointStore returns a store using the Redis server at addr.
+// Password, DB and DialTimeout may be set on the returned store before use.
+func NewRedisCheckpointStore(addr string) *RedisCheckpointStore {
+	return &RedisCheckpointStore{redisConn: redisConn{Addr: addr}, Prefix: "erlcgo:checkpoint:"}
+}
+
+func (s *RedisCheckpointStore) Get(ctx context.Context, key string) (Checkpoint, bool, error) {
+	reply, err := s.do(ctx, "GET", s.Prefix+key)
+	if err != nil {
+		return Checkpoint{}, false, err
+	}
+	data, ok := reply.([]byte)
+	if !ok {
+		return Checkpoint{}, false, nil
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return Checkpoint{}, false, err
+	}
+	return cp, true, nil
+}
+
+func (s *RedisCheckpointStore) Set(ctx context.Context, key string, cp Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	_, err = s.do(ctx, "SET", s.Prefix+key, string(data))
+	return err
+}
+
+// redisConn is a minimal single-connection Redis client supporting only what
+// the Redis-backed helpers need.
+type redisConn struct {
+	// Addr is the host:port of the Redis server.
+	Addr string
+	// Password is sent with AUTH when non-empty.
+	Password string
+	// DB is selected with SELECT when non-zero.
+	DB int
+	// DialTimeout bounds connection attempts. Defaults to 5 seconds.
+	DialTimeout time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+// Close closes the connection to Redis, if one is open.
+func (s *redisConn) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// do sends a command and reads its reply, reconnecting once if the
+// connection has gone away.
+func (s *redisConn) do(ctx context.Context, args ...string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reply, err := s.roundTrip(ctx, args)
+	if err != nil && s.conn != nil {
+		var redisErr redisError
+		if !errors.As(err, &redisErr) {
+			s.conn.Close()
+			s.conn = nil
+			reply, err = s.roundTrip(ctx, args)
+		}
+	}
+	return reply, err
+}
+
+func (s *redisConn) roundTrip(ctx context.Context, args []string) (interface{}, error) {
+	if s.conn == nil {
+		if err := s.connect(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		s.conn.SetDeadline(deadline)
+	} else {
+		s.conn.SetDeadline(time.Time{})
+	}
+	if err := writeRESP(s.conn, args); err != nil {
+		return nil, err
+	}
+	return readRESP(s.rd)
+}
+
+func (s *redisConn) connect(ctx context.Context) error {
+	timeout := s.DialTimeout
+	if timeout <= 0 {
+		timeout = time.Second * 5
+	}
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.Addr)
+	if err != nil {
+		return fmt.Errorf("redis dial failed: %w", err)
+	}
+	s.conn = conn
+	s.rd = bufio.NewReader(conn)
+
+	if s.Password != "" {
+		if _, err := s.roundTrip(ctx, []string{"AUTH", s.Password}); err != nil {
+			s.conn.Close()
+			s.conn = nil
+			return err
+		}
+	}
+	if s.DB != 0 {
+		if _, err := s.roundTrip(ctx, []string{"SELECT", strconv.Itoa(s.DB)}); err != nil {
+			s.conn.Close()
+			s.conn = nil
+			return err
+		}
+	}
+	return nil
+}
+
+// redisError is an error reply sent by the Redis server.
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+func writeRESP(w io.Writer, args []string) error {
+	buf := []byte("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(arg)), 10)
+		buf = append(buf, "\r\n"...)
+		buf = append(buf, arg...)
+		buf = append(buf, "\r\n"...)
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// maxRedisBulkLen is the largest bulk string readRESP accepts, Redis's own
+// default proto-max-bulk-len. It keeps a misbehaving server from making the
+// client allocate arbitrary amounts of memory.
+const maxRedisBulkLen = 512 << 20
+
+// readRESP reads a single reply. Bulk strings are returned as []byte, nil bulk
+// strings as nil, simple strings as string and integers as int64.
+func readRESP(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 {
+		return nil, fmt.Errorf("redis: malformed reply %q", line)
+	}
+	line = line[:len(line)-2]
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n == -1 {
+			return nil, nil
+		}
+		if n < 0 || n > maxRedisBulkLen {
+			return nil, fmt.Errorf("redis: invalid bulk length %d", n)
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return data[:n], nil
+	}
+	return nil, fmt.Errorf("redis: unsupported reply type %q", line[0])
+}
+
+func itoa64(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
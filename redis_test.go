@@ -0,0 +1,262 @@
+package erlcgo
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReadRESPBulkLength(t *testing.T) {
+	for _, tc := range []struct {
+		reply   string
+		want    interface{}
+		wantErr bool
+	}{
+		{reply: "$5\r\nhello\r\n", want: "hello"},
+		{reply: "$-1\r\n", want: nil},
+		{reply: "$-2\r\n", wantErr: true},
+		{reply: "$9223372036854775807\r\n", wantErr: true},
+		{reply: "$1073741824\r\n", wantErr: true},
+	} {
+		got, err := readRESP(bufio.NewReader(strings.NewReader(tc.reply)))
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("%q: got %v, want an error", tc.reply, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tc.reply, err)
+			continue
+		}
+		if b, ok := got.([]byte); ok {
+			got = string(b)
+		}
+		if got != tc.want {
+			t.Errorf("%q: got %v, want %v", tc.reply, got, tc.want)
+		}
+	}
+}
+
+func TestWriteRESP(t *testing.T) {
+	var b strings.Builder
+	if err := writeRESP(&b, []string{"SET", "k", "a\r\nb", ""}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "*4\r\n$3\r\nSET\r\n$1\r\nk\r\n$4\r\na\r\nb\r\n$0\r\n\r\n"; b.String() != want {
+		t.Errorf("got %q, want %q", b.String(), want)
+	}
+}
+
+func TestReadRESPTypes(t *testing.T) {
+	for _, tc := range []struct {
+		reply string
+		want  interface{}
+		err   string
+	}{
+		{reply: "+OK\r\n", want: "OK"},
+		{reply: ":42\r\n", want: int64(42)},
+		{reply: "-WRONGTYPE bad\r\n", err: "redis: WRONGTYPE bad"},
+		{reply: "*1\r\n", err: "unsupported reply type"},
+		{reply: "+\n", err: "malformed"},
+	} {
+		got, err := readRESP(bufio.NewReader(strings.NewReader(tc.reply)))
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("%q: error %v, want %q", tc.reply, err, tc.err)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("%q: got %#v, %v; want %#v", tc.reply, got, err, tc.want)
+		}
+	}
+}
+
+// fakeRedis is a Redis server for tests. It keeps string keys in memory,
+// answers GET, SET, AUTH and SELECT, and runs the leader lock scripts by
+// matching their text.
+type fakeRedis struct {
+	ln       net.Listener
+	password string
+
+	mu       sync.Mutex
+	data     map[string]string
+	expires  map[string]time.Time
+	commands []string // command names in the order received
+	dropNext bool     // close the connection instead of answering the next command
+}
+
+// newFakeRedis starts a fake Redis that requires password, if set.
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &fakeRedis{ln: ln, password: password, data: make(map[string]string), expires: make(map[string]time.Time)}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go r.serve(conn)
+		}
+	}()
+	return r
+}
+
+func (r *fakeRedis) addr() string { return r.ln.Addr().String() }
+
+func (r *fakeRedis) received() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.commands...)
+}
+
+func (r *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	rd := bufio.NewReader(conn)
+	authed := r.password == ""
+	for {
+		line, err := rd.ReadString('\n')
+		if err != nil || !strings.HasPrefix(line, "*") {
+			return
+		}
+		n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		args := make([]string, n)
+		for i := range args {
+			arg, err := readRESP(rd)
+			if err != nil {
+				return
+			}
+			args[i] = string(arg.([]byte))
+		}
+
+		r.mu.Lock()
+		r.commands = append(r.commands, args[0])
+		drop := r.dropNext
+		r.dropNext = false
+		var reply string
+		switch {
+		case drop:
+		case args[0] == "AUTH":
+			authed = args[1] == r.password
+			reply = "+OK\r\n"
+			if !authed {
+				reply = "-WRONGPASS invalid password\r\n"
+			}
+		case !authed:
+			reply = "-NOAUTH Authentication required\r\n"
+		default:
+			reply = r.exec(args)
+		}
+		r.mu.Unlock()
+		if drop {
+			return
+		}
+		conn.Write([]byte(reply))
+	}
+}
+
+// exec runs a command and returns its encoded reply. r.mu must be held.
+func (r *fakeRedis) exec(args []string) string {
+	get := func(key string) (string, bool) {
+		if exp, ok := r.expires[key]; ok && time.Now().After(exp) {
+			delete(r.data, key)
+			delete(r.expires, key)
+		}
+		v, ok := r.data[key]
+		return v, ok
+	}
+	bulk := func(v string) string { return "$" + strconv.Itoa(len(v)) + "\r\n" + v + "\r\n" }
+
+	switch args[0] {
+	case "SELECT":
+		return "+OK\r\n"
+	case "GET":
+		if v, ok := get(args[1]); ok {
+			return bulk(v)
+		}
+		return "$-1\r\n"
+	case "SET":
+		r.data[args[1]] = args[2]
+		delete(r.expires, args[1])
+		return "+OK\r\n"
+	case "EVAL":
+		key, argv := args[3], args[4:]
+		v, held := get(key)
+		switch args[1] {
+		case redisAcquireScript:
+			ms, _ := strconv.Atoi(argv[1])
+			if held && v != argv[0] {
+				return ":0\r\n"
+			}
+			r.data[key] = argv[0]
+			r.expires[key] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+			return ":1\r\n"
+		case redisReleaseScript:
+			if held && v == argv[0] {
+				delete(r.data, key)
+				delete(r.expires, key)
+				return ":1\r\n"
+			}
+			return ":0\r\n"
+		}
+	}
+	return "-ERR unknown command '" + args[0] + "'\r\n"
+}
+
+func TestRedisCheckpointStore(t *testing.T) {
+	srv := newFakeRedis(t, "hunter2")
+	store := NewRedisCheckpointStore(srv.addr())
+	store.Password = "hunter2"
+	store.DB = 3
+	defer store.Close()
+	ctx := context.Background()
+
+	if _, ok, err := store.Get(ctx, "server.kills"); ok || err != nil {
+		t.Fatalf("Get before Set = %v, %v; want a miss", ok, err)
+	}
+	want := Checkpoint{Version: 1, Timestamp: 1700000000, Keys: []string{"a", "b"}}
+	if err := store.Set(ctx, "server.kills", want); err != nil {
+		t.Fatal(err)
+	}
+	srv.mu.Lock()
+	_, prefixed := srv.data["erlcgo:checkpoint:server.kills"]
+	srv.mu.Unlock()
+	if !prefixed {
+		t.Error("checkpoint not stored under the prefixed key")
+	}
+
+	// A dropped connection is redialed, authenticating again.
+	srv.mu.Lock()
+	srv.dropNext = true
+	srv.mu.Unlock()
+	got, ok, err := store.Get(ctx, "server.kills")
+	if err != nil || !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("Get = %+v, %v, %v; want %+v", got, ok, err, want)
+	}
+	wantCommands := []string{"AUTH", "SELECT", "GET", "SET", "GET", "AUTH", "SELECT", "GET"}
+	if got := srv.received(); !reflect.DeepEqual(got, wantCommands) {
+		t.Errorf("commands %v, want %v", got, wantCommands)
+	}
+
+	// Error replies are returned without reconnecting.
+	bad := NewRedisCheckpointStore(srv.addr())
+	bad.Password = "wrong"
+	defer bad.Close()
+	var redisErr redisError
+	if _, _, err := bad.Get(ctx, "server.kills"); !errors.As(err, &redisErr) {
+		t.Errorf("Get with a wrong password: %v, want a redis error", err)
+	}
+}
@@ -0,0 +1,246 @@
+package erlcgo
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxRelayEntries bounds the relay's response cache. It covers every
+// combination of /v2/server options and the other GET routes; further
+// queries evict the oldest entry.
+const maxRelayEntries = 512
+
+// relayRateLimitHeaders are the upstream rate limit headers passed through
+// to downstream clients so they can pace themselves.
+var relayRateLimitHeaders = []string{"X-RateLimit-Bucket", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"}
+
+// RelayConfig configures a Relay.
+type RelayConfig struct {
+	// TTL is how long an upstream response is reused for downstream requests
+	// with the same query. Defaults to one second.
+	TTL time.Duration
+
+	// AccessKey must be sent by downstream clients as their Server-Key
+	// header. Downstream clients never see the real server key. A relay
+	// without an AccessKey refuses every request unless AllowAnonymous is set.
+	AccessKey string
+
+	// AllowAnonymous serves requests without an AccessKey, forwarding them
+	// with the real server key. Only use it on a trusted network.
+	AllowAnonymous bool
+
+	// AllowCommands lets downstream clients execute commands through the relay.
+	AllowCommands bool
+
+	// Grafana, when set, is served under /grafana/. When AccessKey is set,
+	// add it as a Server-Key custom header in the Grafana datasource settings.
+	Grafana *GrafanaDatasource
+}
+
+// Relay serves the PRC API from a single upstream Client, so many downstream
+// erlcgo clients can share one rate limit budget. Every GET under /v1/ and
+// /v2/ is forwarded and cached for TTL, and commands are forwarded when
+// AllowCommands is set; other methods are rejected. Point downstream clients
+// at the relay with WithBaseURL.
+//
+// Example:
+//
+//	upstream := erlcgo.NewClient("real-server-key")
+//	relay := erlcgo.NewRelay(upstream, erlcgo.RelayConfig{AccessKey: "internal-secret"})
+//	go http.ListenAndServe(":8080", relay)
+//
+//	downstream := erlcgo.NewClient("internal-secret", erlcgo.WithBaseURL("http://localhost:8080"))
+type Relay struct {
+	client *Client
+	config RelayConfig
+
+	mu      sync.Mutex
+	entries map[string]relayEntry
+}
+
+type relayEntry struct {
+	body      []byte
+	fetchedAt time.Time
+}
+
+// NewRelay creates a relay that forwards requests through client.
+func NewRelay(client *Client, config RelayConfig) *Relay {
+	if config.TTL <= 0 {
+		config.TTL = time.Second
+	}
+	return &Relay{
+		client:  client,
+		config:  config,
+		entries: make(map[string]relayEntry),
+	}
+}
+
+func (r *Relay) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if r.config.AccessKey == "" && !r.config.AllowAnonymous {
+		writeRelayError(w, http.StatusForbidden, ErrorCodeInvalidServerKey, "relay has no access key configured")
+		return
+	}
+	if r.config.AccessKey != "" && subtle.ConstantTimeCompare([]byte(req.Header.Get("Server-Key")), []byte(r.config.AccessKey)) != 1 {
+		writeRelayError(w, http.StatusForbidden, ErrorCodeInvalidServerKey, "invalid relay access key")
+		return
+	}
+
+	if r.config.Grafana != nil && strings.HasPrefix(req.URL.Path, "/grafana/") {
+		http.StripPrefix("/grafana", r.config.Grafana).ServeHTTP(w, req)
+		return
+	}
+
+	api := strings.HasPrefix(req.URL.Path, "/v1/") || strings.HasPrefix(req.URL.Path, "/v2/")
+	switch {
+	case req.Method == http.MethodPost && req.URL.Path == "/v2/server/command":
+		r.serveCommand(w, req)
+	case req.Method == http.MethodGet && api:
+		r.serveGet(w, req)
+	case api:
+		w.Header().Set("Allow", http.MethodGet)
+		writeRelayError(w, http.StatusMethodNotAllowed, ErrorCodeUnknown, req.Method+" is not supported by relay")
+	default:
+		writeRelayError(w, http.StatusNotFound, ErrorCodeUnknown, "route not supported by relay")
+	}
+}
+
+func (r *Relay) serveGet(w http.ResponseWriter, req *http.Request) {
+	path := req.URL.Path
+	if req.URL.RawQuery != "" {
+		path += "?" + req.URL.RawQuery
+	}
+
+	r.mu.Lock()
+	entry, ok := r.entries[path]
+	r.mu.Unlock()
+	if ok && time.Since(entry.fetchedAt) < r.config.TTL {
+		r.writeRateLimit(w, "global")
+		writeRelayBody(w, http.StatusOK, entry.body)
+		return
+	}
+
+	// Concurrent misses for the same path are coalesced by the client.
+	var raw json.RawMessage
+	if err := r.client.get(req.Context(), path, &raw); err != nil {
+		writeRelayUpstreamError(w, err)
+		return
+	}
+
+	r.mu.Lock()
+	if _, exists := r.entries[path]; !exists && len(r.entries) >= maxRelayEntries {
+		r.evict()
+	}
+	r.entries[path] = relayEntry{body: raw, fetchedAt: time.Now()}
+	r.mu.Unlock()
+	r.writeRateLimit(w, "global")
+	writeRelayBody(w, http.StatusOK, raw)
+}
+
+// evict drops expired entries, or the oldest entry if none have expired.
+// r.mu must be held.
+func (r *Relay) evict() {
+	var oldest string
+	var oldestAt time.Time
+	for path, e := range r.entries {
+		if time.Since(e.fetchedAt) >= r.config.TTL {
+			delete(r.entries, path)
+			continue
+		}
+		if oldest == "" || e.fetchedAt.Before(oldestAt) {
+			oldest, oldestAt = path, e.fetchedAt
+		}
+	}
+	if len(r.entries) >= maxRelayEntries {
+		delete(r.entries, oldest)
+	}
+}
+
+// writeRateLimit reports the upstream client's latest known budget for
+// routeBucket in the PRC rate limit headers, naming routeBucket as the bucket
+// so downstream clients record it where they look it up.
+func (r *Relay) writeRateLimit(w http.ResponseWriter, routeBucket string) {
+	if r.client.rateLimiter == nil {
+		return
+	}
+	state, ok := r.client.rateLimitBudget(routeBucket)
+	if !ok {
+		return
+	}
+	h := w.Header()
+	h.Set("X-RateLimit-Bucket", routeBucket)
+	if state.Limit > 0 {
+		h.Set("X-RateLimit-Limit", strconv.Itoa(state.Limit))
+	}
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(state.Remaining))
+	if !state.Reset.IsZero() {
+		h.Set("X-RateLimit-Reset", itoa64(state.Reset.Unix()))
+	}
+}
+
+func (r *Relay) serveCommand(w http.ResponseWriter, req *http.Request) {
+	if !r.config.AllowCommands {
+		writeRelayError(w, http.StatusForbidden, ErrorCodeRestricted, "commands are disabled on this relay")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, 64<<10))
+	if err != nil {
+		writeRelayError(w, http.StatusBadRequest, ErrorCodeInvalidCommand, "failed to read request body")
+		return
+	}
+	var payload struct {
+		Command string `json:"command"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil || payload.Command == "" {
+		writeRelayError(w, http.StatusBadRequest, ErrorCodeInvalidCommand, "request body must contain a command")
+		return
+	}
+
+	if err := r.client.ExecuteCommand(req.Context(), payload.Command); err != nil {
+		writeRelayUpstreamError(w, err)
+		return
+	}
+	r.writeRateLimit(w, "command")
+	writeRelayBody(w, http.StatusOK, []byte(`{"message":"Success"}`))
+}
+
+func writeRelayBody(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+func writeRelayError(w http.ResponseWriter, status int, code ErrorCode, message string) {
+	body, _ := json.Marshal(APIError{Code: code, Message: message})
+	writeRelayBody(w, status, body)
+}
+
+// writeRelayUpstreamError passes PRC errors through unchanged, including any
+// Retry-After and rate limit headers, and reports local failures as 502s.
+func writeRelayUpstreamError(w http.ResponseWriter, err error) {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		writeRelayError(w, http.StatusBadGateway, ErrorCodeCommunication, err.Error())
+		return
+	}
+	for _, name := range relayRateLimitHeaders {
+		if v := apiErr.Headers.Get(name); v != "" {
+			w.Header().Set(name, v)
+		}
+	}
+	if apiErr.RetryAfter != nil {
+		w.Header().Set("Retry-After", itoa64(int64(apiErr.RetryAfter.Seconds()+0.5)))
+	}
+	if len(apiErr.Body) > 0 {
+		writeRelayBody(w, apiErr.StatusCode, apiErr.Body)
+		return
+	}
+	writeRelayError(w, apiErr.StatusCode, apiErr.Code, apiErr.Message)
+}
@@ -0,0 +1,183 @@
+package erlcgo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestRelay serves a relay whose upstream client talks to upstream.
+func newTestRelay(t *testing.T, upstream http.Handler, config RelayConfig) *httptest.Server {
+	t.Helper()
+	prc := httptest.NewServer(upstream)
+	t.Cleanup(prc.Close)
+	client := NewClient("real-key", WithBaseURL(prc.URL))
+	t.Cleanup(client.Close)
+	relay := httptest.NewServer(NewRelay(client, config))
+	t.Cleanup(relay.Close)
+	return relay
+}
+
+func TestRelayAccessKey(t *testing.T) {
+	var hits int32
+	upstream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if r.Header.Get("Server-Key") != "real-key" {
+			t.Errorf("upstream got Server-Key %q, want the real key", r.Header.Get("Server-Key"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"Name":"Test"}`))
+	})
+
+	for _, tc := range []struct {
+		name   string
+		config RelayConfig
+		key    string
+		ok     bool
+	}{
+		{name: "valid key", config: RelayConfig{AccessKey: "secret"}, key: "secret", ok: true},
+		{name: "wrong key", config: RelayConfig{AccessKey: "secret"}, key: "guess"},
+		{name: "missing key", config: RelayConfig{AccessKey: "secret"}},
+		{name: "no access key configured", key: "anything"},
+		{name: "anonymous", config: RelayConfig{AllowAnonymous: true}, ok: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			relay := newTestRelay(t, upstream, tc.config)
+			req, _ := http.NewRequest(http.MethodGet, relay.URL+"/v2/server", nil)
+			if tc.key != "" {
+				req.Header.Set("Server-Key", tc.key)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			want := http.StatusForbidden
+			if tc.ok {
+				want = http.StatusOK
+			}
+			if resp.StatusCode != want {
+				t.Errorf("status %d, want %d", resp.StatusCode, want)
+			}
+		})
+	}
+}
+
+func TestRelayCachesGets(t *testing.T) {
+	var hits int32
+	upstream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("X-RateLimit-Bucket", "global")
+		w.Header().Set("X-RateLimit-Limit", "35")
+		w.Header().Set("X-RateLimit-Remaining", "30")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	})
+	relay := newTestRelay(t, upstream, RelayConfig{AccessKey: "secret", TTL: 100 * time.Millisecond})
+
+	get := func(path string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, relay.URL+path, nil)
+		req.Header.Set("Server-Key", "secret")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s: status %d", path, resp.StatusCode)
+		}
+		return resp
+	}
+
+	// Any GET route is relayed, not only /v2/server.
+	resp := get("/v1/server/bans")
+	if got := resp.Header.Get("X-RateLimit-Bucket"); got != "global" {
+		t.Errorf("X-RateLimit-Bucket %q, want global", got)
+	}
+	if got := resp.Header.Get("X-RateLimit-Remaining"); got != "30" {
+		t.Errorf("X-RateLimit-Remaining %q, want 30", got)
+	}
+	get("/v1/server/bans")
+	if n := atomic.LoadInt32(&hits); n != 1 {
+		t.Errorf("upstream got %d requests within the TTL, want 1", n)
+	}
+	get("/v2/server?Players=true")
+	if n := atomic.LoadInt32(&hits); n != 2 {
+		t.Errorf("upstream got %d requests, want 2 for a different query", n)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	get("/v1/server/bans")
+	if n := atomic.LoadInt32(&hits); n != 3 {
+		t.Errorf("upstream got %d requests after the TTL, want 3", n)
+	}
+}
+
+func TestRelayRejectsUnsupportedRoutes(t *testing.T) {
+	upstream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected upstream %s %s", r.Method, r.URL.Path)
+	})
+	relay := newTestRelay(t, upstream, RelayConfig{AllowAnonymous: true})
+
+	for _, tc := range []struct {
+		method, path string
+		status       int
+	}{
+		{http.MethodDelete, "/v1/server/bans", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/v2/server", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/v2/server/command", http.StatusForbidden}, // commands disabled
+		{http.MethodGet, "/metrics", http.StatusNotFound},
+	} {
+		req, _ := http.NewRequest(tc.method, relay.URL+tc.path, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.status {
+			t.Errorf("%s %s: status %d, want %d", tc.method, tc.path, resp.StatusCode, tc.status)
+		}
+	}
+}
+
+func TestRelayPassesUpstreamErrors(t *testing.T) {
+	upstream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Bucket", "command")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"code":3002,"message":"Server offline"}`))
+	})
+	relay := newTestRelay(t, upstream, RelayConfig{AllowAnonymous: true, AllowCommands: true})
+	c := NewClient("downstream", WithBaseURL(relay.URL))
+	defer c.Close()
+
+	err := c.ExecuteCommand(context.Background(), ":h hello")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got %v, want an APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusUnprocessableEntity || apiErr.Code != ErrorCode(3002) {
+		t.Errorf("got status %d code %d, want the upstream 422 and code 3002", apiErr.StatusCode, apiErr.Code)
+	}
+	if got := apiErr.Headers.Get("X-RateLimit-Bucket"); got != "command" {
+		t.Errorf("X-RateLimit-Bucket %q, want command", got)
+	}
+
+	// Failures reaching upstream are reported as bad gateway.
+	dead := NewClient("real-key", WithBaseURL("http://127.0.0.1:1"))
+	defer dead.Close()
+	rec := httptest.NewRecorder()
+	NewRelay(dead, RelayConfig{AllowAnonymous: true}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v2/server", nil))
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("unreachable upstream: status %d, want 502", rec.Code)
+	}
+}
@@ -0,0 +1,260 @@
+package erlcgo
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DataKind identifies a data type served by a Repository.
+type DataKind string
+
+const (
+	DataServer         DataKind = "server"
+	DataPlayers        DataKind = "players"
+	DataStaff          DataKind = "staff"
+	DataQueue          DataKind = "queue"
+	DataVehicles       DataKind = "vehicles"
+	DataBans           DataKind = "bans"
+	DataCommandLogs    DataKind = "commandlogs"
+	DataKillLogs       DataKind = "killlogs"
+	DataModCalls       DataKind = "modcalls"
+	DataJoinLogs       DataKind = "joinlogs"
+	DataEmergencyCalls DataKind = "emergencycalls"
+)
+
+// DataPolicy controls how a Repository serves one data type.
+type DataPolicy struct {
+	// MaxAge is how long a fetched value is served without refetching.
+	// Zero fetches on every read.
+	MaxAge time.Duration
+
+	// StaleIfError is how old a value may be and still be served when a
+	// refetch fails. Zero returns the error instead.
+	StaleIfError time.Duration
+}
+
+// RepositoryConfig configures a Repository.
+type RepositoryConfig struct {
+	// Default applies to data types without an entry in Policies.
+	Default DataPolicy
+
+	// Policies overrides the policy per data type.
+	Policies map[DataKind]DataPolicy
+}
+
+// Repository is a read-through layer over a Client with declarative
+// freshness and fallback policies per data type, so application code asks for
+// data without deciding how fresh it must be at every call site. Concurrent
+// reads of the same type share one fetch.
+//
+// Example:
+//
+//	repo := erlcgo.NewRepository(client, erlcgo.RepositoryConfig{
+//	    Default: erlcgo.DataPolicy{MaxAge: 5 * time.Second, StaleIfError: time.Minute},
+//	    Policies: map[erlcgo.DataKind]erlcgo.DataPolicy{
+//	        erlcgo.DataBans: {MaxAge: 5 * time.Minute, StaleIfError: time.Hour},
+//	    },
+//	})
+//	players, err := repo.Players(ctx)
+type Repository struct {
+	client *Client
+	config RepositoryConfig
+	group  group
+
+	mu    sync.Mutex
+	slots map[DataKind]repoSlot
+}
+
+type repoSlot struct {
+	value     interface{}
+	fetchedAt time.Time
+}
+
+// NewRepository creates a repository reading through client.
+func NewRepository(client *Client, config RepositoryConfig) *Repository {
+	return &Repository{
+		client: client,
+		config: config,
+		slots:  make(map[DataKind]repoSlot),
+	}
+}
+
+func (r *Repository) policy(kind DataKind) DataPolicy {
+	if p, ok := r.config.Policies[kind]; ok {
+		return p
+	}
+	return r.config.Default
+}
+
+// Invalidate drops cached values so the next read refetches. With no kinds,
+// every data type is invalidated.
+func (r *Repository) Invalidate(kinds ...DataKind) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(kinds) == 0 {
+		r.slots = make(map[DataKind]repoSlot)
+		return
+	}
+	for _, k := range kinds {
+		delete(r.slots, k)
+	}
+}
+
+// get serves kind according to its policy, calling fetch when needed.
+func (r *Repository) get(ctx context.Context, kind DataKind, fetch func(context.Context) (interface{}, error)) (interface{}, error) {
+	policy := r.policy(kind)
+
+	r.mu.Lock()
+	slot, ok := r.slots[kind]
+	r.mu.Unlock()
+	if ok && policy.MaxAge > 0 && time.Since(slot.fetchedAt) < policy.MaxAge {
+		return slot.value, nil
+	}
+
+	value, err := r.group.Do(string(kind), func() (interface{}, error) {
+		v, err := fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		r.mu.Lock()
+		r.slots[kind] = repoSlot{value: v, fetchedAt: time.Now()}
+		r.mu.Unlock()
+		return v, nil
+	})
+	if err != nil {
+		if ok && policy.StaleIfError > 0 && time.Since(slot.fetchedAt) < policy.StaleIfError {
+			return slot.value, nil
+		}
+		return nil, err
+	}
+	return value, nil
+}
+
+// server fetches one section of /v2/server and extracts it with pick.
+func (r *Repository) server(ctx context.Context, kind DataKind, opts ServerQueryOptions, pick func(*ERLCServerResponse) interface{}) (interface{}, error) {
+	return r.get(ctx, kind, func(ctx context.Context) (interface{}, error) {
+		resp, err := r.client.GetServer(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		return pick(resp), nil
+	})
+}
+
+// Server returns server information without optional sections.
+func (r *Repository) Server(ctx context.Context) (*ERLCServerResponse, error) {
+	v, err := r.server(ctx, DataServer, ServerQueryOptions{}, func(s *ERLCServerResponse) interface{} { return s })
+	if err != nil {
+		return nil, err
+	}
+	return v.(*ERLCServerResponse), nil
+}
+
+// Players returns the players in the server.
+func (r *Repository) Players(ctx context.Context) ([]ERLCServerPlayer, error) {
+	v, err := r.server(ctx, DataPlayers, ServerQueryOptions{Players: true}, func(s *ERLCServerResponse) interface{} { return s.Players })
+	if err != nil {
+		return nil, err
+	}
+	return v.([]ERLCServerPlayer), nil
+}
+
+// Staff returns the server's staff lists.
+func (r *Repository) Staff(ctx context.Context) (*ERLCStaff, error) {
+	v, err := r.server(ctx, DataStaff, ServerQueryOptions{Staff: true}, func(s *ERLCServerResponse) interface{} { return s.Staff })
+	if err != nil {
+		return nil, err
+	}
+	return v.(*ERLCStaff), nil
+}
+
+// Queue returns the user IDs waiting in the join queue.
+func (r *Repository) Queue(ctx context.Context) ([]int64, error) {
+	v, err := r.server(ctx, DataQueue, ServerQueryOptions{Queue: true}, func(s *ERLCServerResponse) interface{} { return s.Queue })
+	if err != nil {
+		return nil, err
+	}
+	return v.([]int64), nil
+}
+
+// Vehicles returns the spawned vehicles.
+func (r *Repository) Vehicles(ctx context.Context) ([]ERLCVehicle, error) {
+	v, err := r.server(ctx, DataVehicles, ServerQueryOptions{Vehicles: true}, func(s *ERLCServerResponse) interface{} { return s.Vehicles })
+	if err != nil {
+		return nil, err
+	}
+	return v.([]ERLCVehicle), nil
+}
+
+// CommandLogs returns the command log, narrowed by any filters given.
+func (r *Repository) CommandLogs(ctx context.Context, filter ...LogFilter) ([]ERLCCommandLog, error) {
+	v, err := r.server(ctx, DataCommandLogs, ServerQueryOptions{CommandLogs: true}, func(s *ERLCServerResponse) interface{} { return s.CommandLogs })
+	if err != nil {
+		return nil, err
+	}
+	logs := v.([]ERLCCommandLog)
+	for _, f := range filter {
+		logs = f.CommandLogs(logs)
+	}
+	return logs, nil
+}
+
+// KillLogs returns the kill log, narrowed by any filters given.
+func (r *Repository) KillLogs(ctx context.Context, filter ...LogFilter) ([]ERLCKillLog, error) {
+	v, err := r.server(ctx, DataKillLogs, ServerQueryOptions{KillLogs: true}, func(s *ERLCServerResponse) interface{} { return s.KillLogs })
+	if err != nil {
+		return nil, err
+	}
+	logs := v.([]ERLCKillLog)
+	for _, f := range filter {
+		logs = f.KillLogs(logs)
+	}
+	return logs, nil
+}
+
+// ModCalls returns the mod call log, narrowed by any filters given.
+func (r *Repository) ModCalls(ctx context.Context, filter ...LogFilter) ([]ERLCModCallLog, error) {
+	v, err := r.server(ctx, DataModCalls, ServerQueryOptions{ModCalls: true}, func(s *ERLCServerResponse) interface{} { return s.ModCalls })
+	if err != nil {
+		return nil, err
+	}
+	logs := v.([]ERLCModCallLog)
+	for _, f := range filter {
+		logs = f.ModCalls(logs)
+	}
+	return logs, nil
+}
+
+// JoinLogs returns the join log, narrowed by any filters given.
+func (r *Repository) JoinLogs(ctx context.Context, filter ...LogFilter) ([]ERLCJoinLog, error) {
+	v, err := r.server(ctx, DataJoinLogs, ServerQueryOptions{JoinLogs: true}, func(s *ERLCServerResponse) interface{} { return s.JoinLogs })
+	if err != nil {
+		return nil, err
+	}
+	logs := v.([]ERLCJoinLog)
+	for _, f := range filter {
+		logs = f.JoinLogs(logs)
+	}
+	return logs, nil
+}
+
+// EmergencyCalls returns the active emergency calls.
+func (r *Repository) EmergencyCalls(ctx context.Context) ([]ERLCEmergencyCall, error) {
+	v, err := r.server(ctx, DataEmergencyCalls, ServerQueryOptions{EmergencyCalls: true}, func(s *ERLCServerResponse) interface{} { return s.EmergencyCalls })
+	if err != nil {
+		return nil, err
+	}
+	return v.([]ERLCEmergencyCall), nil
+}
+
+// Bans returns the ban list.
+func (r *Repository) Bans(ctx context.Context) (ERLCBans, error) {
+	v, err := r.get(ctx, DataBans, func(ctx context.Context) (interface{}, error) {
+		return r.client.GetBans(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(ERLCBans), nil
+}
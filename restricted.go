@@ -0,0 +1,110 @@
+package erlcgo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// restrictedVerbs are the command verbs PRC refuses over the API with code
+// 4002. Permission changes can only be made in game.
+var restrictedVerbs = map[string]bool{
+	"admin":    true,
+	"unadmin":  true,
+	"mod":      true,
+	"unmod":    true,
+	"helper":   true,
+	"unhelper": true,
+}
+
+var restrictedMu sync.RWMutex
+
+// RegisterRestrictedCommand adds verbs, with or without the colon, to the
+// list used by IsRestrictedCommand, for restrictions PRC adds before this
+// package is updated.
+func RegisterRestrictedCommand(verbs ...string) {
+	restrictedMu.Lock()
+	defer restrictedMu.Unlock()
+	for _, v := range verbs {
+		restrictedVerbs[strings.ToLower(strings.TrimLeft(v, ":/"))] = true
+	}
+}
+
+// IsRestrictedCommand reports whether PRC is known to refuse command over the
+// API with code 4002.
+//
+// Example:
+//
+//	if erlcgo.IsRestrictedCommand(":unadmin Player1") {
+//	    return errors.New("demote staff in game")
+//	}
+func IsRestrictedCommand(command string) bool {
+	verb := commandVerb(command)
+	restrictedMu.RLock()
+	defer restrictedMu.RUnlock()
+	return restrictedVerbs[verb]
+}
+
+// commandVerb returns the lower-cased verb of command, without its prefix.
+func commandVerb(command string) string {
+	fields := strings.Fields(strings.TrimLeft(strings.TrimSpace(command), ":/"))
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToLower(fields[0])
+}
+
+// RestrictedCommandError is returned under WithRestrictedCommandCheck for a
+// command PRC is known to refuse. It matches ErrRestricted with errors.Is,
+// like the API's own 4002 error.
+type RestrictedCommandError struct {
+	Command string
+	Verb    string
+}
+
+func (e *RestrictedCommandError) Error() string {
+	return fmt.Sprintf("erlc: command :%s is restricted and cannot be sent through the API", e.Verb)
+}
+
+func (e *RestrictedCommandError) Is(target error) bool {
+	return target == ErrRestricted
+}
+
+// WithRestrictedCommandCheck makes ExecuteCommand refuse restricted commands
+// locally with a RestrictedCommandError instead of spending a request on a
+// certain 4002. Verbs the API refuses with 4002 while the check is on are
+// remembered by the client and refused locally from then on.
+//
+// Example:
+//
+//	client := NewClient("your-server-key", WithRestrictedCommandCheck(true))
+func WithRestrictedCommandCheck(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.checkRestricted = enabled
+	}
+}
+
+// checkRestrictedCommand returns a RestrictedCommandError if the check is on
+// and command is known to be restricted.
+func (c *Client) checkRestrictedCommand(command string) error {
+	if !c.checkRestricted {
+		return nil
+	}
+	verb := commandVerb(command)
+	if _, learned := c.restrictedLearned.Load(verb); learned || IsRestrictedCommand(command) {
+		return &RestrictedCommandError{Command: command, Verb: verb}
+	}
+	return nil
+}
+
+// learnRestricted remembers command's verb if err is the API's 4002.
+func (c *Client) learnRestricted(command string, err error) {
+	var apiErr *APIError
+	if !c.checkRestricted || !errors.As(err, &apiErr) || apiErr.Code != ErrorCodeRestricted {
+		return
+	}
+	if verb := commandVerb(command); verb != "" {
+		c.restrictedLearned.Store(verb, struct{}{})
+	}
+}
@@ -0,0 +1,81 @@
+package erlcgo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestIsRestrictedCommand(t *testing.T) {
+	for cmd, want := range map[string]bool{
+		":admin Player1":    true,
+		"/UNMOD Player1":    true,
+		"  :helper Player1": true,
+		":ban Player1":      false,
+		":h admin":          false,
+		"":                  false,
+	} {
+		if got := IsRestrictedCommand(cmd); got != want {
+			t.Errorf("IsRestrictedCommand(%q) = %v, want %v", cmd, got, want)
+		}
+	}
+
+	RegisterRestrictedCommand(":Shutdown")
+	t.Cleanup(func() {
+		restrictedMu.Lock()
+		delete(restrictedVerbs, "shutdown")
+		restrictedMu.Unlock()
+	})
+	if !IsRestrictedCommand(":shutdown") {
+		t.Error("registered verb is not restricted")
+	}
+}
+
+func TestRestrictedCommandCheck(t *testing.T) {
+	var sent int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&sent, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"code":4002,"message":"Restricted command"}`))
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	c := NewClient("key", WithBaseURL(srv.URL), WithRestrictedCommandCheck(true))
+	defer c.Close()
+
+	// Known restricted verbs are refused without a request.
+	err := c.ExecuteCommand(ctx, ":admin Player1")
+	var rerr *RestrictedCommandError
+	if !errors.As(err, &rerr) || rerr.Verb != "admin" || !errors.Is(err, ErrRestricted) {
+		t.Fatalf("got %v, want a RestrictedCommandError matching ErrRestricted", err)
+	}
+	if n := atomic.LoadInt32(&sent); n != 0 {
+		t.Fatalf("server got %d requests, want 0", n)
+	}
+
+	// A verb the API refuses with 4002 is learned and refused locally after.
+	if err := c.ExecuteCommand(ctx, ":newperm Player1"); !errors.Is(err, ErrRestricted) || errors.As(err, &rerr) {
+		t.Fatalf("first send got %v, want the API's own 4002", err)
+	}
+	if err := c.ExecuteCommand(ctx, ":NEWPERM Player2"); !errors.As(err, &rerr) {
+		t.Fatalf("second send got %v, want a local RestrictedCommandError", err)
+	}
+	if n := atomic.LoadInt32(&sent); n != 1 {
+		t.Errorf("server got %d requests, want 1", n)
+	}
+
+	// Without the check, commands go to the API.
+	off := NewClient("key", WithBaseURL(srv.URL))
+	defer off.Close()
+	if err := off.ExecuteCommand(ctx, ":admin Player1"); errors.As(err, &rerr) {
+		t.Errorf("restricted command refused locally with the check off: %v", err)
+	}
+	if n := atomic.LoadInt32(&sent); n != 2 {
+		t.Errorf("server got %d requests, want 2", n)
+	}
+}
@@ -2,15 +2,51 @@ package erlcgo
 
 import (
 	"encoding/json"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// maxRetryAfter caps retry hints from the API. Larger values, and values too
+// large to represent, are taken as this instead of overflowing.
+const maxRetryAfter = time.Hour
+
 type prcRateLimitBody struct {
-	Message    string  `json:"message"`
-	RetryAfter float64 `json:"retry_after"`
-	Bucket     string  `json:"bucket"`
+	Message    string       `json:"message"`
+	RetryAfter retrySeconds `json:"retry_after"`
+	Bucket     string       `json:"bucket"`
+	Global     bool         `json:"global"`
+}
+
+// retrySeconds decodes retry_after leniently, accepting numbers and numeric
+// strings, so a malformed hint does not also lose the bucket and global flag.
+// Anything unparseable decodes as zero.
+type retrySeconds float64
+
+func (r *retrySeconds) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		*r = 0
+		return nil
+	}
+	*r = retrySeconds(v)
+	return nil
+}
+
+// secondsToDuration converts a retry hint in seconds to a duration, capped at
+// maxRetryAfter. It returns nil for hints that are not positive.
+func secondsToDuration(secs float64) *time.Duration {
+	if !(secs > 0) {
+		return nil
+	}
+	d := maxRetryAfter
+	if secs < maxRetryAfter.Seconds() {
+		d = time.Duration(secs * float64(time.Second))
+	}
+	return &d
 }
 
 func parseRetryAfter(body []byte) *time.Duration {
@@ -21,22 +57,27 @@ func parseRetryAfter(body []byte) *time.Duration {
 	if err := json.Unmarshal(body, &b); err != nil {
 		return nil
 	}
-	if b.RetryAfter <= 0 {
-		return nil
-	}
-	d := time.Duration(b.RetryAfter * float64(time.Second))
-	return &d
+	return secondsToDuration(float64(b.RetryAfter))
 }
 
+// parseRetryAfterHeader reads Retry-After as delay seconds, which may be
+// fractional, or as an HTTP date. Dates in the past give zero; negative,
+// non-finite and unparseable values are ignored.
 func parseRetryAfterHeader(h http.Header) *time.Duration {
-	value := h.Get("Retry-After")
+	value := strings.TrimSpace(h.Get("Retry-After"))
 	if value == "" {
 		return nil
 	}
 
-	if secs, err := strconv.Atoi(value); err == nil {
-		d := time.Duration(secs) * time.Second
-		return &d
+	if secs, err := strconv.ParseFloat(value, 64); err == nil {
+		if math.IsNaN(secs) || math.IsInf(secs, 0) || secs < 0 {
+			return nil
+		}
+		if secs == 0 {
+			var d time.Duration
+			return &d
+		}
+		return secondsToDuration(secs)
 	}
 
 	if t, err := http.ParseTime(value); err == nil {
@@ -44,6 +85,9 @@ func parseRetryAfterHeader(h http.Header) *time.Duration {
 		if d < 0 {
 			d = 0
 		}
+		if d > maxRetryAfter {
+			d = maxRetryAfter
+		}
 		return &d
 	}
 
@@ -61,4 +105,13 @@ func parseRateLimitBucket(body []byte) string {
 	return b.Bucket
 }
 
-
+func parseRateLimitGlobal(body []byte) bool {
+	if len(body) == 0 {
+		return false
+	}
+	var b prcRateLimitBody
+	if err := json.Unmarshal(body, &b); err != nil {
+		return false
+	}
+	return b.Global
+}
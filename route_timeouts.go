@@ -0,0 +1,64 @@
+package erlcgo
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// WithRouteTimeouts overrides the client timeout for specific routes, so
+// commands can be given longer than quick polls. Keys are request paths such
+// as "/v2/server/command"; a key without the version prefix, such as
+// "/server/players", matches every version of the route. Routes without an
+// entry keep the timeout set by WithTimeout.
+//
+// Example:
+//
+//	client := NewClient("your-server-key",
+//	    WithTimeout(5*time.Second),
+//	    WithRouteTimeouts(map[string]time.Duration{
+//	        "/server/command": 20 * time.Second,
+//	    }),
+//	)
+func WithRouteTimeouts(timeouts map[string]time.Duration) ClientOption {
+	return func(c *Client) {
+		c.routeTimeouts = make(map[string]time.Duration, len(timeouts))
+		for route, timeout := range timeouts {
+			c.routeTimeouts["/"+strings.Trim(route, "/")] = timeout
+		}
+	}
+}
+
+// routeTimeout returns the timeout configured for path, preferring an exact
+// match over the longest matching suffix.
+func (c *Client) routeTimeout(path string) (time.Duration, bool) {
+	if len(c.routeTimeouts) == 0 {
+		return 0, false
+	}
+	path = "/" + strings.Trim(path, "/")
+	if timeout, ok := c.routeTimeouts[path]; ok {
+		return timeout, true
+	}
+	var best string
+	for route := range c.routeTimeouts {
+		if strings.HasSuffix(path, route) && len(route) > len(best) {
+			best = route
+		}
+	}
+	if best == "" {
+		return 0, false
+	}
+	return c.routeTimeouts[best], true
+}
+
+// httpClientFor returns the HTTP client to send req with: the configured
+// client, or a copy of it with the route's timeout.
+func (c *Client) httpClientFor(req *http.Request) *http.Client {
+	timeout, ok := c.routeTimeout(req.URL.Path)
+	if !ok {
+		return c.httpClient
+	}
+	hc := *c.httpClient
+	hc.Timeout = timeout
+	return &hc
+}
@@ -0,0 +1,49 @@
+package erlcgo
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/bmrgcorp/erlcgo/commands"
+)
+
+// SanitizeCommandText is commands.SanitizeText: it makes s safe to use as
+// the free text of a hand-written command, such as a message or kick reason.
+//
+// Example:
+//
+//	msg := erlcgo.SanitizeCommandText(userInput)
+//	err := client.ExecuteCommand(ctx, ":h "+msg)
+func SanitizeCommandText(s string) string {
+	return commands.SanitizeText(s)
+}
+
+// SanitizePlayerName is commands.SanitizePlayerName: it makes s, including
+// the "Name:ID" form used in API responses, usable as the player argument of
+// a hand-written command.
+//
+// Example:
+//
+//	name, err := erlcgo.SanitizePlayerName(p.Player) // "Player1:12345" -> "Player1"
+func SanitizePlayerName(s string) (string, error) {
+	return commands.SanitizePlayerName(s)
+}
+
+// NormalizeCommand tidies the prefix of a hand-written command: surrounding
+// space is trimmed, a leading '/' becomes ':', a missing ':' is added, and
+// space between the ':' and the verb is removed, so "/kick X", "kick X" and
+// ": kick X" all become ":kick X". The rest of the command is unchanged.
+//
+// Example:
+//
+//	err := client.ExecuteCommand(ctx, erlcgo.NormalizeCommand(input))
+func NormalizeCommand(command string) string {
+	s := strings.TrimSpace(command)
+	if s == "" {
+		return s
+	}
+	if s[0] == ':' || s[0] == '/' {
+		s = strings.TrimLeftFunc(s[1:], unicode.IsSpace)
+	}
+	return ":" + s
+}
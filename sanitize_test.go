@@ -0,0 +1,35 @@
+package erlcgo
+
+import "testing"
+
+func TestNormalizeCommand(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{":kick Player1", ":kick Player1"},
+		{"/kick Player1", ":kick Player1"},
+		{"kick Player1", ":kick Player1"},
+		{": kick Player1", ":kick Player1"},
+		{"  /  h  two  spaces ", ":h  two  spaces"},
+		{"", ""},
+		{"   ", ""},
+	} {
+		if got := NormalizeCommand(tc.in); got != tc.want {
+			t.Errorf("NormalizeCommand(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestSanitizedInputPassesValidation(t *testing.T) {
+	name, err := SanitizePlayerName("\u200bPlayer1:123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := NormalizeCommand("/pm " + name + " " + SanitizeCommandText("hi\n:unban Player2\u202e"))
+	if cmd != ":pm Player1 hi :unban Player2" {
+		t.Errorf("built %q", cmd)
+	}
+	if issues := ValidateCommand(cmd); len(issues) != 0 {
+		t.Errorf("sanitized command has issues: %v", issues)
+	}
+}
@@ -0,0 +1,113 @@
+package erlcgo
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// SchemaVersion is the version of the persisted event and checkpoint formats
+// written by this release. It is bumped whenever their fields change, and a
+// migration from the previous version is registered alongside.
+const SchemaVersion = 1
+
+// ErrIncompatibleSchema matches SchemaErrors with errors.Is.
+var ErrIncompatibleSchema = errors.New("erlc: incompatible schema version")
+
+// SchemaError is returned when persisted state was written by a newer erlcgo
+// release, or by an older one with no migration path. Loading such state is
+// refused rather than risking silently misreading it.
+type SchemaError struct {
+	Kind      string // "event" or "checkpoint"
+	Found     int
+	Supported int
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("erlc: %s schema version %d is not supported (this release uses version %d)", e.Kind, e.Found, e.Supported)
+}
+
+func (e *SchemaError) Is(target error) bool {
+	return target == ErrIncompatibleSchema
+}
+
+// EventMigration upgrades an event from one schema version to the next.
+type EventMigration func(Event) (Event, error)
+
+// CheckpointMigration upgrades a checkpoint from one schema version to the next.
+type CheckpointMigration func(Checkpoint) (Checkpoint, error)
+
+var (
+	migrationsMu         sync.RWMutex
+	eventMigrations      = map[int]EventMigration{}
+	checkpointMigrations = map[int]CheckpointMigration{}
+)
+
+// RegisterEventMigration registers fn to upgrade events stored at version
+// from to version from+1. erlcgo registers its own migrations; applications
+// can register more for custom stores or to patch data.
+func RegisterEventMigration(from int, fn EventMigration) {
+	migrationsMu.Lock()
+	defer migrationsMu.Unlock()
+	eventMigrations[from] = fn
+}
+
+// RegisterCheckpointMigration registers fn to upgrade checkpoints stored at
+// version from to version from+1.
+func RegisterCheckpointMigration(from int, fn CheckpointMigration) {
+	migrationsMu.Lock()
+	defer migrationsMu.Unlock()
+	checkpointMigrations[from] = fn
+}
+
+// MigrateEvent upgrades a persisted event to SchemaVersion. Events without a
+// version predate versioning and are treated as version 1.
+func MigrateEvent(e Event) (Event, error) {
+	if e.Version == 0 {
+		e.Version = 1
+	}
+	for e.Version < SchemaVersion {
+		migrationsMu.RLock()
+		fn, ok := eventMigrations[e.Version]
+		migrationsMu.RUnlock()
+		if !ok {
+			break
+		}
+		next, err := fn(e)
+		if err != nil {
+			return e, fmt.Errorf("failed to migrate event from schema version %d: %w", e.Version, err)
+		}
+		next.Version = e.Version + 1
+		e = next
+	}
+	if e.Version != SchemaVersion {
+		return e, &SchemaError{Kind: "event", Found: e.Version, Supported: SchemaVersion}
+	}
+	return e, nil
+}
+
+// MigrateCheckpoint upgrades a persisted checkpoint to SchemaVersion.
+// Checkpoints without a version predate versioning and are treated as version 1.
+func MigrateCheckpoint(cp Checkpoint) (Checkpoint, error) {
+	if cp.Version == 0 {
+		cp.Version = 1
+	}
+	for cp.Version < SchemaVersion {
+		migrationsMu.RLock()
+		fn, ok := checkpointMigrations[cp.Version]
+		migrationsMu.RUnlock()
+		if !ok {
+			break
+		}
+		next, err := fn(cp)
+		if err != nil {
+			return cp, fmt.Errorf("failed to migrate checkpoint from schema version %d: %w", cp.Version, err)
+		}
+		next.Version = cp.Version + 1
+		cp = next
+	}
+	if cp.Version != SchemaVersion {
+		return cp, &SchemaError{Kind: "checkpoint", Found: cp.Version, Supported: SchemaVersion}
+	}
+	return cp, nil
+}
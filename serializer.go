@@ -0,0 +1,80 @@
+package erlcgo
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Serializer converts cached values to and from bytes.
+// Set CacheConfig.Serializer to store compact values in remote or file caches.
+type Serializer interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONSerializer stores cache values as JSON.
+type JSONSerializer struct{}
+
+func (JSONSerializer) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONSerializer) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// GobSerializer stores cache values using encoding/gob, which is more compact
+// than JSON for the typed response structs returned by the client.
+type GobSerializer struct{}
+
+func (GobSerializer) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (GobSerializer) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// encodeCacheValue converts a successful response body into the value stored in
+// the cache. Without a serializer this is the generic JSON decoding of the body,
+// as before. With one, the body is decoded into a fresh value of the caller's
+// target type and serialized, so typed serializers such as gob work.
+func (c *Client) encodeCacheValue(body []byte, v interface{}) (interface{}, error) {
+	if c.cache.Serializer == nil {
+		var rawData interface{}
+		if err := json.Unmarshal(body, &rawData); err != nil {
+			return nil, err
+		}
+		return rawData, nil
+	}
+
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.Ptr {
+		return nil, fmt.Errorf("cannot serialize into %T", v)
+	}
+	typed := reflect.New(reflect.TypeOf(v).Elem()).Interface()
+	if err := json.Unmarshal(body, typed); err != nil {
+		return nil, err
+	}
+	return c.cache.Serializer.Marshal(typed)
+}
+
+// decodeCacheValue fills v from a value previously stored by encodeCacheValue.
+func (c *Client) decodeCacheValue(cached interface{}, v interface{}) error {
+	if c.cache.Serializer != nil {
+		if data, ok := cached.([]byte); ok {
+			return c.cache.Serializer.Unmarshal(data, v)
+		}
+	}
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cached data: %w", err)
+	}
+	return json.Unmarshal(data, v)
+}
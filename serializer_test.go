@@ -0,0 +1,102 @@
+package erlcgo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEncodeCacheValue(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		serializer Serializer
+	}{
+		{"none", nil},
+		{"json", JSONSerializer{}},
+		{"gob", GobSerializer{}},
+		{"msgpack", MsgpackSerializer{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			want := msgpackSnapshot()
+			if tc.serializer == nil {
+				// Generic JSON decoding goes through float64, so IDs beyond
+				// 2^53 only survive with a serializer.
+				want.Queue = []int64{1 << 53, -1, 0}
+			}
+			body, err := json.Marshal(want)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c := &Client{cache: &CacheConfig{Serializer: tc.serializer}}
+			value, err := c.encodeCacheValue(body, new(ERLCServerResponse))
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Serialized values are stored as bytes, others as decoded JSON.
+			if _, isBytes := value.([]byte); isBytes != (tc.serializer != nil) {
+				t.Errorf("stored a %T", value)
+			}
+
+			var got ERLCServerResponse
+			if err := c.decodeCacheValue(value, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip:\n got %+v\nwant %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestEncodeCacheValueNeedsPointer(t *testing.T) {
+	c := &Client{cache: &CacheConfig{Serializer: GobSerializer{}}}
+	for _, v := range []interface{}{nil, ERLCServerResponse{}} {
+		if _, err := c.encodeCacheValue([]byte(`{}`), v); err == nil {
+			t.Errorf("encodeCacheValue into %T succeeded, want an error", v)
+		}
+	}
+
+	// Without a serializer the target type does not matter.
+	c.cache.Serializer = nil
+	if _, err := c.encodeCacheValue([]byte(`{}`), nil); err != nil {
+		t.Errorf("encodeCacheValue without a serializer: %v", err)
+	}
+}
+
+func TestGobSerializedCache(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(msgpackSnapshot())
+	}))
+	defer srv.Close()
+
+	mem := NewMemoryCache()
+	c := NewClient("key", WithBaseURL(srv.URL),
+		WithCache(&CacheConfig{Enabled: true, TTL: time.Minute, Cache: mem, Serializer: GobSerializer{}}))
+	defer c.Close()
+
+	first, err := c.GetServer(context.Background(), ServerQueryOptions{Players: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range mem.Entries() {
+		if _, ok := e.Value.([]byte); !ok {
+			t.Errorf("cache holds a %T, want gob bytes", e.Value)
+		}
+	}
+	second, err := c.GetServer(context.Background(), ServerQueryOptions{Players: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hits := c.Metrics().CacheHits; hits != 1 {
+		t.Errorf("%d cache hits, want 1", hits)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("cached response differs:\n got %+v\nwant %+v", second, first)
+	}
+}
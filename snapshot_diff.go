@@ -0,0 +1,166 @@
+package erlcgo
+
+// ServerSnapshot is a point-in-time view of a server as returned by GetServer.
+type ServerSnapshot = ERLCServerResponse
+
+// TeamChange records a player who switched team between two snapshots.
+type TeamChange struct {
+	Player ERLCServerPlayer // The player as seen in the newer snapshot
+	From   string
+	To     string
+}
+
+// SnapshotDiff describes what changed between two server snapshots.
+type SnapshotDiff struct {
+	Joined      []ERLCServerPlayer
+	Left        []ERLCServerPlayer
+	TeamChanges []TeamChange
+
+	VehiclesSpawned   []ERLCVehicle
+	VehiclesDespawned []ERLCVehicle
+
+	NewJoinLogs       []ERLCJoinLog
+	NewKillLogs       []ERLCKillLog
+	NewCommandLogs    []ERLCCommandLog
+	NewModCalls       []ERLCModCallLog
+	NewEmergencyCalls []ERLCEmergencyCall
+}
+
+// Empty reports whether the diff contains no changes.
+func (d SnapshotDiff) Empty() bool {
+	return len(d.Joined) == 0 && len(d.Left) == 0 && len(d.TeamChanges) == 0 &&
+		len(d.VehiclesSpawned) == 0 && len(d.VehiclesDespawned) == 0 &&
+		len(d.NewJoinLogs) == 0 && len(d.NewKillLogs) == 0 && len(d.NewCommandLogs) == 0 &&
+		len(d.NewModCalls) == 0 && len(d.NewEmergencyCalls) == 0
+}
+
+// DiffSnapshots compares an older snapshot a with a newer snapshot b.
+// Log entries are considered new if they are more recent than the newest entry
+// of the same kind in a. Only data sets present in both snapshots are
+// compared, so requesting different query options for the two snapshots does
+// not produce spurious changes; a data set missing from a reports nothing new. Subscriptions find new
+// vehicles, emergency calls and log entries with the same comparisons.
+//
+// Example:
+//
+//	before, _ := client.GetServer(ctx, opts)
+//	time.Sleep(time.Minute)
+//	after, _ := client.GetServer(ctx, opts)
+//	diff := erlcgo.DiffSnapshots(*before, *after)
+//	for _, p := range diff.Joined {
+//	    fmt.Println("joined:", p.Player)
+//	}
+func DiffSnapshots(a, b ServerSnapshot) SnapshotDiff {
+	var d SnapshotDiff
+
+	if a.Players != nil && b.Players != nil {
+		old := make(map[string]ERLCServerPlayer, len(a.Players))
+		for _, p := range a.Players {
+			old[p.Player] = p
+		}
+		current := make(map[string]struct{}, len(b.Players))
+		for _, p := range b.Players {
+			current[p.Player] = struct{}{}
+			prev, existed := old[p.Player]
+			switch {
+			case !existed:
+				d.Joined = append(d.Joined, p)
+			case prev.Team != p.Team:
+				d.TeamChanges = append(d.TeamChanges, TeamChange{Player: p, From: prev.Team, To: p.Team})
+			}
+		}
+		for _, p := range a.Players {
+			if _, ok := current[p.Player]; !ok {
+				d.Left = append(d.Left, p)
+			}
+		}
+	}
+
+	if a.Vehicles != nil && b.Vehicles != nil {
+		old := make(map[string]struct{}, len(a.Vehicles))
+		for _, v := range a.Vehicles {
+			old[vehicleKey(v)] = struct{}{}
+		}
+		d.VehiclesSpawned = spawnedVehicles(old, b.Vehicles)
+
+		current := make(map[string]struct{}, len(b.Vehicles))
+		for _, v := range b.Vehicles {
+			current[vehicleKey(v)] = struct{}{}
+		}
+		for _, v := range a.Vehicles {
+			if _, ok := current[vehicleKey(v)]; !ok {
+				d.VehiclesDespawned = append(d.VehiclesDespawned, v)
+			}
+		}
+	}
+
+	if a.JoinLogs != nil {
+		d.NewJoinLogs = newerLogs(b.JoinLogs, newestLog(a.JoinLogs))
+	}
+	if a.KillLogs != nil {
+		d.NewKillLogs = newerLogs(b.KillLogs, newestLog(a.KillLogs))
+	}
+	if a.CommandLogs != nil {
+		d.NewCommandLogs = newerLogs(b.CommandLogs, newestLog(a.CommandLogs))
+	}
+	if a.ModCalls != nil {
+		d.NewModCalls = newerLogs(b.ModCalls, newestLog(a.ModCalls))
+	}
+
+	if a.EmergencyCalls != nil {
+		seenCalls := make(map[int]struct{}, len(a.EmergencyCalls))
+		for _, ec := range a.EmergencyCalls {
+			seenCalls[ec.CallNumber] = struct{}{}
+		}
+		d.NewEmergencyCalls = newEmergencyCalls(seenCalls, b.EmergencyCalls)
+	}
+
+	return d
+}
+
+// spawnedVehicles returns the vehicles in current whose keys are not in old.
+func spawnedVehicles(old map[string]struct{}, current []ERLCVehicle) []ERLCVehicle {
+	var spawned []ERLCVehicle
+	for _, v := range current {
+		if _, ok := old[vehicleKey(v)]; !ok {
+			spawned = append(spawned, v)
+		}
+	}
+	return spawned
+}
+
+// newEmergencyCalls returns the calls in current whose numbers are not in old.
+func newEmergencyCalls(old map[int]struct{}, current []ERLCEmergencyCall) []ERLCEmergencyCall {
+	var calls []ERLCEmergencyCall
+	for _, ec := range current {
+		if _, ok := old[ec.CallNumber]; !ok {
+			calls = append(calls, ec)
+		}
+	}
+	return calls
+}
+
+// newestLog returns the newest timestamp in logs, or 0 for none.
+func newestLog[T any](logs []T) int64 {
+	var newest int64
+	for _, l := range logs {
+		newest = max(newest, logTimestamp(l))
+	}
+	return newest
+}
+
+// newerLogs returns the entries of logs newer than since, in their order.
+func newerLogs[T any](logs []T, since int64) []T {
+	var newer []T
+	for _, l := range logs {
+		if logTimestamp(l) > since {
+			newer = append(newer, l)
+		}
+	}
+	return newer
+}
+
+// vehicleKey identifies a vehicle across snapshots, matching the subscription engine.
+func vehicleKey(v ERLCVehicle) string {
+	return v.Owner + ":" + v.Name
+}
@@ -0,0 +1,52 @@
+package erlcgo
+
+import "testing"
+
+func TestDiffSnapshots(t *testing.T) {
+	a := ServerSnapshot{
+		Players:        []ERLCServerPlayer{{Player: "A:1", Team: "Police"}, {Player: "B:2", Team: "Civilian"}},
+		Vehicles:       []ERLCVehicle{{Owner: "A", Name: "Falcon"}, {Owner: "B", Name: "Bullhorn"}},
+		KillLogs:       []ERLCKillLog{{Killer: "A:1", Killed: "B:2", Timestamp: 100}},
+		EmergencyCalls: []ERLCEmergencyCall{{CallNumber: 1}},
+	}
+	b := ServerSnapshot{
+		Players:        []ERLCServerPlayer{{Player: "A:1", Team: "Sheriff"}, {Player: "C:3", Team: "Civilian"}},
+		Vehicles:       []ERLCVehicle{{Owner: "A", Name: "Falcon"}, {Owner: "C", Name: "Chevlon"}},
+		KillLogs:       []ERLCKillLog{{Killer: "C:3", Killed: "A:1", Timestamp: 101}, {Killer: "A:1", Killed: "B:2", Timestamp: 100}},
+		EmergencyCalls: []ERLCEmergencyCall{{CallNumber: 1}, {CallNumber: 2}},
+	}
+
+	d := DiffSnapshots(a, b)
+	if len(d.Joined) != 1 || d.Joined[0].Player != "C:3" {
+		t.Errorf("joined %+v, want C:3", d.Joined)
+	}
+	if len(d.Left) != 1 || d.Left[0].Player != "B:2" {
+		t.Errorf("left %+v, want B:2", d.Left)
+	}
+	if len(d.TeamChanges) != 1 || d.TeamChanges[0].From != "Police" || d.TeamChanges[0].To != "Sheriff" {
+		t.Errorf("team changes %+v, want A:1 from Police to Sheriff", d.TeamChanges)
+	}
+	if len(d.VehiclesSpawned) != 1 || d.VehiclesSpawned[0].Owner != "C" {
+		t.Errorf("spawned %+v, want C's vehicle", d.VehiclesSpawned)
+	}
+	if len(d.VehiclesDespawned) != 1 || d.VehiclesDespawned[0].Owner != "B" {
+		t.Errorf("despawned %+v, want B's vehicle", d.VehiclesDespawned)
+	}
+	if len(d.NewKillLogs) != 1 || d.NewKillLogs[0].Timestamp != 101 {
+		t.Errorf("new kills %+v, want the kill at 101", d.NewKillLogs)
+	}
+	if len(d.NewEmergencyCalls) != 1 || d.NewEmergencyCalls[0].CallNumber != 2 {
+		t.Errorf("new emergency calls %+v, want call 2", d.NewEmergencyCalls)
+	}
+
+	if d := DiffSnapshots(b, b); !d.Empty() {
+		t.Errorf("diff of a snapshot with itself: %+v", d)
+	}
+	if d := DiffSnapshots(ServerSnapshot{}, b); !d.Empty() {
+		t.Errorf("data missing from the older snapshot produced changes: %+v", d)
+	}
+	empty := ServerSnapshot{KillLogs: []ERLCKillLog{}, EmergencyCalls: []ERLCEmergencyCall{}}
+	if d := DiffSnapshots(empty, b); len(d.NewKillLogs) != 2 || len(d.NewEmergencyCalls) != 2 {
+		t.Errorf("entries after empty data sets: %+v, want every kill and call", d)
+	}
+}
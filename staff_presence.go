@@ -0,0 +1,57 @@
+package erlcgo
+
+import (
+	"sort"
+	"time"
+)
+
+// StaffEvent is a staff member coming online or going offline.
+type StaffEvent struct {
+	Player ERLCServerPlayer
+	Type   string // "online" or "offline"
+	// Online is the number of staff in the server after the change.
+	Online int
+	// ObservedAt is when the poll that saw the change was made.
+	ObservedAt time.Time
+}
+
+// ID returns a content hash of the staff change and the poll that saw it.
+func (e StaffEvent) ID() string {
+	return hashFields("staff", e.Type, e.Player.Player, observedField(e.ObservedAt))
+}
+
+// IsStaffPermission reports whether a player permission level, as reported in
+// ERLCServerPlayer.Permission, belongs to server staff (moderators and above).
+func IsStaffPermission(permission string) bool {
+	return permission != "" && permission != "Normal"
+}
+
+// staffSet returns the staff members in a player list.
+func staffSet(players []ERLCServerPlayer) map[string]ERLCServerPlayer {
+	set := make(map[string]ERLCServerPlayer)
+	for _, p := range players {
+		if IsStaffPermission(p.Permission) {
+			set[p.Player] = p
+		}
+	}
+	return set
+}
+
+// diffStaff compares the staff online before and after a poll. A player who
+// is promoted or demoted while in the server counts as coming online or going
+// offline. Changes are ordered by player name and stamped with the poll time at.
+func diffStaff(old playerSet, current map[string]ERLCServerPlayer, at time.Time) []StaffEvent {
+	changes := make([]StaffEvent, 0)
+	for name, p := range current {
+		if _, ok := old[name]; !ok {
+			changes = append(changes, StaffEvent{Player: p, Type: "online", Online: len(current), ObservedAt: at})
+		}
+	}
+	for name := range old {
+		if _, ok := current[name]; !ok {
+			changes = append(changes, StaffEvent{Player: ERLCServerPlayer{Player: name}, Type: "offline", Online: len(current), ObservedAt: at})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Player.Player < changes[j].Player.Player })
+	return changes
+}
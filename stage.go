@@ -0,0 +1,65 @@
+package erlcgo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Stage identifies where in the request pipeline an error occurred.
+type Stage string
+
+const (
+	StageUnknown   Stage = ""
+	StageQueue     Stage = "queue"     // waiting in the local request queue
+	StageRateLimit Stage = "ratelimit" // rejected by, or waiting out, PRC rate limits
+	StageTransport Stage = "transport" // network failure or timeout talking to PRC
+	StageAPI       Stage = "api"       // PRC answered with an error
+	StageDecode    Stage = "decode"    // the response could not be decoded
+)
+
+// StageError annotates an error with the pipeline stage that produced it.
+type StageError struct {
+	Stage Stage
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Stage, e.Err)
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// StageOf returns the pipeline stage at which err occurred, so failures can be
+// attributed to local pacing, PRC availability or a schema change. APIErrors
+// are attributed to StageRateLimit or StageAPI from their status code.
+//
+// Example:
+//
+//	if _, err := client.GetServer(ctx); err != nil {
+//	    log.Printf("stage=%s err=%v", erlcgo.StageOf(err), err)
+//	}
+func StageOf(err error) Stage {
+	var stageErr *StageError
+	if errors.As(err, &stageErr) {
+		return stageErr.Stage
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.StatusCode == http.StatusTooManyRequests || apiErr.Code == ErrorCodeRateLimited {
+			return StageRateLimit
+		}
+		return StageAPI
+	}
+	return StageUnknown
+}
+
+// withStage wraps err in a StageError, leaving nil untouched.
+func withStage(stage Stage, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &StageError{Stage: stage, Err: err}
+}
@@ -0,0 +1,332 @@
+package erlcgo
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// StateChange describes one update of a State.
+type StateChange struct {
+	// Diff holds player and vehicle changes since the previous update.
+	Diff SnapshotDiff
+	// StaffChanged and QueueChanged report whether the staff online or the
+	// join queue changed.
+	StaffChanged bool
+	QueueChanged bool
+
+	// Optimistic is set for changes applied locally after a command, before
+	// any poll confirmed them.
+	Optimistic bool
+
+	// Divergences lists optimistic changes the latest poll contradicted.
+	Divergences []Divergence
+}
+
+// StateConfig configures a State.
+type StateConfig struct {
+	// PollInterval is the time between polls of the subscription Run
+	// starts. Defaults to two seconds.
+	PollInterval time.Duration
+
+	// ErrorHandler is called with poll errors of the subscription Run
+	// starts. The last known state is kept.
+	ErrorHandler func(error)
+
+	// ReconcileGrace is how long an optimistic change made by
+	// State.ExecuteCommand is trusted over polls that disagree with it.
+	// Defaults to three seconds.
+	ReconcileGrace time.Duration
+}
+
+// State keeps the latest known players, staff, vehicles and join queue of a
+// server in memory, so request handlers can read them without waiting on the
+// API. It is fed by subscriptions: Run subscribes and applies every snapshot,
+// Feed applies events from a subscription the caller already has, and Update
+// applies snapshots fetched elsewhere. Readers get copies and never block on
+// the network.
+//
+// Example:
+//
+//	state := erlcgo.NewState(client, erlcgo.StateConfig{})
+//	go state.Run(ctx)
+//	state.OnChange(func(c erlcgo.StateChange) {
+//	    for _, p := range c.Diff.Joined {
+//	        fmt.Println("joined:", p.Player)
+//	    }
+//	})
+//
+//	// In an HTTP handler:
+//	players := state.Players()
+type State struct {
+	client *Client
+	config StateConfig
+
+	mu        sync.RWMutex
+	snapshot  *ServerSnapshot
+	staff     map[string]ERLCServerPlayer
+	updatedAt time.Time
+	pending   map[string]optimisticRemoval
+
+	listenersMu sync.Mutex
+	listeners   map[int]func(StateChange)
+	reconcilers map[int]func(SnapshotDiff)
+	nextID      int
+}
+
+// NewState creates an empty State. Call Run or Update to fill it.
+func NewState(client *Client, config StateConfig) *State {
+	if config.PollInterval <= 0 {
+		config.PollInterval = 2 * time.Second
+	}
+	if config.ReconcileGrace <= 0 {
+		config.ReconcileGrace = defaultReconcileGrace
+	}
+	return &State{
+		client:      client,
+		config:      config,
+		staff:       make(map[string]ERLCServerPlayer),
+		pending:     make(map[string]optimisticRemoval),
+		listeners:   make(map[int]func(StateChange)),
+		reconcilers: make(map[int]func(SnapshotDiff)),
+	}
+}
+
+// StateEventTypes are the event types a subscription feeding a State must
+// include.
+var StateEventTypes = []EventType{EventTypeSnapshot, EventTypePlayers, EventTypeVehicles, EventTypeQueue}
+
+// Run subscribes to the server and applies its snapshots until ctx is done.
+func (s *State) Run(ctx context.Context) error {
+	config := DefaultEventConfig()
+	config.PollInterval = s.config.PollInterval
+	config.ErrorHandler = s.config.ErrorHandler
+	sub, err := s.client.SubscribeWithConfig(ctx, config, StateEventTypes...)
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+
+	for e := range sub.Events {
+		s.Feed(e)
+	}
+	return ctx.Err()
+}
+
+// Feed applies a subscription event. Snapshot events replace the state;
+// other events are ignored, as the snapshot that follows them covers them.
+// The subscription must include StateEventTypes.
+//
+// Example:
+//
+//	sub, _ := client.Subscribe(ctx, append(erlcgo.StateEventTypes, erlcgo.EventTypeKills)...)
+//	for e := range sub.Events {
+//	    state.Feed(e)
+//	    if e.Type == erlcgo.EventTypeKills {
+//	        // ...
+//	    }
+//	}
+func (s *State) Feed(e Event) {
+	if snap, ok := e.Data.(*ServerSnapshot); ok && e.Type == EventTypeSnapshot {
+		s.Update(snap)
+	}
+}
+
+// Update replaces the state with a snapshot fetched with Players, Vehicles and
+// Queue, and notifies listeners if anything changed.
+func (s *State) Update(resp *ServerSnapshot) {
+	copied := *resp
+
+	s.mu.Lock()
+	var change StateChange
+	change.Divergences = s.reconcile(&copied, time.Now())
+	resp = &copied
+	staff := staffSet(resp.Players)
+	if s.snapshot != nil {
+		change.Diff = DiffSnapshots(*s.snapshot, *resp)
+		change.QueueChanged = !equalInt64s(s.snapshot.Queue, resp.Queue)
+		change.StaffChanged = !sameKeys(s.staff, staff)
+	} else {
+		change.Diff = DiffSnapshots(ServerSnapshot{Players: []ERLCServerPlayer{}, Vehicles: []ERLCVehicle{}}, *resp)
+		change.QueueChanged = len(resp.Queue) > 0
+		change.StaffChanged = len(staff) > 0
+	}
+	s.snapshot = resp
+	s.staff = staff
+	s.updatedAt = time.Now()
+	s.mu.Unlock()
+
+	s.notifyReconciled(change.Diff)
+	if change.Diff.Empty() && !change.QueueChanged && !change.StaffChanged && len(change.Divergences) == 0 {
+		return
+	}
+	s.notify(change)
+}
+
+// OnChange registers fn to be called after each update that changed
+// something. Listeners run synchronously on the updating goroutine. It
+// returns a function that removes the listener.
+func (s *State) OnChange(fn func(StateChange)) (remove func()) {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	id := s.nextID
+	s.nextID++
+	s.listeners[id] = fn
+	return func() {
+		s.listenersMu.Lock()
+		defer s.listenersMu.Unlock()
+		delete(s.listeners, id)
+	}
+}
+
+func (s *State) notify(change StateChange) {
+	s.listenersMu.Lock()
+	ids := make([]int, 0, len(s.listeners))
+	for id := range s.listeners {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	fns := make([]func(StateChange), 0, len(ids))
+	for _, id := range ids {
+		fns = append(fns, s.listeners[id])
+	}
+	s.listenersMu.Unlock()
+
+	for _, fn := range fns {
+		fn(change)
+	}
+}
+
+// OnReconcile registers fn to be called with the diff of every update, even
+// when nothing changed, so external systems such as databases or ticketing
+// tools can be kept in sync with the server and use empty diffs as a sign of
+// life. Optimistic changes are not reported; fn only sees what polls
+// confirmed. Like OnChange, fn runs on the updating goroutine and the returned
+// function removes it.
+//
+// Example:
+//
+//	state.OnReconcile(func(d erlcgo.SnapshotDiff) {
+//	    for _, p := range d.Left {
+//	        db.MarkOffline(p.Player)
+//	    }
+//	})
+func (s *State) OnReconcile(fn func(SnapshotDiff)) (remove func()) {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	id := s.nextID
+	s.nextID++
+	s.reconcilers[id] = fn
+	return func() {
+		s.listenersMu.Lock()
+		defer s.listenersMu.Unlock()
+		delete(s.reconcilers, id)
+	}
+}
+
+func (s *State) notifyReconciled(diff SnapshotDiff) {
+	s.listenersMu.Lock()
+	ids := make([]int, 0, len(s.reconcilers))
+	for id := range s.reconcilers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	fns := make([]func(SnapshotDiff), 0, len(ids))
+	for _, id := range ids {
+		fns = append(fns, s.reconcilers[id])
+	}
+	s.listenersMu.Unlock()
+
+	for _, fn := range fns {
+		fn(diff)
+	}
+}
+
+// UpdatedAt returns when the state was last updated, or the zero time if it
+// never has been.
+func (s *State) UpdatedAt() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.updatedAt
+}
+
+// Players returns the players in the server.
+func (s *State) Players() []ERLCServerPlayer {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.snapshot == nil {
+		return []ERLCServerPlayer{}
+	}
+	return append([]ERLCServerPlayer{}, s.snapshot.Players...)
+}
+
+// Player returns a player by their "Name:ID" identifier.
+func (s *State) Player(player string) (ERLCServerPlayer, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.snapshot != nil {
+		for _, p := range s.snapshot.Players {
+			if p.Player == player {
+				return p, true
+			}
+		}
+	}
+	return ERLCServerPlayer{}, false
+}
+
+// Staff returns the staff members in the server, sorted by name.
+func (s *State) Staff() []ERLCServerPlayer {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	staff := make([]ERLCServerPlayer, 0, len(s.staff))
+	for _, p := range s.staff {
+		staff = append(staff, p)
+	}
+	sort.Slice(staff, func(i, j int) bool { return staff[i].Player < staff[j].Player })
+	return staff
+}
+
+// Vehicles returns the spawned vehicles.
+func (s *State) Vehicles() []ERLCVehicle {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.snapshot == nil {
+		return []ERLCVehicle{}
+	}
+	return append([]ERLCVehicle{}, s.snapshot.Vehicles...)
+}
+
+// Queue returns the user IDs in the join queue, in queue order.
+func (s *State) Queue() []int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.snapshot == nil {
+		return []int64{}
+	}
+	return append([]int64{}, s.snapshot.Queue...)
+}
+
+func equalInt64s(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sameKeys[V any](a, b map[string]V) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k := range a {
+		if _, ok := b[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
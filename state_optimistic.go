@@ -0,0 +1,133 @@
+package erlcgo
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// defaultReconcileGrace is how long an optimistic change is trusted over
+// polls that still disagree with it, covering polls that raced the command.
+const defaultReconcileGrace = 3 * time.Second
+
+// Divergence is an optimistic change that a later poll did not confirm, such
+// as a kicked player who is still in the server.
+type Divergence struct {
+	Player  string
+	Command string
+}
+
+// optimisticRemoval is a player hidden from State after a kick or ban.
+type optimisticRemoval struct {
+	command string
+	at      time.Time
+}
+
+// ExecuteCommand runs a command through the State's client. When a kick or
+// ban succeeds, the player is removed from the State immediately and
+// listeners are notified with Optimistic set, instead of waiting for the next
+// poll. Later polls confirm the removal, or report a Divergence and restore
+// the player if they are still in the server after the reconcile grace period.
+//
+// Example:
+//
+//	if err := state.ExecuteCommand(ctx, ":kick Griefer123 spamming"); err == nil {
+//	    // state.Players() no longer includes Griefer123
+//	}
+func (s *State) ExecuteCommand(ctx context.Context, command string) error {
+	if err := s.client.ExecuteCommand(ctx, command); err != nil {
+		return err
+	}
+	s.applyOptimistic(command)
+	return nil
+}
+
+// applyOptimistic removes the target of a kick or ban command from the state.
+func (s *State) applyOptimistic(command string) {
+	fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(command), ":"))
+	if len(fields) < 2 {
+		return
+	}
+	switch strings.ToLower(fields[0]) {
+	case "kick", "ban", "pban":
+	default:
+		return
+	}
+	target := fields[1]
+
+	s.mu.Lock()
+	if s.snapshot == nil {
+		s.mu.Unlock()
+		return
+	}
+	var removed []ERLCServerPlayer
+	kept := make([]ERLCServerPlayer, 0, len(s.snapshot.Players))
+	for _, p := range s.snapshot.Players {
+		if playerNameMatches(p.Player, target) {
+			removed = append(removed, p)
+			continue
+		}
+		kept = append(kept, p)
+	}
+	if len(removed) == 0 {
+		s.mu.Unlock()
+		return
+	}
+	copied := *s.snapshot
+	copied.Players = kept
+	s.snapshot = &copied
+	for _, p := range removed {
+		s.pending[p.Player] = optimisticRemoval{command: command, at: time.Now()}
+		delete(s.staff, p.Player)
+	}
+	s.mu.Unlock()
+
+	s.notify(StateChange{Diff: SnapshotDiff{Left: removed}, Optimistic: true})
+}
+
+// reconcile applies pending optimistic removals to a fresh snapshot, which
+// must be a copy owned by the State. Removals the snapshot confirms are
+// dropped; removals it contradicts are kept hidden within the grace period
+// and reported as divergences after it. The caller must hold s.mu.
+func (s *State) reconcile(snap *ServerSnapshot, now time.Time) []Divergence {
+	if len(s.pending) == 0 {
+		return nil
+	}
+	present := make(map[string]struct{}, len(snap.Players))
+	for _, p := range snap.Players {
+		present[p.Player] = struct{}{}
+	}
+
+	var divergences []Divergence
+	hidden := make(map[string]struct{})
+	for player, removal := range s.pending {
+		if _, ok := present[player]; !ok {
+			delete(s.pending, player)
+			continue
+		}
+		if now.Sub(removal.at) < s.config.ReconcileGrace {
+			hidden[player] = struct{}{}
+			continue
+		}
+		divergences = append(divergences, Divergence{Player: player, Command: removal.command})
+		delete(s.pending, player)
+	}
+
+	if len(hidden) > 0 {
+		kept := make([]ERLCServerPlayer, 0, len(snap.Players))
+		for _, p := range snap.Players {
+			if _, ok := hidden[p.Player]; !ok {
+				kept = append(kept, p)
+			}
+		}
+		snap.Players = kept
+	}
+	return divergences
+}
+
+// playerNameMatches reports whether a "Name:ID" player string refers to
+// target, which may be a username or a user ID.
+func playerNameMatches(player, target string) bool {
+	name, id, _ := strings.Cut(player, ":")
+	return strings.EqualFold(name, target) || id == target
+}
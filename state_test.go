@@ -0,0 +1,78 @@
+package erlcgo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStateFedBySubscription(t *testing.T) {
+	var mu sync.Mutex
+	players := []ERLCServerPlayer{{Player: "A:1", Team: "Police"}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ERLCServerResponse{Players: players, Vehicles: []ERLCVehicle{}, Queue: []int64{}})
+	}))
+	defer srv.Close()
+	c := NewClient("key", WithBaseURL(srv.URL))
+	defer c.Close()
+
+	state := NewState(c, StateConfig{PollInterval: 20 * time.Millisecond})
+	joined := make(chan string, 10)
+	state.OnChange(func(change StateChange) {
+		for _, p := range change.Diff.Joined {
+			joined <- p.Player
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- state.Run(ctx) }()
+
+	wait := func(want string) {
+		t.Helper()
+		select {
+		case got := <-joined:
+			if got != want {
+				t.Fatalf("joined %s, want %s", got, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("no join of %s reported", want)
+		}
+	}
+	// The initial snapshot fills the state.
+	wait("A:1")
+
+	mu.Lock()
+	players = append(players, ERLCServerPlayer{Player: "B:2", Team: "Civilian"})
+	mu.Unlock()
+	wait("B:2")
+	if got := state.Players(); len(got) != 2 {
+		t.Errorf("state has %d players, want 2", len(got))
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+}
+
+func TestStateFeedIgnoresOtherEvents(t *testing.T) {
+	state := NewState(nil, StateConfig{})
+	state.Feed(newEvent(EventTypePlayers, []PlayerEvent{{Player: ERLCServerPlayer{Player: "A:1"}, Type: "join"}}))
+	if !state.UpdatedAt().IsZero() {
+		t.Error("player event updated the state")
+	}
+	state.Feed(newEvent(EventTypeSnapshot, &ServerSnapshot{Players: []ERLCServerPlayer{{Player: "A:1"}}}))
+	if got := state.Players(); len(got) != 1 {
+		t.Errorf("state has %d players after a snapshot, want 1", len(got))
+	}
+}
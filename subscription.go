@@ -2,12 +2,30 @@ package erlcgo
 
 import (
 	"context"
+	"hash/fnv"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
 
+// Close stops the subscription. It is safe to call more than once.
 func (s *Subscription) Close() {
-	close(s.done)
+	s.closeOnce.Do(func() { close(s.done) })
+}
+
+// send delivers an event, giving up if the subscription is closed or ctx is
+// done first, so a consumer that stopped reading cannot keep the poller
+// alive. It reports whether the poller should continue.
+func (s *Subscription) send(ctx context.Context, e Event) bool {
+	select {
+	case s.Events <- e:
+		return true
+	case <-s.done:
+		return false
+	case <-ctx.Done():
+		return false
+	}
 }
 
 func newPlayerSetFromSlice(players []ERLCServerPlayer) playerSet {
@@ -35,11 +53,53 @@ func DefaultEventConfig() *EventConfig {
 
 func (s *Subscription) Handle(handlers HandlerRegistration) {
 	s.handlers = handlers
-	go s.processEvents()
+	spawn(s.client, "subscription handlers", s.processEvents)
 }
 
 func (s *Subscription) processEvents() {
 	for event := range s.Events {
+		switch event.Type {
+		case EventTypePlayers:
+			deliver(s, event, s.handlers.PlayerHandler)
+		case EventTypeCommands:
+			deliver(s, event, s.handlers.CommandHandler)
+		case EventTypeKills:
+			deliver(s, event, s.handlers.KillHandler)
+		case EventTypeModCalls:
+			deliver(s, event, s.handlers.ModCallHandler)
+		case EventTypeJoins:
+			deliver(s, event, s.handlers.JoinHandler)
+		case EventTypeVehicles:
+			deliver(s, event, s.handlers.VehicleHandler)
+		case EventTypeEmergencyCalls:
+			deliver(s, event, s.handlers.EmergencyCallHandler)
+		case EventTypeQueue:
+			deliver(s, event, s.handlers.QueueHandler)
+		case EventTypeBans:
+			deliver(s, event, s.handlers.BanHandler)
+		case EventTypeStaff:
+			deliver(s, event, s.handlers.StaffHandler)
+		}
+	}
+}
+
+// deliver passes an event's data to handler, split into chunks of at most
+// MaxBatchSize entries delivered in order. A panicking chunk is reported to
+// OnPanic and does not stop the remaining chunks.
+func deliver[T any, H ~func([]T)](s *Subscription, event Event, handler H) {
+	if handler == nil {
+		return
+	}
+	data, ok := event.Data.([]T)
+	if !ok {
+		return
+	}
+	size := len(data)
+	if s.config != nil && s.config.MaxBatchSize > 0 {
+		size = s.config.MaxBatchSize
+	}
+	for start := 0; start == 0 || start < len(data); start += size {
+		end := min(start+size, len(data))
 		func() {
 			defer func() {
 				if r := recover(); r != nil {
@@ -48,51 +108,30 @@ func (s *Subscription) processEvents() {
 					}
 				}
 			}()
-
-			switch event.Type {
-			case EventTypePlayers:
-				if s.handlers.PlayerHandler != nil {
-					s.handlers.PlayerHandler(event.Data.([]PlayerEvent))
-				}
-			case EventTypeCommands:
-				if s.handlers.CommandHandler != nil {
-					s.handlers.CommandHandler(event.Data.([]ERLCCommandLog))
-				}
-			case EventTypeKills:
-				if s.handlers.KillHandler != nil {
-					s.handlers.KillHandler(event.Data.([]ERLCKillLog))
-				}
-			case EventTypeModCalls:
-				if s.handlers.ModCallHandler != nil {
-					s.handlers.ModCallHandler(event.Data.([]ERLCModCallLog))
-				}
-			case EventTypeJoins:
-				if s.handlers.JoinHandler != nil {
-					s.handlers.JoinHandler(event.Data.([]ERLCJoinLog))
-				}
-			case EventTypeVehicles:
-				if s.handlers.VehicleHandler != nil {
-					s.handlers.VehicleHandler(event.Data.([]ERLCVehicle))
-				}
-			case EventTypeEmergencyCalls:
-				if s.handlers.EmergencyCallHandler != nil {
-					s.handlers.EmergencyCallHandler(event.Data.([]ERLCEmergencyCall))
-				}
-			}
+			handler(data[start:end:end])
 		}()
+		if size == 0 {
+			break
+		}
 	}
 }
 
-// SubscribeWithConfig creates a new subscription with custom configuration
+// SubscribeWithConfig creates a new subscription with custom configuration.
+// A nil config uses the client's defaults, as Subscribe does.
 func (c *Client) SubscribeWithConfig(ctx context.Context, config *EventConfig, types ...EventType) (*Subscription, error) {
-	if config == nil {
+	if config == nil && c.eventDefaults != nil {
+		defaults := *c.eventDefaults
+		config = &defaults
+	} else if config == nil {
 		config = DefaultEventConfig()
 	}
+	ctx = pollContext(ctx)
 
 	sub := &Subscription{
 		Events: make(chan Event, config.BufferSize),
 		done:   make(chan struct{}),
 		config: config,
+		client: c,
 	}
 
 	state := &lastState{
@@ -107,6 +146,9 @@ func (c *Client) SubscribeWithConfig(ctx context.Context, config *EventConfig, t
 	}
 
 	opts := ServerQueryOptions{}
+	banEvents := false
+	staffEvents := false
+	snapshotEvents := false
 	for _, eventType := range types {
 		switch eventType {
 		case EventTypePlayers:
@@ -123,46 +165,133 @@ func (c *Client) SubscribeWithConfig(ctx context.Context, config *EventConfig, t
 			opts.JoinLogs = true
 		case EventTypeEmergencyCalls:
 			opts.EmergencyCalls = true
+		case EventTypeQueue:
+			opts.Queue = true
+		case EventTypeBans:
+			banEvents = true
+		case EventTypeStaff:
+			staffEvents = true
+		case EventTypeSnapshot:
+			snapshotEvents = true
 		}
 	}
 
-	if resp, err := c.GetServer(ctx, opts); err == nil {
-		if opts.Players {
-			state.players = newPlayerSetFromSlice(resp.Players)
+	// Queue admissions and staff presence are derived from the player list,
+	// which is fetched even when player events were not requested.
+	playerEvents := opts.Players
+	if opts.Queue || staffEvents {
+		opts.Players = true
+	}
+
+	var election *leaderElection
+	if config.LeaderLock != nil {
+		election = &leaderElection{
+			lock:   config.LeaderLock,
+			key:    leaderKey(c.apiKey, config.LeaderName, types),
+			holder: newRandomID(),
+			lease:  leaderLease(config),
 		}
-		if opts.Vehicles {
-			for _, v := range resp.Vehicles {
-				state.vehicleSet[v.Owner+":"+v.Name] = struct{}{}
-			}
+		if _, _, err := election.step(ctx); err != nil && config.ErrorHandler != nil {
+			config.ErrorHandler(err)
 		}
-		if opts.CommandLogs && len(resp.CommandLogs) > 0 {
-			state.commandTime = resp.CommandLogs[0].Timestamp
+	}
+
+	// A subscription to bans alone has nothing to fetch from /v2/server.
+	pollServer := opts != (ServerQueryOptions{}) || !banEvents || snapshotEvents
+	banInterval := config.BanPollInterval
+	if banInterval <= 0 {
+		banInterval = defaultBanPollInterval
+	}
+	var lastBanPoll time.Time
+
+	var initial *ERLCServerResponse
+	if pollServer {
+		// Start from a cached response when one covers what we poll, so many
+		// subscriptions starting together do not each hit the API.
+		resp, ok := c.warmServer(ctx, opts)
+		if !ok {
+			var err error
+			if resp, err = c.GetServer(ctx, opts); err != nil {
+				if config.ErrorHandler != nil && ctx.Err() == nil {
+					config.ErrorHandler(err)
+				}
+				resp = nil
+			}
 		}
-		if opts.ModCalls && len(resp.ModCalls) > 0 {
-			state.modCallTime = resp.ModCalls[0].Timestamp
+		if resp != nil {
+			state.baseline(resp, opts)
+			initial = resp
 		}
-		if opts.KillLogs && len(resp.KillLogs) > 0 {
-			state.killTime = resp.KillLogs[0].Timestamp
+	}
+	if banEvents {
+		if bans, err := c.GetBans(ctx); err == nil {
+			state.bans = bans
+			lastBanPoll = time.Now()
+			if config.StaggerStart {
+				// Spread ban polls independently of the main poll.
+				lastBanPoll = lastBanPoll.Add(-staggerOffset(c.apiKey, []EventType{EventTypeBans}, banInterval))
+			}
 		}
-		if opts.JoinLogs && len(resp.JoinLogs) > 0 {
-			state.joinTime = resp.JoinLogs[0].Timestamp
+	}
+
+	var checkpoints *checkpointer
+	if config.CheckpointStore != nil {
+		checkpoints = &checkpointer{
+			store:     config.CheckpointStore,
+			serverKey: c.apiKey,
+			types:     types,
+			saved:     make(map[EventType]string),
+			onError:   config.ErrorHandler,
 		}
-		if opts.EmergencyCalls {
-			for _, ec := range resp.EmergencyCalls {
-				state.emergencyCallNumbers[ec.CallNumber] = struct{}{}
+		if err := checkpoints.load(ctx, state); err != nil {
+			if election != nil {
+				election.release()
 			}
+			return nil, err
 		}
 	}
 
+	// Seed dedup only after checkpoints may have rewound the watermarks, so
+	// the entries missed while we were down are still delivered.
+	if initial != nil {
+		state.seedDedup(initial, opts, config.DedupWindow)
+	}
+
 	state.initialized = true
 
-	go func() {
+	c.trackSubscription(sub)
+
+	spawn(c, "subscription poller", func() {
 		defer close(sub.Events)
+		defer c.untrackSubscription(sub)
+		if election != nil {
+			defer election.release()
+		}
+
+		if snapshotEvents && initial != nil {
+			if !sub.send(ctx, newEvent(EventTypeSnapshot, initial)) {
+				return
+			}
+		}
+
+		if config.StaggerStart {
+			timer := time.NewTimer(staggerOffset(c.apiKey, types, config.PollInterval))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-sub.done:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
 
 		ticker := time.NewTicker(config.PollInterval)
 		defer ticker.Stop()
 
 		var mu sync.RWMutex
+		lastPoll := time.Now()
 
 		for {
 			select {
@@ -171,35 +300,115 @@ func (c *Client) SubscribeWithConfig(ctx context.Context, config *EventConfig, t
 			case <-sub.done:
 				return
 			case <-ticker.C:
-				if resp, err := c.GetServer(ctx, opts); err == nil {
+				if wait := c.pollThrottle(config.PollInterval); wait > 0 {
+					c.bus.publish(LifecycleEvent{Type: LifecyclePollThrottled, Route: "GET /v2/server", Duration: wait})
+					timer := time.NewTimer(wait)
+					select {
+					case <-ctx.Done():
+						timer.Stop()
+						return
+					case <-sub.done:
+						timer.Stop()
+						return
+					case <-timer.C:
+					}
+				}
+
+				if election != nil {
+					leading, tookOver, err := election.step(ctx)
+					if err != nil && config.ErrorHandler != nil {
+						config.ErrorHandler(err)
+					}
+					if !leading {
+						continue
+					}
+					if tookOver {
+						// Our state is stale after standing by; resume from the
+						// previous leader's checkpoints, or re-baseline so we do
+						// not re-emit events it already delivered.
+						if checkpoints != nil {
+							mu.Lock()
+							err := checkpoints.load(ctx, state)
+							mu.Unlock()
+							if err != nil {
+								if config.ErrorHandler != nil {
+									config.ErrorHandler(err)
+								}
+								return
+							}
+						} else {
+							if resp, err := c.GetServer(ctx, opts); err == nil {
+								mu.Lock()
+								state.baseline(resp, opts)
+								state.seedDedup(resp, opts, config.DedupWindow)
+								mu.Unlock()
+								if snapshotEvents && !sub.send(ctx, newEvent(EventTypeSnapshot, resp)) {
+									return
+								}
+							}
+							continue
+						}
+					}
+				}
 
-					if opts.Players && resp.Players != nil {
-						newSet := newPlayerSetFromSlice(resp.Players)
+				if banEvents && time.Since(lastBanPoll) >= banInterval {
+					if bans, err := c.GetBans(ctx); err != nil {
+						c.bus.publish(LifecycleEvent{Type: LifecycleSubscriptionDegraded, Route: "GET /v1/server/bans", Err: err})
+						if config.ErrorHandler != nil && ctx.Err() == nil {
+							config.ErrorHandler(err)
+						}
+					} else {
+						lastBanPoll = time.Now()
 						mu.Lock()
-						oldSet := state.players
-						state.players = newSet
+						old := state.bans
+						state.bans = bans
 						mu.Unlock()
 
-						changes := make([]PlayerEvent, 0)
-						for _, player := range resp.Players {
-							if _, exists := oldSet[player.Player]; !exists {
-								changes = append(changes, PlayerEvent{
-									Player: player,
-									Type:   "join",
-								})
+						// Without a baseline every ban would look new.
+						if old != nil {
+							if changes := diffBans(old, bans, lastBanPoll); len(changes) > 0 {
+								if !sub.send(ctx, newEvent(EventTypeBans, changes)) {
+									return
+								}
 							}
 						}
-						for player := range oldSet {
-							if _, exists := newSet[player]; !exists {
-								changes = append(changes, PlayerEvent{
-									Player: ERLCServerPlayer{Player: player},
-									Type:   "leave",
-								})
+					}
+				}
+
+				if !pollServer {
+					if checkpoints != nil {
+						mu.RLock()
+						checkpoints.save(ctx, state)
+						mu.RUnlock()
+					}
+					continue
+				}
+
+				resp, err := c.GetServer(ctx, opts)
+				if err != nil {
+					c.bus.publish(LifecycleEvent{Type: LifecycleSubscriptionDegraded, Route: "GET /v2/server", Err: err})
+					if config.ErrorHandler != nil && ctx.Err() == nil {
+						config.ErrorHandler(err)
+					}
+				} else {
+					// After a long gap, deliver only the log entries from the
+					// gap and flag them as replayed.
+					replay := config.CatchUpAfter > 0 && time.Since(lastPoll) > config.CatchUpAfter
+					lastPoll = time.Now()
+
+					if playerEvents && resp.Players != nil {
+						mu.Lock()
+						newSet := state.nextPlayerSet(resp.Players)
+						oldSet := state.players
+						state.players = newSet
+						state.sparePlayers = oldSet
+						mu.Unlock()
+
+						if changes := state.diffPlayers(oldSet, newSet, resp.Players, lastPoll); len(changes) > 0 {
+							if !sub.send(ctx, newEvent(EventTypePlayers, changes)) {
+								return
 							}
 						}
-						if len(changes) > 0 {
-							sub.Events <- Event{Type: EventTypePlayers, Data: changes}
-						}
 					}
 
 					if opts.CommandLogs && len(resp.CommandLogs) > 0 {
@@ -207,12 +416,14 @@ func (c *Client) SubscribeWithConfig(ctx context.Context, config *EventConfig, t
 						lastTime := state.commandTime
 						mu.RUnlock()
 
-						if resp.CommandLogs[0].Timestamp > lastTime {
+						if e, ok := logEvent(EventTypeCommands, resp.CommandLogs, lastTime, replay, state.dedupFor(EventTypeCommands, config.DedupWindow)); ok {
 							mu.Lock()
-							state.commandTime = resp.CommandLogs[0].Timestamp
+							state.commandTime = max(lastTime, logTimestamp(resp.CommandLogs[0]))
 							mu.Unlock()
 
-							sub.Events <- Event{Type: EventTypeCommands, Data: resp.CommandLogs}
+							if !sub.send(ctx, e) {
+								return
+							}
 						}
 					}
 
@@ -221,12 +432,14 @@ func (c *Client) SubscribeWithConfig(ctx context.Context, config *EventConfig, t
 						lastTime := state.modCallTime
 						mu.RUnlock()
 
-						if resp.ModCalls[0].Timestamp > lastTime {
+						if e, ok := logEvent(EventTypeModCalls, resp.ModCalls, lastTime, replay, state.dedupFor(EventTypeModCalls, config.DedupWindow)); ok {
 							mu.Lock()
-							state.modCallTime = resp.ModCalls[0].Timestamp
+							state.modCallTime = max(lastTime, logTimestamp(resp.ModCalls[0]))
 							mu.Unlock()
 
-							sub.Events <- Event{Type: EventTypeModCalls, Data: resp.ModCalls}
+							if !sub.send(ctx, e) {
+								return
+							}
 						}
 					}
 
@@ -235,12 +448,14 @@ func (c *Client) SubscribeWithConfig(ctx context.Context, config *EventConfig, t
 						lastTime := state.killTime
 						mu.RUnlock()
 
-						if resp.KillLogs[0].Timestamp > lastTime {
+						if e, ok := logEvent(EventTypeKills, resp.KillLogs, lastTime, replay, state.dedupFor(EventTypeKills, config.DedupWindow)); ok {
 							mu.Lock()
-							state.killTime = resp.KillLogs[0].Timestamp
+							state.killTime = max(lastTime, logTimestamp(resp.KillLogs[0]))
 							mu.Unlock()
 
-							sub.Events <- Event{Type: EventTypeKills, Data: resp.KillLogs}
+							if !sub.send(ctx, e) {
+								return
+							}
 						}
 					}
 
@@ -249,66 +464,348 @@ func (c *Client) SubscribeWithConfig(ctx context.Context, config *EventConfig, t
 						lastTime := state.joinTime
 						mu.RUnlock()
 
-						if resp.JoinLogs[0].Timestamp > lastTime {
+						if e, ok := logEvent(EventTypeJoins, resp.JoinLogs, lastTime, replay, state.dedupFor(EventTypeJoins, config.DedupWindow)); ok {
 							mu.Lock()
-							state.joinTime = resp.JoinLogs[0].Timestamp
+							state.joinTime = max(lastTime, logTimestamp(resp.JoinLogs[0]))
 							mu.Unlock()
 
-							sub.Events <- Event{Type: EventTypeJoins, Data: resp.JoinLogs}
+							if !sub.send(ctx, e) {
+								return
+							}
 						}
 					}
 
 					if opts.Vehicles && resp.Vehicles != nil {
-						newSet := make(map[string]struct{})
+						mu.Lock()
+						newSet := reuseSet(state.spareVehicles, len(resp.Vehicles))
 						for _, v := range resp.Vehicles {
-							newSet[v.Owner+":"+v.Name] = struct{}{}
+							newSet[vehicleKey(v)] = struct{}{}
 						}
-
-						mu.Lock()
 						oldSet := state.vehicleSet
 						state.vehicleSet = newSet
+						state.spareVehicles = oldSet
 						mu.Unlock()
 
-						newVehicles := make([]ERLCVehicle, 0)
-						for _, vehicle := range resp.Vehicles {
-							key := vehicle.Owner + ":" + vehicle.Name
-							if _, exists := oldSet[key]; !exists {
-								newVehicles = append(newVehicles, vehicle)
-							}
+						newVehicles := spawnedVehicles(oldSet, resp.Vehicles)
+						for i := range newVehicles {
+							newVehicles[i].ObservedAt = lastPoll
 						}
 
 						if len(newVehicles) > 0 {
-							sub.Events <- Event{Type: EventTypeVehicles, Data: newVehicles}
+							if !sub.send(ctx, newEvent(EventTypeVehicles, newVehicles)) {
+								return
+							}
 						}
 					}
 
 					if opts.EmergencyCalls && len(resp.EmergencyCalls) > 0 {
 						mu.Lock()
 						oldCallNumbers := state.emergencyCallNumbers
-						newCallNumbers := make(map[int]struct{})
-						newCalls := make([]ERLCEmergencyCall, 0)
-
+						newCallNumbers := reuseSet(state.spareCalls, len(resp.EmergencyCalls))
 						for _, ec := range resp.EmergencyCalls {
 							newCallNumbers[ec.CallNumber] = struct{}{}
-							if _, exists := oldCallNumbers[ec.CallNumber]; !exists {
-								newCalls = append(newCalls, ec)
-							}
 						}
+						newCalls := newEmergencyCalls(oldCallNumbers, resp.EmergencyCalls)
 						state.emergencyCallNumbers = newCallNumbers
+						state.spareCalls = oldCallNumbers
 						mu.Unlock()
 
 						if len(newCalls) > 0 {
-							sub.Events <- Event{Type: EventTypeEmergencyCalls, Data: newCalls}
+							if !sub.send(ctx, newEvent(EventTypeEmergencyCalls, newCalls)) {
+								return
+							}
+						}
+					}
+
+					if staffEvents && resp.Players != nil {
+						current := staffSet(resp.Players)
+						mu.Lock()
+						oldStaff := state.staff
+						state.staff = make(playerSet, len(current))
+						for name := range current {
+							state.staff[name] = struct{}{}
+						}
+						mu.Unlock()
+
+						if changes := diffStaff(oldStaff, current, lastPoll); len(changes) > 0 {
+							if !sub.send(ctx, newEvent(EventTypeStaff, changes)) {
+								return
+							}
+						}
+					}
+
+					if opts.Queue {
+						mu.Lock()
+						oldQueue := state.queue
+						state.queue = append(state.queue[:0:0], resp.Queue...)
+						mu.Unlock()
+
+						if changes := diffQueue(oldQueue, resp.Queue, resp.Players, lastPoll); len(changes) > 0 {
+							if !sub.send(ctx, newEvent(EventTypeQueue, changes)) {
+								return
+							}
 						}
 					}
+
+					if snapshotEvents && !sub.send(ctx, newEvent(EventTypeSnapshot, resp)) {
+						return
+					}
+
+					if checkpoints != nil {
+						mu.RLock()
+						checkpoints.save(ctx, state)
+						mu.RUnlock()
+					}
 				}
 			}
 		}
-	}()
+	})
 
 	return sub, nil
 }
 
 func (c *Client) Subscribe(ctx context.Context, types ...EventType) (*Subscription, error) {
-	return c.SubscribeWithConfig(ctx, DefaultEventConfig(), types...)
+	return c.SubscribeWithConfig(ctx, nil, types...)
+}
+
+// baseline records the current server state as already seen, so it does not
+// produce events on the next poll.
+func (s *lastState) baseline(resp *ERLCServerResponse, opts ServerQueryOptions) {
+	if opts.Players {
+		s.players = newPlayerSetFromSlice(resp.Players)
+		s.rememberPlayers(resp.Players)
+		s.staff = make(playerSet)
+		for name := range staffSet(resp.Players) {
+			s.staff[name] = struct{}{}
+		}
+	}
+	if opts.Vehicles {
+		s.vehicleSet = make(map[string]struct{}, len(resp.Vehicles))
+		for _, v := range resp.Vehicles {
+			s.vehicleSet[vehicleKey(v)] = struct{}{}
+		}
+	}
+	if opts.CommandLogs && len(resp.CommandLogs) > 0 {
+		s.commandTime = logTimestamp(resp.CommandLogs[0])
+	}
+	if opts.ModCalls && len(resp.ModCalls) > 0 {
+		s.modCallTime = logTimestamp(resp.ModCalls[0])
+	}
+	if opts.KillLogs && len(resp.KillLogs) > 0 {
+		s.killTime = logTimestamp(resp.KillLogs[0])
+	}
+	if opts.JoinLogs && len(resp.JoinLogs) > 0 {
+		s.joinTime = logTimestamp(resp.JoinLogs[0])
+	}
+	if opts.Queue {
+		s.queue = append(s.queue[:0:0], resp.Queue...)
+	}
+	if opts.EmergencyCalls {
+		s.emergencyCallNumbers = make(map[int]struct{}, len(resp.EmergencyCalls))
+		for _, ec := range resp.EmergencyCalls {
+			s.emergencyCallNumbers[ec.CallNumber] = struct{}{}
+		}
+	}
+}
+
+// nextPlayerSet builds the player set for the current poll, reusing the set
+// from two polls ago so steady polling does not allocate a new map per tick.
+func (s *lastState) nextPlayerSet(players []ERLCServerPlayer) playerSet {
+	set := reuseSet(s.sparePlayers, len(players))
+	for _, p := range players {
+		set[p.Player] = struct{}{}
+	}
+	return set
+}
+
+// diffPlayers returns the joins and leaves between two polls' player sets,
+// stamped with the poll time at, and records the players of the current poll.
+// The slice is sized by the previous diff, as joins and leaves come in
+// similar numbers from tick to tick.
+func (s *lastState) diffPlayers(oldSet, newSet playerSet, players []ERLCServerPlayer, at time.Time) []PlayerEvent {
+	changes := make([]PlayerEvent, 0, s.playerChanges)
+	for _, player := range players {
+		if _, exists := oldSet[player.Player]; !exists {
+			changes = append(changes, PlayerEvent{
+				Player:     player,
+				Type:       "join",
+				ObservedAt: at,
+			})
+		}
+	}
+	for player := range oldSet {
+		if _, exists := newSet[player]; !exists {
+			record, ok := s.playerRecords[player]
+			if !ok {
+				record = ERLCServerPlayer{Player: player}
+			}
+			changes = append(changes, PlayerEvent{
+				Player:     record,
+				Type:       "leave",
+				ObservedAt: at,
+			})
+			delete(s.playerRecords, player)
+		}
+	}
+	s.rememberPlayers(players)
+	s.playerChanges = len(changes)
+	return changes
+}
+
+// logEvent builds the event for a log with new entries, reporting false if
+// there are none. Without dedup, an entry newer than since means the whole log
+// is delivered, as it always has been, and replayed events carry only the
+// entries newer than since. With dedup, entries at or after since that dedup
+// has not seen are delivered, so entries sharing the newest second are
+// neither lost nor repeated.
+func logEvent[T interface{ ID() string }](eventType EventType, logs []T, since int64, replay bool, dedup *dedupWindow) (Event, bool) {
+	if dedup == nil {
+		if logTimestamp(logs[0]) <= since {
+			return Event{}, false
+		}
+		if !replay {
+			return newEvent(eventType, logs), true
+		}
+		e := newEvent(eventType, newerLogs(logs, since))
+		e.Replayed = true
+		return e, true
+	}
+
+	var fresh []T
+	for _, l := range logs {
+		if logTimestamp(l) >= since && dedup.add(l.ID()) {
+			fresh = append(fresh, l)
+		}
+	}
+	if len(fresh) == 0 {
+		return Event{}, false
+	}
+	e := newEvent(eventType, fresh)
+	e.Replayed = replay
+	return e, true
+}
+
+// logTimestamp returns the Timestamp of a log entry in Unix seconds. Log
+// timestamps are compared through it, so a switch to milliseconds does not
+// reorder entries or move watermarks far into the future.
+func logTimestamp[T any](entry T) int64 {
+	switch e := any(entry).(type) {
+	case ERLCCommandLog:
+		return NormalizeTimestamp(e.Timestamp)
+	case ERLCModCallLog:
+		return NormalizeTimestamp(e.Timestamp)
+	case ERLCKillLog:
+		return NormalizeTimestamp(e.Timestamp)
+	case ERLCJoinLog:
+		return NormalizeTimestamp(e.Timestamp)
+	}
+	return 0
+}
+
+// staggerOffset returns a stable offset within interval for a subscription,
+// derived from its server key and event types, so subscriptions started
+// together poll at different points of the interval.
+func staggerOffset(serverKey string, types []EventType, interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write([]byte(serverKey))
+	for _, t := range types {
+		h.Write([]byte{0})
+		h.Write([]byte(t))
+	}
+	return time.Duration(h.Sum64() % uint64(interval))
+}
+
+// rememberPlayers records the latest full record of each player.
+func (s *lastState) rememberPlayers(players []ERLCServerPlayer) {
+	if s.playerRecords == nil {
+		s.playerRecords = make(map[string]ERLCServerPlayer, len(players))
+	}
+	for _, p := range players {
+		s.playerRecords[p.Player] = p
+	}
+}
+
+// reuseSet clears and returns spare, or allocates a set sized for n entries
+// when there is nothing to reuse.
+func reuseSet[K comparable](spare map[K]struct{}, n int) map[K]struct{} {
+	if spare == nil {
+		return make(map[K]struct{}, n)
+	}
+	clear(spare)
+	return spare
+}
+
+// pollThrottle returns how much longer than interval a sub-second poller
+// should wait so that it does not spend the remaining rate limit budget
+// before the window resets. Intervals of a second or more are never stretched.
+func (c *Client) pollThrottle(interval time.Duration) time.Duration {
+	if interval >= time.Second || c.rateLimiter == nil {
+		return 0
+	}
+	state, ok := c.rateLimitBudget("global")
+	if !ok || state.Limit <= 0 {
+		return 0
+	}
+	until := time.Until(state.Reset)
+	if until <= 0 {
+		return 0
+	}
+	if state.Remaining <= 0 {
+		return until
+	}
+	if safe := until / time.Duration(state.Remaining); safe > interval {
+		return c.jitter(safe - interval)
+	}
+	return 0
+}
+
+// diffQueue compares two queue snapshots. A user who left the queue and is
+// now in the player list was admitted; anyone else who left gave up waiting.
+// Changes are stamped with the poll time at.
+func diffQueue(old, current []int64, players []ERLCServerPlayer, at time.Time) []QueueEvent {
+	oldSet := make(map[int64]struct{}, len(old))
+	for _, id := range old {
+		oldSet[id] = struct{}{}
+	}
+	currentSet := make(map[int64]struct{}, len(current))
+	for _, id := range current {
+		currentSet[id] = struct{}{}
+	}
+	online := make(map[int64]struct{}, len(players))
+	for _, p := range players {
+		if id, ok := playerUserID(p.Player); ok {
+			online[id] = struct{}{}
+		}
+	}
+
+	changes := make([]QueueEvent, 0)
+	for i, id := range current {
+		if _, ok := oldSet[id]; !ok {
+			changes = append(changes, QueueEvent{UserID: id, Type: "entered", Position: i + 1, ObservedAt: at})
+		}
+	}
+	for _, id := range old {
+		if _, ok := currentSet[id]; ok {
+			continue
+		}
+		if _, ok := online[id]; ok {
+			changes = append(changes, QueueEvent{UserID: id, Type: "admitted", ObservedAt: at})
+		} else {
+			changes = append(changes, QueueEvent{UserID: id, Type: "left", ObservedAt: at})
+		}
+	}
+	return changes
+}
+
+// playerUserID extracts the Roblox user ID from a "Name:ID" player string.
+func playerUserID(player string) (int64, bool) {
+	i := strings.LastIndexByte(player, ':')
+	if i < 0 {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(player[i+1:], 10, 64)
+	return id, err == nil
 }
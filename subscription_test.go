@@ -0,0 +1,78 @@
+package erlcgo
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+// pollPlayers returns the player lists of two alternating polls of a full
+// server, with a few players leaving and joining between them.
+func pollPlayers() [2][]ERLCServerPlayer {
+	var polls [2][]ERLCServerPlayer
+	for i := 0; i < 40; i++ {
+		p := ERLCServerPlayer{Player: "Player" + strconv.Itoa(i) + ":" + strconv.Itoa(1000+i), Team: "Civilian"}
+		polls[0] = append(polls[0], p)
+		if i%10 == 0 {
+			p.Player = "Joiner" + strconv.Itoa(i) + ":" + strconv.Itoa(2000+i)
+		}
+		polls[1] = append(polls[1], p)
+	}
+	return polls
+}
+
+// diffPlayerPoll runs the player diff of one subscription tick, either as the
+// subscription does, reusing sets and the capacity hint, or allocating afresh.
+func diffPlayerPoll(state *lastState, players []ERLCServerPlayer, reuse bool) int {
+	var newSet playerSet
+	if reuse {
+		newSet = state.nextPlayerSet(players)
+	} else {
+		newSet = newPlayerSetFromSlice(players)
+		state.playerChanges = 0
+	}
+	oldSet := state.players
+	state.players = newSet
+	if reuse {
+		state.sparePlayers = oldSet
+	}
+	return len(state.diffPlayers(oldSet, newSet, players, time.Time{}))
+}
+
+func TestPlayerDiffReuse(t *testing.T) {
+	polls := pollPlayers()
+	fresh, reused := &lastState{}, &lastState{}
+	for i := 0; i < 6; i++ {
+		players := polls[i%2]
+		want := diffPlayerPoll(fresh, players, false)
+		if got := diffPlayerPoll(reused, players, true); got != want {
+			t.Fatalf("poll %d: %d changes with reused sets, want %d", i, got, want)
+		}
+		if len(reused.players) != len(players) {
+			t.Fatalf("poll %d: reused set holds %d players, want %d", i, len(reused.players), len(players))
+		}
+	}
+}
+
+// BenchmarkPlayerDiff measures the allocations of the player diff run on
+// every tick of a 1-second poll, with fresh and reused sets.
+func BenchmarkPlayerDiff(b *testing.B) {
+	polls := pollPlayers()
+	for _, bench := range []struct {
+		name  string
+		reuse bool
+	}{
+		{"fresh", false},
+		{"reused", true},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			state := &lastState{}
+			diffPlayerPoll(state, polls[1], bench.reuse)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				diffPlayerPoll(state, polls[i%2], bench.reuse)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package erlcgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrTargetOffline matches TargetOfflineErrors with errors.Is.
+var ErrTargetOffline = errors.New("erlc: command target is not in the server")
+
+// TargetOfflineError is returned under WithTargetCheck when a command names a
+// player who is not in the server.
+type TargetOfflineError struct {
+	Command string
+	Target  string
+}
+
+func (e *TargetOfflineError) Error() string {
+	return fmt.Sprintf("erlc: %q is not in the server, not sending %q", e.Target, e.Command)
+}
+
+func (e *TargetOfflineError) Is(target error) bool {
+	return target == ErrTargetOffline
+}
+
+// targetedCommands are the verbs whose first argument is a player who must
+// be in the server.
+var targetedCommands = map[string]bool{
+	"kick": true,
+	"ban":  true,
+	"pm":   true,
+	"load": true,
+}
+
+// groupTargets are targets that select several players rather than naming
+// one, and are never checked.
+var groupTargets = map[string]bool{
+	"all":    true,
+	"others": true,
+	"me":     true,
+}
+
+// WithTargetCheck makes ExecuteCommand check that the player named by a
+// kick, ban, pm or load command is in the server before sending it, and
+// return a TargetOfflineError if not, instead of the less helpful error PRC
+// gives. The player list comes from GetServer, so with caching enabled the
+// check usually costs no request. If the player list cannot be fetched the
+// command is sent unchecked.
+//
+// Note that with the check enabled, players cannot be banned while offline.
+//
+// Example:
+//
+//	client := NewClient("your-server-key", WithTargetCheck(true))
+//	err := client.ExecuteCommand(ctx, ":kick Player1 Exploiting")
+//	if errors.Is(err, erlcgo.ErrTargetOffline) {
+//	    fmt.Println("Player1 already left")
+//	}
+func WithTargetCheck(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.checkTargets = enabled
+	}
+}
+
+// checkTarget returns a TargetOfflineError if command targets a player who
+// is not in the server.
+func (c *Client) checkTarget(ctx context.Context, command string) error {
+	if !c.checkTargets {
+		return nil
+	}
+	fields := strings.Fields(command)
+	if len(fields) < 2 || !targetedCommands[strings.ToLower(strings.TrimLeft(fields[0], ":/"))] {
+		return nil
+	}
+	target := fields[1]
+	if groupTargets[strings.ToLower(target)] {
+		return nil
+	}
+
+	resp, err := c.GetServer(ctx, ServerQueryOptions{Players: true})
+	if err != nil {
+		return nil
+	}
+	for _, p := range resp.Players {
+		if targetMatches(p.Player, target) {
+			return nil
+		}
+	}
+	return &TargetOfflineError{Command: command, Target: target}
+}
+
+// targetMatches reports whether a "Name:ID" player string is selected by
+// target. Since the game accepts shortened names, a prefix of the username
+// counts as well as the full name or user ID.
+func targetMatches(player, target string) bool {
+	if playerNameMatches(player, target) {
+		return true
+	}
+	name, _, _ := strings.Cut(player, ":")
+	return len(name) >= len(target) && strings.EqualFold(name[:len(target)], target)
+}
@@ -0,0 +1,100 @@
+package erlcgo
+
+import (
+	"context"
+	"sort"
+)
+
+type tenantKey struct{}
+
+// WithTenant returns a context tagging requests made with it as belonging to
+// tenant, such as a Discord guild ID in a bot serving many guilds. The tag is
+// carried on lifecycle events, dead letters and journal entries, and counted
+// by the rate limiter, so operators can see which tenant uses the shared API
+// budget. It takes precedence over WithDefaultTenant.
+//
+// Example:
+//
+//	ctx := erlcgo.WithTenant(ctx, guildID)
+//	resp, err := client.GetServer(ctx, erlcgo.ServerQueryOptions{Players: true})
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFrom returns the tenant stored in ctx, or "" if none is set.
+func TenantFrom(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// WithDefaultTenant tags every request made by the client with tenant unless
+// the request context carries its own tag. It suits setups with one client
+// per tenant.
+//
+// Example:
+//
+//	client := NewClient("guild-server-key",
+//	    WithRateLimiter(sharedLimiter),
+//	    WithDefaultTenant(guildID),
+//	)
+func WithDefaultTenant(tenant string) ClientOption {
+	return func(c *Client) {
+		c.tenant = tenant
+	}
+}
+
+// tenantOf returns the tenant a request made with ctx is attributed to.
+func (c *Client) tenantOf(ctx context.Context) string {
+	if tenant := TenantFrom(ctx); tenant != "" {
+		return tenant
+	}
+	return c.tenant
+}
+
+// TenantUsage counts the requests a tenant sent through a RateLimiter.
+// Coalesced GETs are counted once, for the tenant whose request was sent.
+type TenantUsage struct {
+	Tenant      string
+	Requests    int64
+	Commands    int64
+	RateLimited int64
+}
+
+// recordTenant counts one request sent on behalf of tenant.
+func (rl *RateLimiter) recordTenant(tenant string, command, rateLimited bool) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if rl.tenants == nil {
+		rl.tenants = make(map[string]*TenantUsage)
+	}
+	u, ok := rl.tenants[tenant]
+	if !ok {
+		u = &TenantUsage{Tenant: tenant}
+		rl.tenants[tenant] = u
+	}
+	u.Requests++
+	if command {
+		u.Commands++
+	}
+	if rateLimited {
+		u.RateLimited++
+	}
+}
+
+// TenantUsage returns the usage of every tenant that sent requests through
+// the limiter, sorted by tenant. Untagged requests are reported under "".
+func (rl *RateLimiter) TenantUsage() []TenantUsage {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+
+	usage := make([]TenantUsage, 0, len(rl.tenants))
+	for _, u := range rl.tenants {
+		usage = append(usage, *u)
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Tenant < usage[j].Tenant })
+	return usage
+}
@@ -0,0 +1,98 @@
+package erlcgo
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTenantQuotaExceeded is matched via errors.Is by TenantQuotaError.
+var ErrTenantQuotaExceeded = errors.New("erlc: tenant quota exceeded")
+
+// TenantQuotaError is returned, without contacting the API, when a tenant
+// has used up its quota on the RateLimiter. RetryAfter is how long until the
+// tenant may send another request.
+type TenantQuotaError struct {
+	Tenant     string
+	Quota      int
+	RetryAfter time.Duration
+}
+
+func (e *TenantQuotaError) Error() string {
+	return fmt.Sprintf("tenant %q exceeded its quota of %d requests per minute, retry after %s", e.Tenant, e.Quota, e.RetryAfter)
+}
+
+func (e *TenantQuotaError) Is(target error) bool {
+	return target == ErrTenantQuotaExceeded
+}
+
+// tenantQuotaWindow is the period tenant quotas are counted over.
+const tenantQuotaWindow = time.Minute
+
+// SetTenantQuota limits tenant to perMinute requests per minute across every
+// client sharing the limiter, so one tenant cannot exhaust a shared global
+// key. Requests over the quota fail with a TenantQuotaError. A quota of zero
+// or less removes the tenant's own quota, falling back to the default.
+//
+// Example:
+//
+//	limiter := erlcgo.NewRateLimiter()
+//	limiter.SetDefaultTenantQuota(30)
+//	limiter.SetTenantQuota("premium-guild", 120)
+func (rl *RateLimiter) SetTenantQuota(tenant string, perMinute int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if perMinute <= 0 {
+		delete(rl.quotas, tenant)
+		return
+	}
+	if rl.quotas == nil {
+		rl.quotas = make(map[string]int)
+	}
+	rl.quotas[tenant] = perMinute
+}
+
+// SetDefaultTenantQuota sets the quota for tagged tenants without their own.
+// Untagged requests are never subject to quotas. Zero disables the default.
+func (rl *RateLimiter) SetDefaultTenantQuota(perMinute int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.defaultQuota = perMinute
+}
+
+// reserveTenant takes a slot from tenant's quota, or returns a
+// TenantQuotaError if none is left.
+func (rl *RateLimiter) reserveTenant(tenant string, now time.Time) error {
+	if tenant == "" {
+		return nil
+	}
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	quota, ok := rl.quotas[tenant]
+	if !ok {
+		quota = rl.defaultQuota
+	}
+	if quota <= 0 {
+		return nil
+	}
+
+	// Drop requests that have left the window.
+	sent := rl.quotaWindows[tenant]
+	i := 0
+	for i < len(sent) && now.Sub(sent[i]) >= tenantQuotaWindow {
+		i++
+	}
+	sent = sent[i:]
+
+	if len(sent) >= quota {
+		rl.quotaWindows[tenant] = sent
+		return &TenantQuotaError{Tenant: tenant, Quota: quota, RetryAfter: sent[0].Add(tenantQuotaWindow).Sub(now)}
+	}
+	if rl.quotaWindows == nil {
+		rl.quotaWindows = make(map[string][]time.Time)
+	}
+	rl.quotaWindows[tenant] = append(sent, now)
+	return nil
+}
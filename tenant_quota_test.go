@@ -0,0 +1,86 @@
+package erlcgo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTenantQuotaReservedOncePerRequest(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"code":4001,"message":"rate limited","retry_after":0.05}`))
+			return
+		}
+		w.Write([]byte(`{"message":"Success"}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient("key", WithBaseURL(srv.URL), WithRequestQueue(1, time.Millisecond))
+	defer c.Close()
+	c.queue.SetRateLimitRetries(1)
+	c.rateLimiter.SetTenantQuota("guild", 2)
+	ctx := WithTenant(context.Background(), "guild")
+
+	// The first command is retried by the queue after its 429.
+	if err := c.ExecuteCommand(ctx, ":h one"); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&hits); n != 2 {
+		t.Fatalf("server got %d requests, want 2", n)
+	}
+	if err := c.ExecuteCommand(ctx, ":h two"); err != nil {
+		t.Fatalf("second command of a quota of 2: %v", err)
+	}
+	if err := c.ExecuteCommand(ctx, ":h three"); err == nil {
+		t.Fatal("third command of a quota of 2 succeeded")
+	}
+}
+
+func TestTenantQuotaNotSharedByCoalescing(t *testing.T) {
+	release := make(chan struct{})
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) > 1 {
+			<-release
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"Name":"Test"}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient("key", WithBaseURL(srv.URL))
+	defer c.Close()
+	c.rateLimiter.SetTenantQuota("spent", 1)
+	spent := WithTenant(context.Background(), "spent")
+	if _, err := c.GetServer(spent); err != nil {
+		t.Fatal(err)
+	}
+
+	// Tenant "other" starts a GET and "spent" asks for the same URL while
+	// it is in flight; "spent" must not get the data past its quota.
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.GetServer(WithTenant(context.Background(), "other"))
+		done <- err
+	}()
+	for atomic.LoadInt32(&hits) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	time.AfterFunc(100*time.Millisecond, func() { close(release) })
+	_, err := c.GetServer(spent)
+	var quotaErr *TenantQuotaError
+	if !errors.As(err, &quotaErr) {
+		t.Errorf("over-quota tenant got %v, want a TenantQuotaError", err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("other tenant: %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package erlcgo
+
+import "time"
+
+// millisecondThreshold separates second and millisecond Unix timestamps.
+// As seconds it is the year 5138; as milliseconds it is March 1973, well
+// before any PRC log.
+const millisecondThreshold = 100_000_000_000
+
+// NormalizeTimestamp returns ts as Unix seconds. PRC sends log timestamps in
+// seconds; values large enough to only make sense as milliseconds are
+// converted, so code keeps working if the API switches units.
+func NormalizeTimestamp(ts int64) int64 {
+	if ts >= millisecondThreshold || ts <= -millisecondThreshold {
+		return ts / 1000
+	}
+	return ts
+}
+
+// ParseTimestamp converts a log timestamp to a time.Time in UTC, detecting
+// millisecond-encoded values.
+func ParseTimestamp(ts int64) time.Time {
+	if ts >= millisecondThreshold || ts <= -millisecondThreshold {
+		return time.UnixMilli(ts).UTC()
+	}
+	return time.Unix(ts, 0).UTC()
+}
+
+// WithTimeLocation sets the time zone LogTime and FormatLogTime render
+// timestamps in. The default is UTC.
+//
+// Example:
+//
+//	loc, _ := time.LoadLocation("America/New_York")
+//	client := NewClient("your-server-key",
+//	    WithTimeLocation(loc),
+//	)
+func WithTimeLocation(loc *time.Location) ClientOption {
+	return func(c *Client) {
+		c.timeLocation = loc
+	}
+}
+
+// LogTime converts a log timestamp to a time.Time in the client's configured
+// time zone.
+func (c *Client) LogTime(ts int64) time.Time {
+	t := ParseTimestamp(ts)
+	if c.timeLocation != nil {
+		t = t.In(c.timeLocation)
+	}
+	return t
+}
+
+// FormatLogTime formats a log timestamp with layout in the client's
+// configured time zone.
+//
+// Example:
+//
+//	for _, l := range resp.KillLogs {
+//	    fmt.Println(client.FormatLogTime(l.Timestamp, time.Kitchen), l.Killer, "killed", l.Killed)
+//	}
+func (c *Client) FormatLogTime(ts int64, layout string) string {
+	return c.LogTime(ts).Format(layout)
+}
+
+// Time returns when the command was executed.
+func (l ERLCCommandLog) Time() time.Time { return ParseTimestamp(l.Timestamp) }
+
+// Time returns when the mod call was made.
+func (l ERLCModCallLog) Time() time.Time { return ParseTimestamp(l.Timestamp) }
+
+// Time returns when the kill happened.
+func (l ERLCKillLog) Time() time.Time { return ParseTimestamp(l.Timestamp) }
+
+// Time returns when the player joined or left.
+func (l ERLCJoinLog) Time() time.Time { return ParseTimestamp(l.Timestamp) }
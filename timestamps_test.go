@@ -0,0 +1,55 @@
+package erlcgo
+
+import (
+	"testing"
+	"time"
+)
+
+// Millisecond timestamps must compare like the seconds they stand for
+// wherever logs are diffed or bucketed.
+func TestMillisecondLogTimestamps(t *testing.T) {
+	const sec = int64(1704614400)
+	ms := sec * 1000
+
+	t.Run("subscription watermark", func(t *testing.T) {
+		logs := []ERLCKillLog{{Killer: "A:1", Timestamp: ms + 5000}}
+		e, ok := logEvent(EventTypeKills, logs, sec+10, false, nil)
+		if ok {
+			t.Errorf("kill 5s after the watermark, older than it, delivered: %+v", e)
+		}
+		if _, ok := logEvent(EventTypeKills, logs, sec, false, nil); !ok {
+			t.Error("kill newer than the watermark not delivered")
+		}
+	})
+
+	t.Run("log tailer", func(t *testing.T) {
+		tl := &LogTailer{mark: sec + 10, seen: make(map[string]struct{})}
+		fresh := tl.advance([]LogEntry{
+			{Kind: LogKindKills, Timestamp: logTimestamp(ERLCKillLog{Timestamp: ms + 5000}), Data: ERLCKillLog{Killer: "Old:1", Timestamp: ms + 5000}},
+			{Kind: LogKindKills, Timestamp: logTimestamp(ERLCKillLog{Timestamp: ms + 20000}), Data: ERLCKillLog{Killer: "New:2", Timestamp: ms + 20000}},
+		})
+		if len(fresh) != 1 || fresh[0].Data.(ERLCKillLog).Killer != "New:2" {
+			t.Errorf("delivered %v, want only New:2", fresh)
+		}
+		if tl.mark != sec+20 {
+			t.Errorf("mark %d, want %d", tl.mark, sec+20)
+		}
+	})
+
+	t.Run("grafana", func(t *testing.T) {
+		g := NewGrafanaDatasource(nil, GrafanaConfig{})
+		now := time.Unix(sec+60, 0)
+		g.record(&ERLCServerResponse{KillLogs: []ERLCKillLog{{Timestamp: ms + 30000}}}, now)
+		if g.kills[sec] != 1 || g.killTime != sec+30 {
+			t.Errorf("kills %v, kill time %d; want one kill in minute %d", g.kills, g.killTime, sec)
+		}
+	})
+
+	t.Run("snapshot diff", func(t *testing.T) {
+		a := ServerSnapshot{CommandLogs: []ERLCCommandLog{{Command: ":h a", Timestamp: sec + 10}}}
+		b := ServerSnapshot{CommandLogs: []ERLCCommandLog{{Command: ":h a", Timestamp: sec + 10}, {Command: ":h b", Timestamp: ms + 5000}}}
+		if d := DiffSnapshots(a, b); len(d.NewCommandLogs) != 0 {
+			t.Errorf("older millisecond entry reported as new: %+v", d.NewCommandLogs)
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package erlcgo
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"syscall"
+)
+
+// defaultTransportRetries is how many times a GET is retried after a dropped
+// connection unless WithTransportRetries says otherwise.
+const defaultTransportRetries = 1
+
+// WithTransportRetries sets how many times a GET request is retried when the
+// connection is reset or closed before a response arrives. This is
+// independent of rate-limit handling and only covers reads: commands are never
+// retried automatically, since PRC may already have run them. The default is 1;
+// pass 0 to disable.
+//
+// Example:
+//
+//	client := erlcgo.NewClient(apiKey, erlcgo.WithTransportRetries(0))
+func WithTransportRetries(n int) ClientOption {
+	return func(c *Client) {
+		if n < 0 {
+			n = 0
+		}
+		c.transportRetries = n
+	}
+}
+
+// isTransientTransportErr reports whether err looks like a dropped connection
+// rather than a timeout or a failure to connect at all.
+func isTransientTransportErr(err error) bool {
+	return errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET)
+}
+
+// doHTTP sends req, retrying idempotent requests that hit a dropped connection.
+func (c *Client) doHTTP(req *http.Request, routeName string) (*http.Response, error) {
+	hc := c.httpClientFor(req)
+	resp, err := hc.Do(req)
+	for attempt := 1; err != nil && attempt <= c.transportRetries; attempt++ {
+		if req.Method != http.MethodGet || req.Context().Err() != nil || !isTransientTransportErr(err) {
+			break
+		}
+		c.bus.publish(LifecycleEvent{Type: LifecycleRetry, Route: routeName, Attempt: attempt, Err: err, Tenant: TenantFrom(req.Context())})
+		resp, err = hc.Do(req)
+	}
+	return resp, err
+}
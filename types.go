@@ -1,6 +1,7 @@
 package erlcgo
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"sync"
@@ -137,12 +138,19 @@ type ERLCVehicle struct {
 	Texture   string `json:"Texture"`
 	ColorHex  string `json:"ColorHex"`
 	ColorName string `json:"ColorName"`
+
+	// ObservedAt is when a subscription first saw the vehicle. It is not
+	// part of the API response and is zero for vehicles from GetServer.
+	ObservedAt time.Time `json:"ObservedAt,omitzero"`
 }
 
 // APIError represents an error returned by the ERLC API.
 // It implements the standard Go error interface.
+//
+// When the body is not a PRC error, such as an HTML page from a proxy,
+// Message holds a short printable preview and Body the full response.
 type APIError struct {
-	Code       int            `json:"code"`
+	Code       ErrorCode      `json:"code"`
 	Message    string         `json:"message"`
 	CommandID  string         `json:"commandId,omitempty"`
 	StatusCode int            `json:"-"`
@@ -160,39 +168,12 @@ func (e *APIError) Error() string {
 
 // GetFriendlyErrorMessage returns a human-readable error message based on the error code
 func GetFriendlyErrorMessage(err error) string {
-	if apiErr, ok := err.(*APIError); ok {
-		switch apiErr.Code {
-		case 0:
-			return "An unknown error occurred. If this persists, please contact PRC support."
-		case 1001:
-			return "Failed to communicate with the game server. Please try again in a few minutes."
-		case 1002:
-			return "An internal system error occurred. Please try again later."
-		case 2000:
-			return "No server key provided. Please configure your server key."
-		case 2001, 2002:
-			return "Invalid server key. Please check your configuration."
-		case 2003:
-			return "Invalid API key. Please check your configuration."
-		case 2004:
-			return "This server key has been banned from accessing the API."
-		case 3001:
-			return "Invalid command format. Please check your input."
-		case 3002:
-			return "The server is currently offline (no players). Please try again when players are in the server."
-		case 4001:
-			return "You are being rate limited. Please wait a moment and try again."
-		case 4002:
-			return "This command is restricted and cannot be executed."
-		case 4003:
-			return "The message you're trying to send contains prohibited content."
-		case 9998:
-			return "Access to this resource is restricted."
-		case 9999:
-			return "The server module is out of date. Please kick all players and try again."
-		default:
-			return apiErr.Message
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if msg := apiErr.Code.FriendlyMessage(); msg != "" {
+			return msg
 		}
+		return apiErr.Message
 	}
 	return err.Error()
 }
@@ -211,8 +192,13 @@ type RateLimit struct {
 
 // RateLimiter manages rate limits for different buckets.
 type RateLimiter struct {
-	mu     sync.RWMutex
-	limits map[string]*RateLimit
+	mu      sync.RWMutex
+	limits  map[string]*RateLimit
+	tenants map[string]*TenantUsage
+
+	quotas       map[string]int
+	defaultQuota int
+	quotaWindows map[string][]time.Time
 }
 
 // CacheConfig represents cache configuration for different endpoints
@@ -231,11 +217,30 @@ type CacheConfig struct {
 	// Cache is the cache implementation to use
 	Cache Cache
 
+	// CacheCtx is a context-aware cache implementation. When set it is used
+	// instead of Cache, and its errors are reported as lifecycle events.
+	CacheCtx CacheCtx
+
 	// Prefix is prepended to all cache keys
 	Prefix string
 
 	// MaxItems is the maximum number of items to store in the cache
 	MaxItems int
+
+	// MaxBytes is the maximum approximate size of all cached values, measured
+	// by their JSON encoding. Zero means unlimited.
+	MaxBytes int64
+
+	// Serializer encodes values before they are stored in Cache and decodes
+	// them on retrieval: JSONSerializer, GobSerializer or MsgpackSerializer.
+	// When nil, values are stored as generic JSON-decoded data.
+	Serializer Serializer
+
+	// ReadYourWritesWindow makes cached reads bypass the cache for this long
+	// after ExecuteCommand, until each route has been refetched once. This keeps
+	// dashboards from showing state the command just changed, such as a kicked
+	// player still being online. Zero disables the behaviour.
+	ReadYourWritesWindow time.Duration
 }
 
 // DefaultCacheConfig returns a default cache configuration.
@@ -264,11 +269,30 @@ const (
 	EventTypeJoins          EventType = "joins"
 	EventTypeVehicles       EventType = "vehicles"
 	EventTypeEmergencyCalls EventType = "emergencycalls"
+	EventTypeQueue          EventType = "queue"
+	EventTypeBans           EventType = "bans"
+	EventTypeStaff          EventType = "staff"
+
+	// EventTypeSnapshot delivers the full *ServerSnapshot of every successful
+	// poll, starting with the initial one, after the poll's other events. It
+	// carries whatever the subscription's other event types fetch.
+	EventTypeSnapshot EventType = "snapshot"
 )
 
 type Event struct {
+	// ID is a stable content hash of the event's entries; see the ID methods
+	// on the log types. Sinks can use it to deduplicate across restarts.
+	ID   string
 	Type EventType
 	Data interface{}
+
+	// Version is the SchemaVersion the event was created with. Persisted
+	// events should be passed through MigrateEvent when loaded.
+	Version int
+
+	// Replayed marks log events backfilled after a subscription gap rather
+	// than seen live; see EventConfig.CatchUpAfter.
+	Replayed bool
 }
 
 // Event handler types for type-safety
@@ -279,6 +303,9 @@ type ModCallEventHandler func([]ERLCModCallLog)
 type JoinEventHandler func([]ERLCJoinLog)
 type VehicleEventHandler func([]ERLCVehicle)
 type EmergencyCallEventHandler func([]ERLCEmergencyCall)
+type QueueEventHandler func([]QueueEvent)
+type BanEventHandler func([]BanEvent)
+type StaffEventHandler func([]StaffEvent)
 
 type HandlerRegistration struct {
 	PlayerHandler        PlayerEventHandler
@@ -288,16 +315,40 @@ type HandlerRegistration struct {
 	JoinHandler          JoinEventHandler
 	VehicleHandler       VehicleEventHandler
 	EmergencyCallHandler EmergencyCallEventHandler
+	QueueHandler         QueueEventHandler
+	BanHandler           BanEventHandler
+	StaffHandler         StaffEventHandler
 }
 
 type PlayerEvent struct {
+	// Player is the player's record. For leaves it is the last record seen
+	// while they were in the server.
 	Player ERLCServerPlayer
 	Type   string // "join" or "leave"
+	// ObservedAt is when the poll that saw the change was made.
+	ObservedAt time.Time
+}
+
+// QueueEvent is a change in the server's join queue.
+type QueueEvent struct {
+	UserID int64
+	Type   string // "entered", "left" or "admitted"
+	// Position is the 1-based place in the queue for "entered" events.
+	Position int
+	// ObservedAt is when the poll that saw the change was made.
+	ObservedAt time.Time
 }
 
 // EventConfig provides configuration options for event subscriptions
 type EventConfig struct {
-	PollInterval        time.Duration
+	// PollInterval may be below one second for keys with a higher rate limit.
+	// Sub-second intervals are stretched whenever the remaining rate limit
+	// budget would not last until the window resets, publishing a
+	// LifecyclePollThrottled event each time.
+	PollInterval time.Duration
+	// BanPollInterval is how often the ban list is polled for EventTypeBans.
+	// Defaults to one minute.
+	BanPollInterval     time.Duration
 	BufferSize          int
 	RetryOnError        bool
 	RetryInterval       time.Duration
@@ -306,11 +357,52 @@ type EventConfig struct {
 	BatchEvents         bool
 	BatchWindow         time.Duration
 	LogErrors           bool
-	ErrorHandler        func(error)
+	// ErrorHandler is called with poll, checkpoint and leader election
+	// errors. Failed polls are retried on the next tick.
+	ErrorHandler func(error)
+	// CatchUpAfter, when positive, enables catch-up after gaps: if this long
+	// has passed since the last successful poll, for example after an outage
+	// or a paused process, the next poll delivers only the log entries from
+	// the gap, with Event.Replayed set, so sinks can tell backfill from live
+	// events.
+	CatchUpAfter time.Duration
+	// DedupWindow, when positive, deduplicates log events by content: the
+	// IDs of the last DedupWindow entries of each log are remembered, and
+	// events carry only entries not seen before instead of the whole log.
+	// This catches entries that share the newest one-second timestamp
+	// across polls.
+	DedupWindow int
+	// StaggerStart delays the first poll by a stable offset within
+	// PollInterval, derived from the server key and event types, and offsets
+	// ban polling the same way. Processes starting many subscriptions at
+	// once then spread their polling across the interval instead of polling
+	// in lockstep. The initial state is still fetched, or taken from the
+	// cache, when subscribing.
+	StaggerStart bool
+	// MaxBatchSize, when positive, splits events with more entries, such as
+	// a log backlog after downtime, into chunks of at most this many entries
+	// that are passed to the handler one after another.
+	MaxBatchSize int
 	// OnPanic is called if an event handler panics.
 	// If nil, the panic is recovered but not reported.
 	OnPanic    func(interface{})
 	TimeFormat string
+	// CheckpointStore, when set, persists the last-seen state of each event
+	// type so a restarted subscription resumes where it left off instead of
+	// starting from the current server state.
+	CheckpointStore CheckpointStore
+	// LeaderLock, when set, lets redundant instances subscribing to the same
+	// server elect a single poller. Followers skip polling and take over when
+	// the leader's lease lapses.
+	LeaderLock LeaderLock
+	// LeaderName identifies the subscription in the leader election, so
+	// instances only compete with others using the same name. Defaults to
+	// the subscription's event types, so subscriptions to different events
+	// on one server each elect their own poller.
+	LeaderName string
+	// LeaderLease is how long a leadership lease lasts without renewal.
+	// Defaults to three poll intervals, and at least five seconds.
+	LeaderLease time.Duration
 }
 
 // Internal types for subscription handling
@@ -324,12 +416,33 @@ type lastState struct {
 	joinTime             int64
 	vehicleSet           map[string]struct{}
 	emergencyCallNumbers map[int]struct{}
+	queue                []int64
+	bans                 ERLCBans
+	staff                playerSet
 	initialized          bool
+
+	// playerRecords holds the last full record of each player in players,
+	// so leave events can report who left with their team, callsign and ID.
+	// It is not checkpointed; after a restore leaves carry only the name.
+	playerRecords map[string]ERLCServerPlayer
+
+	// dedup holds the recently seen entry IDs of each log when
+	// EventConfig.DedupWindow is set.
+	dedup map[EventType]*dedupWindow
+
+	// Sets from the previous poll, kept for reuse on the next one, and the
+	// size of the last player diff as a capacity hint.
+	sparePlayers  playerSet
+	spareVehicles map[string]struct{}
+	spareCalls    map[int]struct{}
+	playerChanges int
 }
 
 type Subscription struct {
-	Events   chan Event
-	done     chan struct{}
-	handlers HandlerRegistration
-	config   *EventConfig
+	Events    chan Event
+	done      chan struct{}
+	closeOnce sync.Once
+	handlers  HandlerRegistration
+	config    *EventConfig
+	client    *Client
 }
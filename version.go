@@ -0,0 +1,118 @@
+package erlcgo
+
+import (
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// modulePath is the import path used to find erlcgo in the build info.
+const modulePath = "github.com/bmrgcorp/erlcgo"
+
+var (
+	versionOnce sync.Once
+	version     = "devel"
+)
+
+// Version returns the erlcgo module version compiled into the binary, such as
+// "v1.4.0", or "devel" when built from a local checkout.
+func Version() string {
+	versionOnce.Do(func() {
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		for _, dep := range info.Deps {
+			if dep.Path == modulePath {
+				if dep.Replace != nil && dep.Replace.Version != "" {
+					version = dep.Replace.Version
+				} else if dep.Version != "" {
+					version = dep.Version
+				}
+				return
+			}
+		}
+	})
+	return version
+}
+
+// userAgent is sent with every request so PRC can tell library versions apart.
+func userAgent() string {
+	return "erlcgo/" + Version()
+}
+
+// DeprecationNotice describes deprecation headers returned by the API for a route.
+type DeprecationNotice struct {
+	Route string
+	// Deprecation is the raw Deprecation header, typically "true" or a date.
+	Deprecation string
+	// Sunset is when the route will stop working, if the API said.
+	Sunset time.Time
+	// Link is the Link header, which may point at migration documentation.
+	Link string
+	// Warning is the raw Warning header, if any.
+	Warning string
+}
+
+// DeprecationHook is called the first time a route returns deprecation headers.
+type DeprecationHook func(DeprecationNotice)
+
+// WithDeprecationHook registers a hook that is notified when the API marks a
+// route as deprecated, so breaking changes show up in logs before they cause
+// failures. Each distinct notice is reported once per client. Notices are also
+// published as LifecycleDeprecation events.
+//
+// Example:
+//
+//	client := erlcgo.NewClient("your-server-key",
+//	    erlcgo.WithDeprecationHook(func(n erlcgo.DeprecationNotice) {
+//	        log.Printf("deprecated: %s (sunset %s) %s", n.Route, n.Sunset, n.Link)
+//	    }),
+//	)
+func WithDeprecationHook(h DeprecationHook) ClientOption {
+	return func(c *Client) {
+		c.deprecationHook = h
+	}
+}
+
+// deprecations remembers which notices have already been reported.
+type deprecations struct {
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+// checkDeprecation reports deprecation headers on a response the first time
+// they are seen for a route.
+func (c *Client) checkDeprecation(route string, h http.Header) {
+	notice := DeprecationNotice{
+		Route:       route,
+		Deprecation: h.Get("Deprecation"),
+		Link:        h.Get("Link"),
+		Warning:     h.Get("Warning"),
+	}
+	sunset := h.Get("Sunset")
+	if sunset != "" {
+		notice.Sunset, _ = http.ParseTime(sunset)
+	}
+	if notice.Deprecation == "" && sunset == "" && notice.Warning == "" {
+		return
+	}
+
+	key := route + "\x00" + notice.Deprecation + "\x00" + sunset + "\x00" + notice.Warning
+	c.deprecations.mu.Lock()
+	if c.deprecations.seen == nil {
+		c.deprecations.seen = make(map[string]struct{})
+	}
+	_, seen := c.deprecations.seen[key]
+	c.deprecations.seen[key] = struct{}{}
+	c.deprecations.mu.Unlock()
+	if seen {
+		return
+	}
+
+	c.bus.publish(LifecycleEvent{Type: LifecycleDeprecation, Route: route})
+	if c.deprecationHook != nil {
+		c.deprecationHook(notice)
+	}
+}
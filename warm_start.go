@@ -0,0 +1,95 @@
+package erlcgo
+
+import (
+	"context"
+	"math/bits"
+	"sort"
+)
+
+// maxWarmStartKeys caps how many cached responses a warm start looks up.
+const maxWarmStartKeys = 64
+
+// serverOptionFlags lists the ServerQueryOptions fields in a fixed order, so
+// option sets can be handled as bit masks.
+var serverOptionFlags = []func(*ServerQueryOptions) *bool{
+	func(o *ServerQueryOptions) *bool { return &o.Players },
+	func(o *ServerQueryOptions) *bool { return &o.Staff },
+	func(o *ServerQueryOptions) *bool { return &o.JoinLogs },
+	func(o *ServerQueryOptions) *bool { return &o.Queue },
+	func(o *ServerQueryOptions) *bool { return &o.KillLogs },
+	func(o *ServerQueryOptions) *bool { return &o.CommandLogs },
+	func(o *ServerQueryOptions) *bool { return &o.ModCalls },
+	func(o *ServerQueryOptions) *bool { return &o.EmergencyCalls },
+	func(o *ServerQueryOptions) *bool { return &o.Vehicles },
+}
+
+func optionMask(opts ServerQueryOptions) uint {
+	var mask uint
+	for i, flag := range serverOptionFlags {
+		if *flag(&opts) {
+			mask |= 1 << i
+		}
+	}
+	return mask
+}
+
+func optionsFromMask(mask uint) ServerQueryOptions {
+	var opts ServerQueryOptions
+	for i, flag := range serverOptionFlags {
+		*flag(&opts) = mask&(1<<i) != 0
+	}
+	return opts
+}
+
+// warmServer looks for a cached /v2/server response that covers opts: one
+// fetched with the same options or with more. It lets subscriptions start
+// from data other callers already fetched instead of each making its own
+// request. Candidates with the fewest extra options are preferred, and keys
+// marked stale by a recent command are skipped.
+func (c *Client) warmServer(ctx context.Context, opts ServerQueryOptions) (*ERLCServerResponse, bool) {
+	if c.cache == nil || !c.cache.Enabled || c.cache.backend() == nil {
+		return nil, false
+	}
+
+	want := optionMask(opts)
+	free := (uint(1)<<len(serverOptionFlags) - 1) &^ want
+	var masks []uint
+	for extra := free; ; extra = (extra - 1) & free {
+		masks = append(masks, want|extra)
+		if extra == 0 {
+			break
+		}
+	}
+	sort.Slice(masks, func(i, j int) bool { return bits.OnesCount(masks[i]) < bits.OnesCount(masks[j]) })
+	if len(masks) > maxWarmStartKeys {
+		masks = masks[:maxWarmStartKeys]
+	}
+
+	keys := make([]string, 0, len(masks))
+	for _, mask := range masks {
+		key := c.cacheKey(c.baseURL + serverPath(optionsFromMask(mask)))
+		if !c.taint.bypass(key) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, false
+	}
+	hits, err := c.cache.backend().GetMulti(ctx, keys)
+	if err != nil {
+		c.bus.publish(LifecycleEvent{Type: LifecycleCacheError, Route: "GET /v2/server", Err: err})
+		return nil, false
+	}
+	for _, key := range keys {
+		cached, ok := hits[key]
+		if !ok {
+			continue
+		}
+		var resp ERLCServerResponse
+		if err := c.decodeCacheValue(cached, &resp); err == nil {
+			c.countCacheHit("GET /v2/server")
+			return &resp, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,174 @@
+package erlcgo
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader is the header carrying webhook signatures.
+const SignatureHeader = "X-Erlc-Signature"
+
+// DefaultSignatureTolerance is how old a signed webhook may be before
+// VerifySignature rejects it as a possible replay.
+const DefaultSignatureTolerance = 5 * time.Minute
+
+var (
+	ErrSignatureMissing  = errors.New("erlc: webhook signature missing or malformed")
+	ErrSignatureMismatch = errors.New("erlc: webhook signature does not match")
+	ErrSignatureExpired  = errors.New("erlc: webhook signature timestamp outside tolerance")
+)
+
+// SignPayload returns a signature header value for body, signed at time t with
+// every secret given. Signing with both the old and new secret while rotating
+// lets receivers switch secrets at their own pace.
+//
+// The format is "t=<unix seconds>,v1=<hex hmac>[,v1=<hex hmac>...]", where each
+// HMAC-SHA256 covers "<unix seconds>.<body>".
+func SignPayload(body []byte, t time.Time, secrets ...string) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	parts := []string{"t=" + ts}
+	for _, secret := range secrets {
+		parts = append(parts, "v1="+signatureOf(secret, ts, body))
+	}
+	return strings.Join(parts, ",")
+}
+
+// VerifySignature checks a SignatureHeader value against body. It succeeds if
+// any signature in the header matches any of secrets, so receivers can accept
+// both secrets during a rotation. Signatures older than
+// DefaultSignatureTolerance are rejected.
+//
+// Example:
+//
+//	func handle(w http.ResponseWriter, r *http.Request) {
+//	    body, _ := io.ReadAll(r.Body)
+//	    if err := erlcgo.VerifySignature(r.Header.Get(erlcgo.SignatureHeader), body, []string{secret}); err != nil {
+//	        http.Error(w, "bad signature", http.StatusUnauthorized)
+//	        return
+//	    }
+//	    // ...
+//	}
+func VerifySignature(header string, body []byte, secrets []string) error {
+	var ts string
+	var sigs []string
+	for _, part := range strings.Split(header, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			sigs = append(sigs, v)
+		}
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || len(sigs) == 0 {
+		return ErrSignatureMissing
+	}
+	if age := time.Since(time.Unix(unix, 0)); age > DefaultSignatureTolerance || age < -DefaultSignatureTolerance {
+		return ErrSignatureExpired
+	}
+
+	for _, secret := range secrets {
+		expected := signatureOf(secret, ts, body)
+		for _, sig := range sigs {
+			if hmac.Equal([]byte(sig), []byte(expected)) {
+				return nil
+			}
+		}
+	}
+	return ErrSignatureMismatch
+}
+
+func signatureOf(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookRelay posts subscription events to an HTTP callback, signed with
+// SignPayload so the receiver can authenticate them with VerifySignature.
+//
+// Example:
+//
+//	hook := &erlcgo.WebhookRelay{URL: "https://bot.example.com/erlc", Secrets: []string{newSecret, oldSecret}}
+//	sub, _ := client.Subscribe(ctx, erlcgo.EventTypeKills)
+//	go hook.Forward(ctx, sub)
+type WebhookRelay struct {
+	// URL receives a POST with the JSON-encoded Event for each event.
+	URL string
+
+	// Secrets sign each callback. List the new secret first while rotating
+	// and drop the old one once receivers have switched.
+	Secrets []string
+
+	// HTTPClient sends callbacks. Defaults to a client with a 10 second timeout.
+	HTTPClient *http.Client
+
+	// ErrorHandler is called when Forward fails to deliver an event.
+	ErrorHandler func(Event, error)
+}
+
+// Send delivers a single event.
+func (h *WebhookRelay) Send(ctx context.Context, event Event) error {
+	if len(h.Secrets) == 0 {
+		return errors.New("webhook relay requires at least one secret")
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent())
+	req.Header.Set(SignatureHeader, SignPayload(body, time.Now(), h.Secrets...))
+
+	client := h.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Forward sends every event from sub until its channel closes or ctx is done.
+func (h *WebhookRelay) Forward(ctx context.Context, sub *Subscription) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.Events:
+			if !ok {
+				return
+			}
+			if err := h.Send(ctx, event); err != nil && h.ErrorHandler != nil {
+				h.ErrorHandler(event, err)
+			}
+		}
+	}
+}
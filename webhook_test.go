@@ -0,0 +1,77 @@
+package erlcgo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"type":"kills"}`)
+	now := time.Now()
+
+	for _, tc := range []struct {
+		name    string
+		header  string
+		body    []byte
+		secrets []string
+		want    error
+	}{
+		{name: "valid", header: SignPayload(body, now, "new"), secrets: []string{"new"}},
+		// While rotating, the sender signs with both secrets, and receivers
+		// on either secret accept it.
+		{name: "rotating, receiver on old", header: SignPayload(body, now, "new", "old"), secrets: []string{"old"}},
+		{name: "rotating, receiver on new", header: SignPayload(body, now, "new", "old"), secrets: []string{"new"}},
+		{name: "receiver accepts both", header: SignPayload(body, now, "old"), secrets: []string{"new", "old"}},
+		{name: "rotation finished", header: SignPayload(body, now, "new"), secrets: []string{"old"}, want: ErrSignatureMismatch},
+		{name: "tampered body", header: SignPayload(body, now, "new"), body: []byte(`{"type":"joins"}`), secrets: []string{"new"}, want: ErrSignatureMismatch},
+		{name: "expired", header: SignPayload(body, now.Add(-DefaultSignatureTolerance-time.Minute), "new"), secrets: []string{"new"}, want: ErrSignatureExpired},
+		{name: "from the future", header: SignPayload(body, now.Add(DefaultSignatureTolerance+time.Minute), "new"), secrets: []string{"new"}, want: ErrSignatureExpired},
+		{name: "within tolerance", header: SignPayload(body, now.Add(-DefaultSignatureTolerance+time.Minute), "new"), secrets: []string{"new"}},
+		{name: "empty", secrets: []string{"new"}, want: ErrSignatureMissing},
+		{name: "no signature", header: "t=" + strconv.FormatInt(now.Unix(), 10), secrets: []string{"new"}, want: ErrSignatureMissing},
+		{name: "no timestamp", header: "v1=abcd", secrets: []string{"new"}, want: ErrSignatureMissing},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := tc.body
+			if b == nil {
+				b = body
+			}
+			if err := VerifySignature(tc.header, b, tc.secrets); !errors.Is(err, tc.want) {
+				t.Errorf("got %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestWebhookRelaySend(t *testing.T) {
+	var verified error
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		verified = VerifySignature(r.Header.Get(SignatureHeader), body, []string{"old"})
+		if verified != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	hook := &WebhookRelay{URL: srv.URL, Secrets: []string{"new", "old"}}
+	if err := hook.Send(context.Background(), Event{Type: EventTypeKills}); err != nil {
+		t.Fatalf("send: %v (verify: %v)", err, verified)
+	}
+
+	hook.Secrets = []string{"new"}
+	if err := hook.Send(context.Background(), Event{Type: EventTypeKills}); err == nil {
+		t.Error("receiver still on the old secret accepted a callback signed only with the new one")
+	}
+
+	hook.Secrets = nil
+	if err := hook.Send(context.Background(), Event{Type: EventTypeKills}); err == nil {
+		t.Error("send without secrets succeeded")
+	}
+}